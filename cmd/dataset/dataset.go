@@ -0,0 +1,408 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dataset
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/stratastor/rodent/cmd/remote"
+	"github.com/stratastor/rodent/pkg/client"
+	"github.com/stratastor/rodent/pkg/zfs/dataset"
+)
+
+// listColumns are the properties dataset listings show
+var listColumns = []string{"used", "available", "referenced", "mountpoint"}
+
+func NewDatasetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dataset",
+		Short: "Manage ZFS filesystems and volumes through the Rodent API",
+	}
+	remote.AddFlags(cmd)
+
+	cmd.AddCommand(newListCmd())
+	cmd.AddCommand(newGetCmd())
+	cmd.AddCommand(newSetCmd())
+	cmd.AddCommand(newInheritCmd())
+	cmd.AddCommand(newCreateCmd())
+	cmd.AddCommand(newDestroyCmd())
+	cmd.AddCommand(newRenameCmd())
+	cmd.AddCommand(newMountCmd())
+	cmd.AddCommand(newUnmountCmd())
+	cmd.AddCommand(newResizeCmd())
+	cmd.AddCommand(newCloneCmd())
+	cmd.AddCommand(newPromoteCmd())
+	cmd.AddCommand(newDiffCmd())
+	return cmd
+}
+
+func newListCmd() *cobra.Command {
+	var cfg dataset.ListConfig
+
+	cmd := &cobra.Command{
+		Use:               "list [name]",
+		Short:             "List datasets",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: remote.CompleteDatasets("filesystem,volume", 1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				cfg.Name = args[0]
+			}
+			result, err := c.ListDatasets(ctx, cfg)
+			if err != nil {
+				return err
+			}
+			return remote.PrintDatasets(cmd, result, listColumns...)
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&cfg.Recursive, "recursive", "r", false, "List the children of the dataset")
+	cmd.Flags().UintVarP(&cfg.Depth, "depth", "d", 0, "Limit recursion to depth")
+	cmd.Flags().StringVarP(&cfg.Type, "type", "t", "filesystem,volume",
+		"Comma separated types: filesystem, volume, snapshot, bookmark or all")
+	return cmd
+}
+
+func newGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "get <name> [property]",
+		Short:             "Show all properties of a dataset, or one",
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: remote.CompleteDatasets("all", 1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			var (
+				result dataset.ListResult
+				err    error
+			)
+			if len(args) == 2 {
+				result, err = c.GetDatasetProperty(ctx, dataset.PropertyConfig{
+					NameConfig: dataset.NameConfig{Name: args[0]},
+					Property:   args[1],
+				})
+			} else {
+				result, err = c.ListDatasetProperties(ctx, dataset.NameConfig{Name: args[0]})
+			}
+			if err != nil {
+				return err
+			}
+			return remote.PrintProperties(cmd, result)
+		}),
+	}
+}
+
+func newSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "set <name> <property=value>...",
+		Short:             "Set properties of a dataset",
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: remote.CompleteDatasets("all", 1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			props, err := remote.Properties(args[1:])
+			if err != nil {
+				return err
+			}
+			for property, value := range props {
+				if err := c.SetDatasetProperty(ctx, dataset.SetPropertyConfig{
+					PropertyConfig: dataset.PropertyConfig{
+						NameConfig: dataset.NameConfig{Name: args[0]},
+						Property:   property,
+					},
+					Value: value,
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		}),
+	}
+}
+
+func newInheritCmd() *cobra.Command {
+	var cfg dataset.InheritConfig
+
+	cmd := &cobra.Command{
+		Use:               "inherit <property> <name>...",
+		Short:             "Clear a property so it's inherited from the parent",
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: remote.CompleteDatasets("filesystem,volume,snapshot", 100),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			cfg.Property = args[0]
+			cfg.Names = args[1:]
+			return c.InheritDatasetProperty(ctx, cfg)
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&cfg.Recursive, "recursive", "r", false, "Inherit for all children")
+	cmd.Flags().BoolVarP(&cfg.Revert, "revert", "S", false, "Revert to the received value")
+	return cmd
+}
+
+func newCreateCmd() *cobra.Command {
+	var (
+		props      []string
+		volsize    string
+		sparse     bool
+		blocksize  string
+		parents    bool
+		doNotMount bool
+		dryRun     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a filesystem, or a volume with --volsize",
+		Args:  cobra.ExactArgs(1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			properties, err := remote.Properties(props)
+			if err != nil {
+				return err
+			}
+			kind := "filesystem"
+			if volsize != "" {
+				kind = "volume"
+				err = c.CreateVolume(ctx, dataset.VolumeConfig{
+					NameConfig: dataset.NameConfig{Name: args[0]},
+					Size:       volsize,
+					Properties: properties,
+					Sparse:     sparse,
+					BlockSize:  blocksize,
+					Parents:    parents,
+					DryRun:     dryRun,
+				})
+			} else {
+				err = c.CreateFilesystem(ctx, dataset.FilesystemConfig{
+					NameConfig: dataset.NameConfig{Name: args[0]},
+					Properties: properties,
+					Parents:    parents,
+					DoNotMount: doNotMount,
+					DryRun:     dryRun,
+				})
+			}
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				fmt.Fprintf(cmd.OutOrStdout(), "would create %s %s\n", kind, args[0])
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringArrayVar(&props, "prop", nil, "Property to set, as key=value; repeatable")
+	cmd.Flags().StringVarP(&volsize, "volsize", "V", "", "Create a volume of this size, e.g. 10G")
+	cmd.Flags().BoolVarP(&sparse, "sparse", "s", false, "Create a sparse volume with no reservation")
+	cmd.Flags().StringVarP(&blocksize, "blocksize", "b", "", "Block size of the volume")
+	cmd.Flags().BoolVarP(&parents, "parents", "p", false, "Create missing parent datasets")
+	cmd.Flags().BoolVarP(&doNotMount, "no-mount", "u", false, "Don't mount the new filesystem")
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Validate without creating")
+	return cmd
+}
+
+func newDestroyCmd() *cobra.Command {
+	var cfg dataset.DestroyConfig
+
+	cmd := &cobra.Command{
+		Use:               "destroy <name>",
+		Short:             "Destroy a dataset",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: remote.CompleteDatasets("filesystem,volume", 1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			cfg.Name = args[0]
+			if err := c.DestroyDataset(ctx, cfg); err != nil {
+				return err
+			}
+			if cfg.DryRun {
+				fmt.Fprintf(cmd.OutOrStdout(), "would destroy %s\n", args[0])
+			}
+			return nil
+		}),
+	}
+
+	addDestroyFlags(cmd, &cfg)
+	return cmd
+}
+
+// addDestroyFlags adds the flags of destroying datasets and snapshots
+func addDestroyFlags(cmd *cobra.Command, cfg *dataset.DestroyConfig) {
+	cmd.Flags().BoolVarP(&cfg.RecursiveDestroyChildren, "recursive", "r", false, "Destroy all children")
+	cmd.Flags().BoolVarP(&cfg.RecursiveDestroyDependents, "dependents", "R", false,
+		"Destroy all dependents, including clones outside the hierarchy")
+	cmd.Flags().BoolVarP(&cfg.Force, "force", "f", false, "Unmount busy filesystems")
+	cmd.Flags().BoolVarP(&cfg.DryRun, "dry-run", "n", false, "Validate without destroying")
+}
+
+func newRenameCmd() *cobra.Command {
+	var cfg dataset.RenameConfig
+
+	cmd := &cobra.Command{
+		Use:               "rename <name> <new-name>",
+		Short:             "Rename a dataset",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: remote.CompleteDatasets("filesystem,volume", 1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			cfg.Name, cfg.NewName = args[0], args[1]
+			return c.RenameDataset(ctx, cfg)
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&cfg.Parents, "parents", "p", false, "Create missing parent datasets")
+	cmd.Flags().BoolVarP(&cfg.Force, "force", "f", false, "Unmount busy filesystems")
+	cmd.Flags().BoolVarP(&cfg.DoNotMount, "no-mount", "u", false, "Don't remount the filesystem")
+	return cmd
+}
+
+func newMountCmd() *cobra.Command {
+	var cfg dataset.MountConfig
+
+	cmd := &cobra.Command{
+		Use:               "mount <filesystem>",
+		Short:             "Mount a filesystem",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: remote.CompleteDatasets("filesystem", 1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			cfg.Name = args[0]
+			return c.Mount(ctx, cfg)
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&cfg.Recursive, "recursive", "R", false, "Mount the children too")
+	cmd.Flags().BoolVarP(&cfg.Overlay, "overlay", "O", false, "Mount over a non-empty directory")
+	cmd.Flags().BoolVarP(&cfg.Force, "force", "f", false, "Force the mount")
+	cmd.Flags().StringSliceVar(&cfg.Options, "options", nil, "Temporary mount options")
+	return cmd
+}
+
+func newUnmountCmd() *cobra.Command {
+	var cfg dataset.UnmountConfig
+
+	cmd := &cobra.Command{
+		Use:               "unmount <filesystem>",
+		Short:             "Unmount a filesystem",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: remote.CompleteDatasets("filesystem", 1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			cfg.Name = args[0]
+			return c.Unmount(ctx, cfg)
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&cfg.Force, "force", "f", false, "Unmount even if busy")
+	return cmd
+}
+
+func newResizeCmd() *cobra.Command {
+	var cfg dataset.VolumeResizeConfig
+
+	cmd := &cobra.Command{
+		Use:               "resize <volume> <size>",
+		Short:             "Change the size of a volume",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: remote.CompleteDatasets("volume", 1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			cfg.Name, cfg.Size = args[0], args[1]
+			result, err := c.ResizeVolume(ctx, cfg)
+			if err != nil {
+				return err
+			}
+			return remote.Print(cmd, result, func(w io.Writer) {
+				fmt.Fprintln(w, "NAME\tOLD SIZE\tNEW SIZE\tDEVICE")
+				fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", result.Name, result.OldSize, result.NewSize, result.Device)
+			})
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&cfg.Force, "force", "f", false, "Allow shrinking below the referenced data")
+	return cmd
+}
+
+func newCloneCmd() *cobra.Command {
+	var (
+		props   []string
+		parents bool
+	)
+
+	cmd := &cobra.Command{
+		Use:               "clone <snapshot> <name>",
+		Short:             "Create a clone of a snapshot",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: remote.CompleteDatasets("snapshot", 1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			properties, err := remote.Properties(props)
+			if err != nil {
+				return err
+			}
+			return c.Clone(ctx, dataset.CloneConfig{
+				NameConfig: dataset.NameConfig{Name: args[0]},
+				CloneName:  args[1],
+				Properties: properties,
+				Parents:    parents,
+			})
+		}),
+	}
+
+	cmd.Flags().StringArrayVar(&props, "prop", nil, "Property to set, as key=value; repeatable")
+	cmd.Flags().BoolVarP(&parents, "parents", "p", false, "Create missing parent datasets")
+	return cmd
+}
+
+func newPromoteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "promote <clone>",
+		Short:             "Make a clone independent of its origin snapshot",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: remote.CompleteDatasets("filesystem,volume", 1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			return c.PromoteClone(ctx, dataset.NameConfig{Name: args[0]})
+		}),
+	}
+}
+
+func newDiffCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "diff <snapshot> [snapshot|filesystem]",
+		Short:             "List the changes between a snapshot and a later snapshot or the filesystem",
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: remote.CompleteDatasets("snapshot", 2),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			cfg := dataset.DiffConfig{NamesConfig: dataset.NamesConfig{Names: args}}
+
+			output, _ := cmd.Flags().GetString("output")
+			if output != remote.OutputTable {
+				result, err := c.Diff(ctx, cfg)
+				if err != nil {
+					return err
+				}
+				return remote.Print(cmd, result, nil)
+			}
+
+			// Print changes as zfs reports them
+			out := cmd.OutOrStdout()
+			return c.DiffStream(ctx, cfg, func(e dataset.DiffEntry) error {
+				if e.NewPath != "" {
+					_, err := fmt.Fprintf(out, "%s\t%s\t%s -> %s\n", e.ChangeType, e.FileType, e.Path, e.NewPath)
+					return err
+				}
+				_, err := fmt.Fprintf(out, "%s\t%s\t%s\n", e.ChangeType, e.FileType, e.Path)
+				return err
+			})
+		}),
+	}
+}
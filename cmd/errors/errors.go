@@ -0,0 +1,110 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	rodenterrors "github.com/stratastor/rodent/pkg/errors"
+)
+
+func NewErrorsCmd() *cobra.Command {
+	var (
+		domain  string
+		output  string
+		openapi bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "errors [code|name]",
+		Short: "Look up Rodent error codes",
+		Long: "List the error codes Rodent returns, or look one up by number or name.\n" +
+			"The catalog is also served at /api/v1/errors.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if openapi {
+				return printJSON(map[string]interface{}{
+					"components": rodenterrors.OpenAPIComponents(),
+				})
+			}
+
+			var entries []rodenterrors.CatalogEntry
+			if len(args) == 1 {
+				e, ok := lookup(args[0])
+				if !ok {
+					return fmt.Errorf("unknown error code %s", args[0])
+				}
+				entries = append(entries, e)
+			} else {
+				for _, e := range rodenterrors.Catalog() {
+					if domain == "" || strings.EqualFold(string(e.Domain), domain) {
+						entries = append(entries, e)
+					}
+				}
+			}
+
+			switch output {
+			case "json":
+				if len(args) == 1 {
+					return printJSON(entries[0])
+				}
+				return printJSON(entries)
+			case "table":
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "CODE\tNAME\tDOMAIN\tSTATUS\tMESSAGE")
+				for _, e := range entries {
+					fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\n",
+						e.Code, e.Name, e.Domain, e.HTTPStatus, e.Message)
+				}
+				return w.Flush()
+			default:
+				return fmt.Errorf("unknown output format %q; use table or json", output)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&domain, "domain", "d", "", "Only list codes of a domain, e.g. ZFS")
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format: table or json")
+	cmd.Flags().BoolVar(&openapi, "openapi", false, "Print the catalog as an OpenAPI 3 components section")
+	return cmd
+}
+
+// lookup finds an error by code or by name, e.g. 2092 or ZFSNotFound
+func lookup(arg string) (rodenterrors.CatalogEntry, bool) {
+	if code, err := strconv.Atoi(arg); err == nil {
+		return rodenterrors.Lookup(rodenterrors.ErrorCode(code))
+	}
+	for _, e := range rodenterrors.Catalog() {
+		if strings.EqualFold(e.Name, arg) {
+			return e, true
+		}
+	}
+	return rodenterrors.CatalogEntry{}, false
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
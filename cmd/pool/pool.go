@@ -0,0 +1,579 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stratastor/rodent/cmd/remote"
+	"github.com/stratastor/rodent/pkg/client"
+	"github.com/stratastor/rodent/pkg/zfs/pool"
+)
+
+// listColumns are the properties pool listings show
+var listColumns = []string{"size", "allocated", "free", "capacity", "health"}
+
+func NewPoolCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pool",
+		Short: "Manage ZFS pools through the Rodent API",
+	}
+	remote.AddFlags(cmd)
+
+	cmd.AddCommand(newListCmd())
+	cmd.AddCommand(newStatusCmd())
+	cmd.AddCommand(newGetCmd())
+	cmd.AddCommand(newSetCmd())
+	cmd.AddCommand(newCreateCmd())
+	cmd.AddCommand(newPlanCmd())
+	cmd.AddCommand(newDestroyCmd())
+	cmd.AddCommand(newImportCmd())
+	cmd.AddCommand(newExportCmd())
+	cmd.AddCommand(newScrubCmd())
+	cmd.AddCommand(newAddCmd())
+	cmd.AddCommand(newRemoveCmd())
+	cmd.AddCommand(newAttachCmd())
+	cmd.AddCommand(newDetachCmd())
+	cmd.AddCommand(newReplaceCmd())
+	cmd.AddCommand(newOnlineCmd())
+	cmd.AddCommand(newOfflineCmd())
+	cmd.AddCommand(newClearCmd())
+	return cmd
+}
+
+func newListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List pools",
+		Args:  cobra.NoArgs,
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			result, err := c.ListPools(ctx)
+			if err != nil {
+				return err
+			}
+			return remote.Print(cmd, result, func(w io.Writer) {
+				header := []string{"NAME"}
+				for _, p := range listColumns {
+					header = append(header, strings.ToUpper(p))
+				}
+				fmt.Fprintln(w, strings.Join(header, "\t"))
+				for _, name := range remote.PoolNames(result) {
+					row := []string{name}
+					for _, p := range listColumns {
+						row = append(row, remote.Value(result.Pools[name].Properties[p].Value))
+					}
+					fmt.Fprintln(w, strings.Join(row, "\t"))
+				}
+			})
+		}),
+	}
+}
+
+func newStatusCmd() *cobra.Command {
+	var progress bool
+
+	cmd := &cobra.Command{
+		Use:               "status <pool>",
+		Short:             "Show the health and device tree of a pool",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: remote.CompletePools(1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			status, err := c.PoolStatus(ctx, args[0], progress)
+			if err != nil {
+				return err
+			}
+			return remote.Print(cmd, status, func(w io.Writer) {
+				for _, name := range remote.PoolNames(pool.ListResult{Pools: status.Pools}) {
+					printStatus(w, status.Pools[name])
+				}
+			})
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&progress, "progress", "p", false,
+		"Include the progress of initializing and trimming")
+	return cmd
+}
+
+// printStatus writes a pool status the way zpool status lays it out
+func printStatus(w io.Writer, p pool.Pool) {
+	fmt.Fprintf(w, "pool:\t%s\n", p.Name)
+	fmt.Fprintf(w, "state:\t%s\n", p.State)
+	if p.Status != "" {
+		fmt.Fprintf(w, "status:\t%s\n", p.Status)
+	}
+	if p.Action != "" {
+		fmt.Fprintf(w, "action:\t%s\n", p.Action)
+	}
+	if s := p.ScanStats; s != nil && s.Function != "" {
+		fmt.Fprintf(w, "scan:\t%s %s, %s examined of %s, %s errors\n",
+			strings.ToLower(s.Function), strings.ToLower(s.State), s.Examined, s.ToExamine, s.Errors)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "NAME\tSTATE\tREAD\tWRITE\tCKSUM")
+	for _, name := range vdevNames(p.VDevs) {
+		printVDev(w, p.VDevs[name], 0)
+	}
+	if p.ErrorCount != "" {
+		fmt.Fprintf(w, "\nerrors:\t%s\n", p.ErrorCount)
+	}
+}
+
+func printVDev(w io.Writer, v *pool.VDev, depth int) {
+	fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s\n", strings.Repeat("  ", depth), v.Name,
+		remote.Value(v.State), remote.Value(v.ReadErrors), remote.Value(v.WriteErrors),
+		remote.Value(v.ChecksumErrors))
+	for _, name := range vdevNames(v.VDevs) {
+		printVDev(w, v.VDevs[name], depth+1)
+	}
+}
+
+// vdevNames returns the sorted names of a vdev map
+func vdevNames(vdevs map[string]*pool.VDev) []string {
+	names := make([]string, 0, len(vdevs))
+	for name := range vdevs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "get <pool> [property]",
+		Short:             "Show all properties of a pool, or one",
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: remote.CompletePools(1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			var (
+				result pool.ListResult
+				err    error
+			)
+			if len(args) == 2 {
+				result, err = c.GetPoolProperty(ctx, args[0], args[1])
+			} else {
+				result, err = c.GetPoolProperties(ctx, args[0])
+			}
+			if err != nil {
+				return err
+			}
+			return remote.Print(cmd, result, func(w io.Writer) {
+				fmt.Fprintln(w, "NAME\tPROPERTY\tVALUE\tSOURCE")
+				for _, name := range remote.PoolNames(result) {
+					props := result.Pools[name].Properties
+					keys := make([]string, 0, len(props))
+					for k := range props {
+						keys = append(keys, k)
+					}
+					sort.Strings(keys)
+					for _, k := range keys {
+						fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+							name, k, remote.Value(props[k].Value), remote.Value(props[k].Source.Type))
+					}
+				}
+			})
+		}),
+	}
+}
+
+func newSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "set <pool> <property=value>...",
+		Short:             "Set properties of a pool",
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: remote.CompletePools(1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			props, err := remote.Properties(args[1:])
+			if err != nil {
+				return err
+			}
+			for property, value := range props {
+				if err := c.SetPoolProperty(ctx, args[0], property, value); err != nil {
+					return err
+				}
+			}
+			return nil
+		}),
+	}
+}
+
+func newCreateCmd() *cobra.Command {
+	var (
+		cfg   pool.CreateConfig
+		props []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create <pool> <vdev>...",
+		Short: "Create a pool from a zpool-style vdev specification",
+		Long: `Create a pool from a vdev specification as zpool create takes it, e.g.
+
+  rodent pool create tank mirror sda sdb mirror sdc sdd log mirror nvme0n1 nvme1n1
+
+Use "rodent pool plan" to preview a layout before creating it.`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			specs, err := parseVDevs(args[1:])
+			if err != nil {
+				return err
+			}
+			if cfg.Properties, err = remote.Properties(props); err != nil {
+				return err
+			}
+			cfg.Name, cfg.VDevSpec = args[0], specs
+			return c.CreatePool(ctx, cfg)
+		}),
+	}
+
+	cmd.Flags().StringArrayVar(&props, "prop", nil, "Pool property to set, as key=value; repeatable")
+	cmd.Flags().StringVarP(&cfg.MountPoint, "mountpoint", "m", "", "Mount point of the root dataset")
+	cmd.Flags().StringVarP(&cfg.AltRoot, "altroot", "R", "", "Alternate root")
+	return cmd
+}
+
+func newPlanCmd() *cobra.Command {
+	var (
+		cfg   pool.PlanConfig
+		props []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "plan <pool> <disk>...",
+		Short: "Lay disks out into vdevs and preview the pool without creating it",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			var err error
+			if cfg.Properties, err = remote.Properties(props); err != nil {
+				return err
+			}
+			cfg.Name, cfg.Disks = args[0], args[1:]
+			plan, err := c.PlanPool(ctx, cfg)
+			if err != nil {
+				return err
+			}
+			return remote.Print(cmd, plan, func(w io.Writer) {
+				fmt.Fprintf(w, "ashift:\t%d\n", plan.Ashift)
+				fmt.Fprintf(w, "raw size:\t%d\n", plan.RawSize)
+				fmt.Fprintf(w, "usable:\t%d\n", plan.Usable)
+				for _, warning := range plan.Warnings {
+					fmt.Fprintf(w, "warning:\t%s\n", warning)
+				}
+				fmt.Fprintf(w, "\n%s", plan.Preview)
+			})
+		}),
+	}
+
+	cmd.Flags().StringVarP(&cfg.Layout.Type, "layout", "l", pool.LayoutMirror,
+		"stripe, mirror, raidz1, raidz2, raidz3, draid1, draid2 or draid3")
+	cmd.Flags().IntVarP(&cfg.Layout.Width, "width", "w", 0, "Disks per vdev")
+	cmd.Flags().IntVar(&cfg.Layout.DraidData, "draid-data", 0, "dRAID data devices per redundancy group")
+	cmd.Flags().IntVar(&cfg.Layout.DraidSpares, "draid-spares", 0, "dRAID distributed spares")
+	cmd.Flags().IntVar(&cfg.Ashift, "ashift", 0, "Sector size exponent; detected from the disks unless set")
+	cmd.Flags().BoolVar(&cfg.AllowMixedSizes, "allow-mixed-sizes", false, "Allow disks of different sizes")
+	cmd.Flags().StringArrayVar(&props, "prop", nil, "Pool property to set, as key=value; repeatable")
+	return cmd
+}
+
+func newDestroyCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:               "destroy <pool>",
+		Short:             "Destroy a pool",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: remote.CompletePools(1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			return c.DestroyPool(ctx, args[0], force)
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Unmount busy datasets")
+	return cmd
+}
+
+func newImportCmd() *cobra.Command {
+	var (
+		cfg   pool.ImportConfig
+		props []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import [pool|guid]",
+		Short: "Import a pool, or list the pools that can be imported",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				pools, err := c.ListImportable(ctx, pool.ImportableConfig{
+					Paths:     cfg.Paths,
+					Destroyed: cfg.AllowDestroy,
+				})
+				if err != nil {
+					return err
+				}
+				return remote.Print(cmd, pools, func(w io.Writer) {
+					fmt.Fprintln(w, "NAME\tGUID\tSTATE\tDESTROYED")
+					for _, p := range pools {
+						fmt.Fprintf(w, "%s\t%s\t%s\t%t\n", p.Name, p.GUID, p.State, p.Destroyed)
+					}
+				})
+			}
+
+			var err error
+			if cfg.Properties, err = remote.Properties(props); err != nil {
+				return err
+			}
+			if isGUID(args[0]) {
+				cfg.GUID = args[0]
+			} else {
+				cfg.Name = args[0]
+			}
+			return c.ImportPool(ctx, cfg)
+		}),
+	}
+
+	cmd.Flags().StringVar(&cfg.NewName, "name", "", "Import the pool under this name")
+	cmd.Flags().StringSliceVarP(&cfg.Paths, "dir", "d", nil, "Directories or devices to search")
+	cmd.Flags().StringArrayVar(&props, "prop", nil, "Pool property to set, as key=value; repeatable")
+	cmd.Flags().BoolVarP(&cfg.Force, "force", "f", false, "Import even if the pool appears in use")
+	cmd.Flags().BoolVarP(&cfg.AllowDestroy, "destroyed", "D", false, "Import or list destroyed pools")
+	cmd.Flags().BoolVar(&cfg.ReadOnly, "readonly", false, "Import read-only")
+	cmd.Flags().BoolVarP(&cfg.MissingLog, "missing-log", "m", false, "Import with a missing log device")
+	cmd.Flags().StringVarP(&cfg.AltRoot, "altroot", "R", "", "Alternate root")
+	return cmd
+}
+
+// isGUID reports whether s is a numeric pool GUID rather than a name
+func isGUID(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func newExportCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:               "export <pool>",
+		Short:             "Export a pool",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: remote.CompletePools(1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			return c.ExportPool(ctx, args[0], force)
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Unmount busy datasets")
+	return cmd
+}
+
+func newScrubCmd() *cobra.Command {
+	var (
+		cfg         pool.ScrubConfig
+		stop, pause bool
+	)
+
+	cmd := &cobra.Command{
+		Use:               "scrub <pool>",
+		Short:             "Start, pause or stop a scrub",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: remote.CompletePools(1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			switch {
+			case stop && pause:
+				return fmt.Errorf("--stop and --pause are exclusive")
+			case stop:
+				cfg.Action = "stop"
+			case pause:
+				cfg.Action = "pause"
+			default:
+				cfg.Action = "start"
+			}
+			return c.Scrub(ctx, args[0], cfg)
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&stop, "stop", "s", false, "Stop the scrub")
+	cmd.Flags().BoolVarP(&pause, "pause", "p", false, "Pause the scrub")
+	cmd.Flags().BoolVarP(&cfg.ErrorScrub, "errors", "e", false, "Scrub only the blocks in the error log")
+	return cmd
+}
+
+// printPreview writes the layout a dry run reports
+func printPreview(cmd *cobra.Command, preview *pool.LayoutPreview) error {
+	if preview == nil {
+		return nil
+	}
+	return remote.Print(cmd, preview, func(w io.Writer) {
+		fmt.Fprint(w, preview.Output)
+	})
+}
+
+func newAddCmd() *cobra.Command {
+	var cfg pool.AddConfig
+
+	cmd := &cobra.Command{
+		Use:               "add <pool> <vdev>...",
+		Short:             "Add vdevs to a pool",
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: remote.CompletePools(1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			specs, err := parseVDevs(args[1:])
+			if err != nil {
+				return err
+			}
+			cfg.Name, cfg.VDevSpec = args[0], specs
+			preview, err := c.AddVDevs(ctx, cfg)
+			if err != nil {
+				return err
+			}
+			return printPreview(cmd, preview)
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&cfg.Force, "force", "f", false, "Add vdevs of a mismatched redundancy")
+	cmd.Flags().BoolVarP(&cfg.DryRun, "dry-run", "n", false, "Show the resulting layout without adding")
+	return cmd
+}
+
+func newRemoveCmd() *cobra.Command {
+	var (
+		cfg    pool.RemoveConfig
+		cancel bool
+	)
+
+	cmd := &cobra.Command{
+		Use:               "remove <pool> <device>...",
+		Short:             "Evacuate and remove top-level vdevs",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: remote.CompletePools(1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			if cancel {
+				return c.CancelRemoval(ctx, args[0])
+			}
+			if len(args) < 2 {
+				return fmt.Errorf("no devices specified")
+			}
+			cfg.Name, cfg.Devices = args[0], args[1:]
+			preview, err := c.RemoveDevice(ctx, cfg)
+			if err != nil {
+				return err
+			}
+			return printPreview(cmd, preview)
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&cfg.DryRun, "dry-run", "n", false, "Show the memory the removal will use")
+	cmd.Flags().BoolVarP(&cfg.Wait, "wait", "w", false, "Wait until the evacuation completes")
+	cmd.Flags().BoolVarP(&cancel, "stop", "s", false, "Cancel the removal in progress")
+	return cmd
+}
+
+func newAttachCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "attach <pool> <device> <new-device>",
+		Short:             "Attach a device to a mirror, or make a device a mirror",
+		Args:              cobra.ExactArgs(3),
+		ValidArgsFunction: remote.CompletePools(1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			return c.AttachDevice(ctx, args[0], args[1], args[2])
+		}),
+	}
+}
+
+func newDetachCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "detach <pool> <device>",
+		Short:             "Detach a device from a mirror",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: remote.CompletePools(1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			return c.DetachDevice(ctx, args[0], args[1])
+		}),
+	}
+}
+
+func newReplaceCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "replace <pool> <device> <new-device>",
+		Short:             "Replace a device",
+		Args:              cobra.ExactArgs(3),
+		ValidArgsFunction: remote.CompletePools(1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			return c.ReplaceDevice(ctx, args[0], args[1], args[2])
+		}),
+	}
+}
+
+func newOnlineCmd() *cobra.Command {
+	var expand bool
+
+	cmd := &cobra.Command{
+		Use:               "online <pool> <device>...",
+		Short:             "Bring devices online",
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: remote.CompletePools(1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			return c.OnlineDevice(ctx, args[0], args[1:], expand)
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&expand, "expand", "e", false, "Expand the devices to use all their space")
+	return cmd
+}
+
+func newOfflineCmd() *cobra.Command {
+	var temporary, force bool
+
+	cmd := &cobra.Command{
+		Use:               "offline <pool> <device>",
+		Short:             "Take a device offline",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: remote.CompletePools(1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			return c.OfflineDevice(ctx, args[0], args[1], temporary, force)
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&temporary, "temporary", "t", false, "Offline until the next reboot only")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Force the device into a faulted state")
+	return cmd
+}
+
+func newClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "clear <pool> [device]",
+		Short:             "Clear the errors of a device, or of all devices of the pool",
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: remote.CompletePools(1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			device := ""
+			if len(args) == 2 {
+				device = args[1]
+			}
+			return c.ClearErrors(ctx, args[0], device)
+		}),
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stratastor/rodent/pkg/zfs/pool"
+)
+
+// classes are the allocation classes whose vdevs follow their keyword
+var classes = map[string]bool{
+	"log":     true,
+	"cache":   true,
+	"spare":   true,
+	"special": true,
+	"dedup":   true,
+}
+
+// isGroup reports whether arg starts a redundancy group, e.g. mirror,
+// raidz2 or draid2:4d:1s
+func isGroup(arg string) bool {
+	switch arg {
+	case "mirror", "raidz", "raidz1", "raidz2", "raidz3":
+		return true
+	}
+	kind, _, _ := strings.Cut(arg, ":")
+	switch kind {
+	case "draid", "draid1", "draid2", "draid3":
+		return true
+	}
+	return false
+}
+
+// parseVDevs parses a vdev specification the way zpool create and add
+// take it: devices, optionally grouped by a vdev type keyword, and the
+// allocation class keywords whose vdevs follow them, e.g.
+//
+//	mirror sda sdb mirror sdc sdd log mirror nvme0n1 nvme1n1 cache sde
+func parseVDevs(args []string) ([]pool.VDevSpec, error) {
+	var (
+		specs []pool.VDevSpec
+		// class is the allocation class being parsed, if any
+		class *pool.VDevSpec
+		// group is the vdev devices are added to
+		group *pool.VDevSpec
+	)
+
+	flushGroup := func() error {
+		if group == nil {
+			return nil
+		}
+		if len(group.Devices) == 0 {
+			return fmt.Errorf("%s has no devices", group.Type)
+		}
+		if class != nil {
+			class.Children = append(class.Children, *group)
+		} else {
+			specs = append(specs, *group)
+		}
+		group = nil
+		return nil
+	}
+	flushClass := func() error {
+		if err := flushGroup(); err != nil {
+			return err
+		}
+		if class == nil {
+			return nil
+		}
+		if len(class.Children) == 0 {
+			return fmt.Errorf("%s has no devices", class.Type)
+		}
+		specs = append(specs, *class)
+		class = nil
+		return nil
+	}
+
+	for _, arg := range args {
+		switch {
+		case classes[arg]:
+			if err := flushClass(); err != nil {
+				return nil, err
+			}
+			class = &pool.VDevSpec{Type: arg}
+		case isGroup(arg):
+			if err := flushGroup(); err != nil {
+				return nil, err
+			}
+			group = &pool.VDevSpec{Type: arg}
+		default:
+			if group == nil {
+				// Devices outside a group are striped
+				group = &pool.VDevSpec{}
+			}
+			group.Devices = append(group.Devices, arg)
+		}
+	}
+	if err := flushClass(); err != nil {
+		return nil, err
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no vdevs specified")
+	}
+	return specs, nil
+}
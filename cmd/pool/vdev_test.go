@@ -0,0 +1,86 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stratastor/rodent/pkg/zfs/pool"
+)
+
+func TestParseVDevs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []pool.VDevSpec
+		wantErr bool
+	}{
+		{
+			name: "stripe",
+			args: []string{"sda", "sdb"},
+			want: []pool.VDevSpec{{Devices: []string{"sda", "sdb"}}},
+		},
+		{
+			name: "mirrors",
+			args: []string{"mirror", "sda", "sdb", "mirror", "sdc", "sdd"},
+			want: []pool.VDevSpec{
+				{Type: "mirror", Devices: []string{"sda", "sdb"}},
+				{Type: "mirror", Devices: []string{"sdc", "sdd"}},
+			},
+		},
+		{
+			name: "classes",
+			args: []string{
+				"draid2:4d:1s", "sda", "sdb", "sdc", "sdd", "sde", "sdf", "sdg",
+				"log", "mirror", "nvme0n1", "nvme1n1",
+				"cache", "sdh",
+			},
+			want: []pool.VDevSpec{
+				{Type: "draid2:4d:1s", Devices: []string{"sda", "sdb", "sdc", "sdd", "sde", "sdf", "sdg"}},
+				{Type: "log", Children: []pool.VDevSpec{
+					{Type: "mirror", Devices: []string{"nvme0n1", "nvme1n1"}},
+				}},
+				{Type: "cache", Children: []pool.VDevSpec{
+					{Devices: []string{"sdh"}},
+				}},
+			},
+		},
+		{name: "empty", args: nil, wantErr: true},
+		{name: "empty group", args: []string{"mirror", "raidz", "sda"}, wantErr: true},
+		{name: "empty class", args: []string{"sda", "spare"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseVDevs(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package remote holds what the commands that manage ZFS through the API
+// share: the connection flags, output formats and shell completion of
+// dataset and pool names.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/stratastor/rodent/config"
+	"github.com/stratastor/rodent/pkg/client"
+	"github.com/stratastor/rodent/pkg/zfs/dataset"
+	"github.com/stratastor/rodent/pkg/zfs/pool"
+	"gopkg.in/yaml.v2"
+)
+
+// Output formats
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// Environment variables the connection flags default to
+const (
+	EnvURL   = "RODENT_URL"
+	EnvToken = "RODENT_TOKEN"
+)
+
+// AddFlags adds the connection and output flags to a command group
+func AddFlags(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+	flags.String("url", os.Getenv(EnvURL),
+		"Rodent API URL; the local server unless set, also $"+EnvURL)
+	flags.String("token", "", "Bearer token; also $"+EnvToken)
+	flags.Bool("insecure", false, "Skip TLS certificate verification")
+	flags.StringP("output", "o", OutputTable, "Output format: table, json or yaml")
+}
+
+// NewClient returns a client of the server the flags of cmd point at
+func NewClient(cmd *cobra.Command) (*client.Client, error) {
+	flags := cmd.Flags()
+	url, _ := flags.GetString("url")
+	if url == "" {
+		url = fmt.Sprintf("http://localhost:%d", config.GetConfig().Server.Port)
+	}
+	token, _ := flags.GetString("token")
+	if token == "" {
+		token = os.Getenv(EnvToken)
+	}
+	insecure, _ := flags.GetBool("insecure")
+
+	cfg := client.NewConfig(url)
+	cfg.BearerToken = token
+	cfg.AllowInsecure = insecure
+	return client.New(cfg)
+}
+
+// RunFunc runs a command against the API
+type RunFunc func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error
+
+// Run adapts fn to a cobra RunE. fn gets a client and a context that is
+// cancelled on SIGINT or SIGTERM, which cancels the request in flight.
+func Run(fn RunFunc) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if _, err := format(cmd); err != nil {
+			return err
+		}
+		// Usage isn't the problem past the arguments
+		cmd.SilenceUsage = true
+		c, err := NewClient(cmd)
+		if err != nil {
+			return err
+		}
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return fn(ctx, c, cmd, args)
+	}
+}
+
+func format(cmd *cobra.Command) (string, error) {
+	output, _ := cmd.Flags().GetString("output")
+	switch output {
+	case OutputTable, OutputJSON, OutputYAML:
+		return output, nil
+	}
+	return "", fmt.Errorf("unknown output format %q; use table, json or yaml", output)
+}
+
+// Print writes v in the output format of cmd. table writes the table
+// format to a tabwriter; nil prints nothing in table format.
+func Print(cmd *cobra.Command, v interface{}, table func(w io.Writer)) error {
+	output, err := format(cmd)
+	if err != nil {
+		return err
+	}
+	return write(cmd.OutOrStdout(), output, v, table)
+}
+
+func write(out io.Writer, output string, v interface{}, table func(w io.Writer)) error {
+	switch output {
+	case OutputJSON:
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case OutputYAML:
+		// Go through JSON so fields are named by their json tags
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var generic interface{}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&generic); err != nil {
+			return err
+		}
+		data, err = yaml.Marshal(yamlValue(generic))
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	default:
+		if table == nil {
+			return nil
+		}
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		table(w)
+		return w.Flush()
+	}
+}
+
+// yamlValue converts json.Number to numbers yaml writes unquoted
+func yamlValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = yamlValue(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = yamlValue(e)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+	}
+	return v
+}
+
+// Properties parses key=value arguments
+func Properties(args []string) (map[string]string, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	props := make(map[string]string, len(args))
+	for _, arg := range args {
+		k, v, ok := strings.Cut(arg, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("property %q must be key=value", arg)
+		}
+		props[k] = v
+	}
+	return props, nil
+}
+
+// Value formats a property value for tables; "-" when unset
+func Value(v interface{}) string {
+	if v == nil {
+		return "-"
+	}
+	if s := fmt.Sprint(v); s != "" {
+		return s
+	}
+	return "-"
+}
+
+// DatasetNames returns the sorted names of a dataset listing
+func DatasetNames(result dataset.ListResult) []string {
+	names := make([]string, 0, len(result.Datasets))
+	for name := range result.Datasets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PoolNames returns the sorted names of a pool listing
+func PoolNames(result pool.ListResult) []string {
+	names := make([]string, 0, len(result.Pools))
+	for name := range result.Pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PrintDatasets writes a dataset listing with a column per property
+func PrintDatasets(cmd *cobra.Command, result dataset.ListResult, properties ...string) error {
+	return Print(cmd, result, func(w io.Writer) {
+		header := []string{"NAME"}
+		for _, p := range properties {
+			header = append(header, strings.ToUpper(p))
+		}
+		fmt.Fprintln(w, strings.Join(header, "\t"))
+		for _, name := range DatasetNames(result) {
+			row := []string{name}
+			for _, p := range properties {
+				row = append(row, Value(result.Datasets[name].Properties[p].Value))
+			}
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+	})
+}
+
+// PrintProperties writes the properties of a dataset listing, one per row
+func PrintProperties(cmd *cobra.Command, result dataset.ListResult) error {
+	return Print(cmd, result, func(w io.Writer) {
+		fmt.Fprintln(w, "NAME\tPROPERTY\tVALUE\tSOURCE")
+		for _, name := range DatasetNames(result) {
+			props := result.Datasets[name].Properties
+			keys := make([]string, 0, len(props))
+			for k := range props {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, k, Value(props[k].Value), Value(props[k].Source.Type))
+			}
+		}
+	})
+}
+
+// completion is the signature of cobra's ValidArgsFunction
+type completion func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// CompleteDatasets completes the names of datasets of a type, e.g.
+// "filesystem,volume", for the first positional arguments up to max.
+// For "snapshot", dataset names are completed up to the @ and then the
+// snapshots of that dataset.
+func CompleteDatasets(types string, max int) completion {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) >= max {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		c, err := NewClient(cmd)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		directive := cobra.ShellCompDirectiveNoFileComp
+		suffix := ""
+		cfg := dataset.ListConfig{Type: types}
+		if parent, _, ok := strings.Cut(toComplete, "@"); ok {
+			// Snapshots of one dataset
+			cfg = dataset.ListConfig{Name: parent, Type: "snapshot", Depth: 1}
+		} else {
+			if types == "snapshot" {
+				// The dataset the snapshot is of comes first
+				cfg.Type, suffix = "filesystem,volume", "@"
+				directive |= cobra.ShellCompDirectiveNoSpace
+			}
+			if i := strings.LastIndex(toComplete, "/"); i > 0 {
+				// Children of the parent typed so far
+				cfg.Name, cfg.Depth = toComplete[:i], 1
+			}
+		}
+
+		result, err := c.ListDatasets(cmd.Context(), cfg)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		var names []string
+		for _, name := range DatasetNames(result) {
+			if strings.HasPrefix(name, toComplete) {
+				names = append(names, name+suffix)
+			}
+		}
+		return names, directive
+	}
+}
+
+// CompletePools completes pool names for the first positional arguments
+// up to max
+func CompletePools(max int) completion {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) >= max {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		c, err := NewClient(cmd)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		result, err := c.ListPools(cmd.Context())
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		var names []string
+		for _, name := range PoolNames(result) {
+			if strings.HasPrefix(name, toComplete) {
+				names = append(names, name)
+			}
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
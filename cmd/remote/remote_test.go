@@ -0,0 +1,75 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	v := map[string]interface{}{"name": "tank", "size": 1024, "ratio": 1.5}
+	table := func(w io.Writer) {
+		fmt.Fprintln(w, "NAME\tSIZE")
+		fmt.Fprintln(w, "tank\t1024")
+	}
+
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{OutputTable, "NAME  SIZE\ntank  1024\n"},
+		{OutputJSON, "{\n  \"name\": \"tank\",\n  \"ratio\": 1.5,\n  \"size\": 1024\n}\n"},
+		{OutputYAML, "name: tank\nratio: 1.5\nsize: 1024\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := write(&buf, tt.output, v, table); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestProperties(t *testing.T) {
+	props, err := Properties([]string{"compression=lz4", "org.example:note=a=b", "comment="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"compression": "lz4", "org.example:note": "a=b", "comment": ""}
+	if len(props) != len(want) {
+		t.Fatalf("got %v, want %v", props, want)
+	}
+	for k, v := range want {
+		if props[k] != v {
+			t.Errorf("%s: got %q, want %q", k, props[k], v)
+		}
+	}
+
+	for _, arg := range []string{"compression", "=lz4"} {
+		if _, err := Properties([]string{arg}); err == nil {
+			t.Errorf("%q: expected an error", arg)
+		}
+	}
+}
@@ -20,10 +20,15 @@ package cmd
 import (
 	"github.com/spf13/cobra"
 	"github.com/stratastor/rodent/cmd/config"
+	"github.com/stratastor/rodent/cmd/dataset"
+	"github.com/stratastor/rodent/cmd/errors"
 	"github.com/stratastor/rodent/cmd/health"
 	"github.com/stratastor/rodent/cmd/logs"
+	"github.com/stratastor/rodent/cmd/pool"
 	"github.com/stratastor/rodent/cmd/serve"
+	"github.com/stratastor/rodent/cmd/snapshot"
 	"github.com/stratastor/rodent/cmd/status"
+	"github.com/stratastor/rodent/cmd/transfer"
 	"github.com/stratastor/rodent/cmd/version"
 )
 
@@ -39,6 +44,11 @@ func NewRootCmd() *cobra.Command {
 	rootCmd.AddCommand(status.NewStatusCmd())
 	rootCmd.AddCommand(logs.NewLogsCmd())
 	rootCmd.AddCommand(config.NewConfigCmd())
+	rootCmd.AddCommand(errors.NewErrorsCmd())
+	rootCmd.AddCommand(dataset.NewDatasetCmd())
+	rootCmd.AddCommand(snapshot.NewSnapshotCmd())
+	rootCmd.AddCommand(pool.NewPoolCmd())
+	rootCmd.AddCommand(transfer.NewTransferCmd())
 
 	return rootCmd
 }
@@ -0,0 +1,208 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stratastor/rodent/cmd/remote"
+	"github.com/stratastor/rodent/pkg/client"
+	"github.com/stratastor/rodent/pkg/zfs/dataset"
+)
+
+// listColumns are the properties snapshot listings show
+var listColumns = []string{"used", "referenced", "creation"}
+
+func NewSnapshotCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Manage ZFS snapshots and bookmarks through the Rodent API",
+	}
+	remote.AddFlags(cmd)
+
+	cmd.AddCommand(newListCmd())
+	cmd.AddCommand(newCreateCmd())
+	cmd.AddCommand(newDestroyCmd())
+	cmd.AddCommand(newRollbackCmd())
+	cmd.AddCommand(newBookmarkCmd())
+	return cmd
+}
+
+func newListCmd() *cobra.Command {
+	var cfg dataset.ListConfig
+
+	cmd := &cobra.Command{
+		Use:               "list [dataset]",
+		Short:             "List snapshots",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: remote.CompleteDatasets("filesystem,volume", 1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				cfg.Name = args[0]
+			}
+			cfg.Type = "snapshot"
+
+			output, _ := cmd.Flags().GetString("output")
+			if output != remote.OutputTable {
+				result, err := c.ListSnapshots(ctx, cfg)
+				if err != nil {
+					return err
+				}
+				return remote.Print(cmd, result, nil)
+			}
+
+			// Stream rows as they come; pools can hold a great many snapshots
+			out := cmd.OutOrStdout()
+			header := []string{"NAME"}
+			for _, p := range listColumns {
+				header = append(header, strings.ToUpper(p))
+			}
+			fmt.Fprintln(out, strings.Join(header, "\t"))
+			return c.ListSnapshotsStream(ctx, cfg, func(ds dataset.Dataset) error {
+				row := []string{ds.Name}
+				for _, p := range listColumns {
+					row = append(row, remote.Value(ds.Properties[p].Value))
+				}
+				_, err := fmt.Fprintln(out, strings.Join(row, "\t"))
+				return err
+			})
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&cfg.Recursive, "recursive", "r", false, "List the snapshots of the children too")
+	cmd.Flags().UintVarP(&cfg.Depth, "depth", "d", 0, "Limit recursion to depth")
+	return cmd
+}
+
+// splitSnapshot splits dataset@snapshot
+func splitSnapshot(name string) (string, string, error) {
+	ds, snap, ok := strings.Cut(name, "@")
+	if !ok || ds == "" || snap == "" {
+		return "", "", fmt.Errorf("snapshot %q must be dataset@name", name)
+	}
+	return ds, snap, nil
+}
+
+func newCreateCmd() *cobra.Command {
+	var (
+		props     []string
+		recursive bool
+	)
+
+	cmd := &cobra.Command{
+		Use:               "create <dataset@name>",
+		Short:             "Create a snapshot",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: remote.CompleteDatasets("snapshot", 1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			ds, snap, err := splitSnapshot(args[0])
+			if err != nil {
+				return err
+			}
+			properties, err := remote.Properties(props)
+			if err != nil {
+				return err
+			}
+			return c.CreateSnapshot(ctx, dataset.SnapshotConfig{
+				NameConfig: dataset.NameConfig{Name: ds},
+				SnapName:   snap,
+				Recursive:  recursive,
+				Properties: properties,
+			})
+		}),
+	}
+
+	cmd.Flags().StringArrayVar(&props, "prop", nil, "Property to set, as key=value; repeatable")
+	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Snapshot the children atomically too")
+	return cmd
+}
+
+func newDestroyCmd() *cobra.Command {
+	var cfg dataset.DestroyConfig
+
+	cmd := &cobra.Command{
+		Use:               "destroy <dataset@name>",
+		Short:             "Destroy a snapshot",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: remote.CompleteDatasets("snapshot", 1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			if _, _, err := splitSnapshot(args[0]); err != nil {
+				return err
+			}
+			cfg.Name = args[0]
+			if err := c.DestroyDataset(ctx, cfg); err != nil {
+				return err
+			}
+			if cfg.DryRun {
+				fmt.Fprintf(cmd.OutOrStdout(), "would destroy %s\n", args[0])
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&cfg.RecursiveDestroyChildren, "recursive", "r", false,
+		"Destroy the snapshot of the same name in the children")
+	cmd.Flags().BoolVarP(&cfg.RecursiveDestroyDependents, "dependents", "R", false,
+		"Destroy all dependents, including clones")
+	cmd.Flags().BoolVarP(&cfg.DryRun, "dry-run", "n", false, "Validate without destroying")
+	return cmd
+}
+
+func newRollbackCmd() *cobra.Command {
+	var cfg dataset.RollbackConfig
+
+	cmd := &cobra.Command{
+		Use:               "rollback <dataset@name>",
+		Short:             "Roll a dataset back to a snapshot",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: remote.CompleteDatasets("snapshot", 1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			if _, _, err := splitSnapshot(args[0]); err != nil {
+				return err
+			}
+			cfg.Name = args[0]
+			return c.Rollback(ctx, cfg)
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&cfg.DestroyRecent, "recent", "r", false,
+		"Destroy snapshots and bookmarks more recent than the one specified")
+	cmd.Flags().BoolVarP(&cfg.DestroyRecentClones, "clones", "R", false,
+		"Destroy more recent snapshots and bookmarks, and their clones")
+	cmd.Flags().BoolVarP(&cfg.ForceUnmount, "force", "f", false,
+		"With -R, unmount the clones that are destroyed")
+	return cmd
+}
+
+func newBookmarkCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "bookmark <dataset@name> <dataset#bookmark>",
+		Short:             "Create a bookmark of a snapshot",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: remote.CompleteDatasets("snapshot", 1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			return c.CreateBookmark(ctx, dataset.BookmarkConfig{
+				NameConfig:   dataset.NameConfig{Name: args[0]},
+				BookmarkName: args[1],
+			})
+		}),
+	}
+}
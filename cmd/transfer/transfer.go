@@ -0,0 +1,165 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stratastor/rodent/cmd/remote"
+	"github.com/stratastor/rodent/pkg/client"
+	"github.com/stratastor/rodent/pkg/zfs/dataset"
+)
+
+func NewTransferCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "transfer",
+		Short: "Send snapshots to local or remote datasets through the Rodent API",
+	}
+	remote.AddFlags(cmd)
+
+	cmd.AddCommand(newSendCmd())
+	cmd.AddCommand(newResumeTokenCmd())
+	return cmd
+}
+
+func newSendCmd() *cobra.Command {
+	var (
+		cfg          dataset.TransferConfig
+		incremental  string
+		intermediary string
+		host         string
+		props        []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "send <snapshot> <target>",
+		Short: "Send a snapshot and receive it into a dataset",
+		Long: `Send a snapshot and receive it into a dataset, on the same host or, with
+--remote, on another one over SSH. The command returns once the receive
+completes; interrupting it cancels the transfer.`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: remote.CompleteDatasets("snapshot", 1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			send, recv := &cfg.SendConfig, &cfg.ReceiveConfig
+			send.Snapshot, recv.Target = args[0], args[1]
+
+			switch {
+			case incremental != "" && intermediary != "":
+				return fmt.Errorf("-i and -I are exclusive")
+			case incremental != "":
+				send.FromSnapshot, send.Incremental = incremental, true
+			case intermediary != "":
+				send.FromSnapshot, send.Intermediary = intermediary, true
+			}
+
+			if host != "" {
+				rc, err := parseRemote(host)
+				if err != nil {
+					return err
+				}
+				rc.PrivateKey = recv.RemoteConfig.PrivateKey
+				recv.RemoteConfig = rc
+			}
+
+			var err error
+			if recv.Properties, err = remote.Properties(props); err != nil {
+				return err
+			}
+			// Both ends validate without moving data
+			recv.DryRun = send.DryRun
+
+			if err := c.Transfer(ctx, cfg); err != nil {
+				return err
+			}
+			if send.DryRun {
+				fmt.Fprintf(cmd.OutOrStdout(), "would send %s to %s\n", send.Snapshot, recv.Target)
+			}
+			return nil
+		}),
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&incremental, "incremental", "i", "", "Send the changes since this snapshot")
+	flags.StringVarP(&intermediary, "intermediary", "I", "",
+		"Send the changes since this snapshot, with all snapshots in between")
+	flags.BoolVarP(&cfg.SendConfig.Replicate, "replicate", "R", false,
+		"Send the dataset with its children, snapshots and properties")
+	flags.BoolVarP(&cfg.SendConfig.Properties, "props", "p", false, "Send the properties")
+	flags.BoolVarP(&cfg.SendConfig.Raw, "raw", "w", false, "Send encrypted datasets as is")
+	flags.BoolVarP(&cfg.SendConfig.Compressed, "compressed", "c", false, "Send compressed blocks as is")
+	flags.BoolVarP(&cfg.SendConfig.LargeBlocks, "large-block", "L", false, "Allow blocks larger than 128K")
+	flags.BoolVarP(&cfg.SendConfig.EmbedData, "embed", "e", false, "Send embedded blocks as is")
+	flags.StringVarP(&cfg.SendConfig.ResumeToken, "resume", "t", "", "Resume an interrupted transfer from its token")
+	flags.BoolVarP(&cfg.SendConfig.DryRun, "dry-run", "n", false, "Validate the transfer without sending data")
+
+	flags.BoolVarP(&cfg.ReceiveConfig.Force, "force", "F", false, "Roll the target back to its latest snapshot first")
+	flags.BoolVarP(&cfg.ReceiveConfig.Unmounted, "no-mount", "u", false, "Don't mount the received datasets")
+	flags.BoolVarP(&cfg.ReceiveConfig.Resumable, "resumable", "s", false,
+		"Keep the state of an interrupted receive so it can be resumed")
+	flags.StringArrayVar(&props, "prop", nil, "Property to set on the target, as key=value; repeatable")
+	flags.StringSliceVarP(&cfg.ReceiveConfig.ExcludeProps, "exclude", "x", nil, "Properties not to receive")
+
+	flags.StringVar(&host, "remote", "", "Receive on [user@]host[:port] over SSH")
+	flags.StringVar(&cfg.ReceiveConfig.RemoteConfig.PrivateKey, "identity", "",
+		"Private key of the SSH connection, on the Rodent host")
+	return cmd
+}
+
+// parseRemote parses [user@]host[:port]
+func parseRemote(s string) (dataset.RemoteConfig, error) {
+	var rc dataset.RemoteConfig
+	addr := s
+	if user, host, ok := strings.Cut(s, "@"); ok {
+		rc.User, addr = user, host
+	}
+	rc.Host = addr
+	if host, port, ok := strings.Cut(addr, ":"); ok {
+		p, err := strconv.Atoi(port)
+		if err != nil || p <= 0 || p > 65535 {
+			return rc, fmt.Errorf("invalid port %q", port)
+		}
+		rc.Host, rc.Port = host, p
+	}
+	if rc.Host == "" {
+		return rc, fmt.Errorf("remote %q has no host", s)
+	}
+	return rc, nil
+}
+
+func newResumeTokenCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "resume-token <dataset>",
+		Short:             "Show the token to resume an interrupted receive into a dataset",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: remote.CompleteDatasets("filesystem,volume", 1),
+		RunE: remote.Run(func(ctx context.Context, c *client.Client, cmd *cobra.Command, args []string) error {
+			token, err := c.GetResumeToken(ctx, dataset.NameConfig{Name: args[0]})
+			if err != nil {
+				return err
+			}
+			return remote.Print(cmd, map[string]string{"token": token}, func(w io.Writer) {
+				fmt.Fprintln(w, token)
+			})
+		}),
+	}
+}
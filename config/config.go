@@ -59,6 +59,96 @@ type Config struct {
 		SentryDSN    string `mapstructure:"sentryDSN"`
 	} `mapstructure:"logger"`
 
+	ZFS struct {
+		ChannelPrograms struct {
+			// AllowCustom permits callers with the admin role to run
+			// caller supplied Lua scripts in addition to the vetted
+			// script library.
+			AllowCustom bool `mapstructure:"allowCustom"`
+			// AdminToken grants the admin role custom scripts require.
+			// Rodent has no user accounts, so a caller holds the role by
+			// sending it as a bearer token. Custom scripts stay refused
+			// while it is empty.
+			AdminToken       string `mapstructure:"adminToken"`
+			InstructionLimit uint64 `mapstructure:"instructionLimit"`
+			MemoryLimit      uint64 `mapstructure:"memoryLimit"`
+		} `mapstructure:"channelPrograms"`
+
+		// AutoReplace polls pool status and replaces FAULTED devices with
+		// an available hot spare of the same pool
+		AutoReplace struct {
+			Enabled  bool   `mapstructure:"enabled"`
+			Interval string `mapstructure:"interval"`
+		} `mapstructure:"autoReplace"`
+
+		// Binaries are the absolute paths of the zfs and zpool executables.
+		// Empty paths are searched for in /usr/sbin, /sbin and
+		// /usr/local/sbin. Either way the binaries must be owned by root
+		// and not world-writable.
+		Binaries struct {
+			ZFS   string `mapstructure:"zfs"`
+			Zpool string `mapstructure:"zpool"`
+		} `mapstructure:"binaries"`
+
+		// Executor bounds the zfs/zpool processes run at once. Commands
+		// are queued by class: interactive mutating, interactive read and
+		// background; a class whose queue is full fails new commands with
+		// a retry-after hint.
+		Executor struct {
+			MaxConcurrent int `mapstructure:"maxConcurrent"`
+			QueueDepth    int `mapstructure:"queueDepth"`
+			Limits        struct {
+				Mutating   int `mapstructure:"mutating"`
+				Read       int `mapstructure:"read"`
+				Background int `mapstructure:"background"`
+			} `mapstructure:"limits"`
+		} `mapstructure:"executor"`
+
+		// DeviceNaming maps device paths to stable names before they are
+		// passed to zpool: by-id, by-path or none
+		DeviceNaming string `mapstructure:"deviceNaming"`
+
+		Scrub struct {
+			// HistoryPath is the JSON file completed scrubs are recorded in
+			HistoryPath string `mapstructure:"historyPath"`
+			// Interval between checks of pool scan state and schedules
+			Interval string `mapstructure:"interval"`
+			// MaxConcurrent caps the scheduled scrubs running at once
+			MaxConcurrent int `mapstructure:"maxConcurrent"`
+			// QuietHours is a local time window, e.g. "08:00-18:00", in
+			// which scheduled scrubs are paused and none are started
+			QuietHours string `mapstructure:"quietHours"`
+			// Schedules sets the scrub cadence of pools, e.g. "30d"
+			Schedules []struct {
+				Pool    string `mapstructure:"pool"`
+				Cadence string `mapstructure:"cadence"`
+			} `mapstructure:"schedules"`
+		} `mapstructure:"scrub"`
+	} `mapstructure:"zfs"`
+
+	Shares struct {
+		SMB struct {
+			// IncludeFile holds the generated share stanzas; smb.conf
+			// must include it
+			IncludeFile string `mapstructure:"includeFile"`
+			// MainConfig is the smb.conf checked for the include
+			MainConfig string `mapstructure:"mainConfig"`
+			// ShadowFormat is the strftime format of snapshot names shown
+			// as Previous Versions, unless a share sets its own
+			ShadowFormat string `mapstructure:"shadowFormat"`
+		} `mapstructure:"smb"`
+
+		ISCSI struct {
+			// BaseIQN prefixes the target names generated for volumes
+			BaseIQN string `mapstructure:"baseIQN"`
+			// Portals are the ip:port pairs targets listen on unless an
+			// export lists its own
+			Portals []string `mapstructure:"portals"`
+			// SaveConfig is the file targetcli persists targets in
+			SaveConfig string `mapstructure:"saveConfig"`
+		} `mapstructure:"iscsi"`
+	} `mapstructure:"shares"`
+
 	Environment string `mapstructure:"environment"`
 }
 
@@ -104,6 +194,31 @@ func LoadConfig(configFilePath string) *Config {
 		viper.SetDefault("logger.logLevel", "info")
 		viper.SetDefault("logger.enableSentry", false)
 		viper.SetDefault("logger.sentryDSN", "")
+		viper.SetDefault("zfs.channelPrograms.allowCustom", false)
+		viper.SetDefault("zfs.channelPrograms.adminToken", "")
+		viper.SetDefault("zfs.channelPrograms.instructionLimit", 10000000)
+		viper.SetDefault("zfs.channelPrograms.memoryLimit", 10485760)
+		viper.SetDefault("zfs.autoReplace.enabled", false)
+		viper.SetDefault("zfs.autoReplace.interval", "1m")
+		viper.SetDefault("zfs.binaries.zfs", "")
+		viper.SetDefault("zfs.binaries.zpool", "")
+		viper.SetDefault("zfs.executor.maxConcurrent", 16)
+		viper.SetDefault("zfs.executor.queueDepth", 64)
+		viper.SetDefault("zfs.executor.limits.mutating", 12)
+		viper.SetDefault("zfs.executor.limits.read", 8)
+		viper.SetDefault("zfs.executor.limits.background", 2)
+		viper.SetDefault("zfs.deviceNaming", "by-id")
+		viper.SetDefault("zfs.scrub.historyPath",
+			filepath.Join(constants.SystemStateDir, "scrub_history.json"))
+		viper.SetDefault("zfs.scrub.interval", "5m")
+		viper.SetDefault("zfs.scrub.maxConcurrent", 1)
+		viper.SetDefault("zfs.scrub.quietHours", "")
+		viper.SetDefault("shares.smb.includeFile", "/etc/samba/rodent-shares.conf")
+		viper.SetDefault("shares.smb.mainConfig", "/etc/samba/smb.conf")
+		viper.SetDefault("shares.smb.shadowFormat", "@GMT-%Y.%m.%d-%H.%M.%S")
+		viper.SetDefault("shares.iscsi.baseIQN", "iqn.2024-01.in.tinkershack.rodent")
+		viper.SetDefault("shares.iscsi.portals", []string{"0.0.0.0:3260"})
+		viper.SetDefault("shares.iscsi.saveConfig", "/etc/target/saveconfig.json")
 
 		// Bind environment variables
 		viper.AutomaticEnv()
@@ -26,4 +26,7 @@ const (
 	UserConfigDir   = "~/.rodent"
 	ConfigFileName  = "rodent.yml"
 	StateFileName   = "rodent_state.yml"
+
+	// Persistent state such as scrub history
+	SystemStateDir = "/var/lib/rodent"
 )
@@ -18,7 +18,7 @@
 package main
 
 import (
-	"fmt"
+	"os"
 
 	"github.com/stratastor/rodent/cmd"
 )
@@ -27,6 +27,7 @@ func main() {
 	rootCmd := cmd.NewRootCmd()
 
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+		// cobra has printed the error
+		os.Exit(1)
 	}
 }
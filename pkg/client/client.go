@@ -0,0 +1,267 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package client is a typed Go client of the Rodent HTTP API. Methods take
+// and return the config and result types of the dataset and pool packages.
+//
+// Error responses are decoded into *errors.RodentError with the code the
+// server reported, so callers can switch on it as they would in process.
+// Calls return ctx.Err() when their context is done.
+//
+// Authentication, TLS and retries are set on the httpclient.ClientConfig:
+//
+//	cfg := client.NewConfig("https://rodent.example:8042")
+//	cfg.BearerToken = token
+//	c, err := client.New(cfg)
+//
+// Requests refused by a full command queue are retried after the
+// Retry-After the server sends. Transport failures and gateway errors are
+// only retried for requests that don't change state.
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/httpclient"
+)
+
+// APIPrefix is the path the API is served under
+const APIPrefix = "/api/v1"
+
+// mimeNDJSON is the content type of streamed listings
+const mimeNDJSON = "application/x-ndjson"
+
+// Client calls the Rodent API of one server
+type Client struct {
+	http *httpclient.Client
+}
+
+// NewConfig returns the httpclient defaults for the server at baseURL, e.g.
+// http://localhost:8042. There's no client timeout: operations such as
+// transfers take as long as the command, so bound them with the context.
+func NewConfig(baseURL string) httpclient.ClientConfig {
+	cfg := httpclient.NewClientConfig()
+	cfg.BaseURL = baseURL
+	cfg.Timeout = 0
+	return cfg
+}
+
+// New returns a client of the server at cfg.BaseURL
+func New(cfg httpclient.ClientConfig) (*Client, error) {
+	base, err := url.Parse(cfg.BaseURL)
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return nil, errors.New(errors.ClientConfig,
+			"base URL must be an absolute http(s) URL").WithMetadata("base_url", cfg.BaseURL)
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/") + APIPrefix
+	cfg.RetryConditions = append(cfg.RetryConditions, shouldRetry)
+
+	hc := httpclient.NewClient(cfg)
+	hc.SetRetryAfter(retryAfter)
+	// Responses that are retried are otherwise left open when streaming
+	hc.AddRetryHook(func(resp *resty.Response, _ error) {
+		if resp != nil && resp.RawResponse != nil {
+			resp.RawResponse.Body.Close()
+		}
+	})
+	return &Client{http: hc}, nil
+}
+
+// safeKey marks the context of requests that don't change state
+type safeKey struct{}
+
+// request is one API call
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   interface{}
+	// result is decoded from a successful response; nil to discard it
+	result interface{}
+	// safe requests don't change state and can be repeated after a
+	// transport failure; GET requests always are
+	safe bool
+}
+
+// do sends r and decodes the response into r.result
+func (c *Client) do(ctx context.Context, r request) error {
+	resp, err := c.send(ctx, r, false)
+	if err != nil {
+		return err
+	}
+	if resp.IsError() {
+		return decodeError(resp.StatusCode(), resp.Body())
+	}
+	if r.result != nil && len(resp.Body()) > 0 {
+		if err := json.Unmarshal(resp.Body(), r.result); err != nil {
+			return errors.Wrap(err, errors.ClientResponse).
+				WithMetadata("method", r.method).
+				WithMetadata("path", r.path)
+		}
+	}
+	return nil
+}
+
+// stream sends r asking for an NDJSON response and calls fn with each line.
+// An error the server reports after the stream started is returned as is.
+func (c *Client) stream(ctx context.Context, r request, fn func(line []byte) error) error {
+	resp, err := c.send(ctx, r, true)
+	if err != nil {
+		return err
+	}
+	body := resp.RawBody()
+	defer body.Close()
+
+	if resp.IsError() {
+		data, _ := io.ReadAll(body)
+		return decodeError(resp.StatusCode(), data)
+	}
+
+	reader := bufio.NewReader(body)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var late struct {
+				Error *errors.RodentError `json:"error"`
+			}
+			if json.Unmarshal(line, &late) == nil && late.Error != nil {
+				if late.Error.Code == 0 {
+					return errors.New(errors.ClientResponse, "stream ended with an error")
+				}
+				return late.Error
+			}
+			if err := fn(line); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return errors.Wrap(err, errors.ClientResponse).
+				WithMetadata("method", r.method).
+				WithMetadata("path", r.path)
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, r request, stream bool) (*resty.Response, error) {
+	if r.safe {
+		ctx = context.WithValue(ctx, safeKey{}, true)
+	}
+	req := c.http.R().SetContext(ctx)
+	if r.query != nil {
+		req.SetQueryParamsFromValues(r.query)
+	}
+	if r.body != nil {
+		req.SetHeader("Content-Type", "application/json").SetBody(r.body)
+	}
+	if stream {
+		req.SetHeader("Accept", mimeNDJSON).SetDoNotParseResponse(true)
+	} else {
+		req.SetHeader("Accept", "application/json")
+	}
+
+	resp, err := req.Execute(r.method, r.path)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, errors.Wrap(err, errors.ClientRequest).
+			WithMetadata("method", r.method).
+			WithMetadata("path", r.path)
+	}
+	return resp, nil
+}
+
+// decodeError returns the RodentError of an error response, or a
+// ClientResponse error when the body isn't one
+func decodeError(status int, body []byte) error {
+	var re errors.RodentError
+	if err := json.Unmarshal(body, &re); err == nil && re.Code != 0 {
+		re.HTTPStatus = status
+		return &re
+	}
+	return errors.New(errors.ClientResponse, strings.TrimSpace(string(body))).
+		WithMetadata("http_status", strconv.Itoa(status))
+}
+
+// shouldRetry retries requests the server refused because its command
+// queue was full; those never ran. Transport failures and gateway errors
+// are only retried for requests that are safe to repeat.
+func shouldRetry(resp *resty.Response, err error) bool {
+	if resp == nil || resp.Request == nil {
+		return false
+	}
+	if resp.StatusCode() == http.StatusServiceUnavailable && resp.Header().Get("Retry-After") != "" {
+		return true
+	}
+
+	req := resp.Request
+	if req.Method != http.MethodGet && req.Context().Value(safeKey{}) == nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode() {
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter waits for the Retry-After seconds of the response, if any.
+// Zero falls back to the client's backoff.
+func retryAfter(_ *resty.Client, resp *resty.Response) (time.Duration, error) {
+	if secs, err := strconv.Atoi(resp.Header().Get("Retry-After")); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second, nil
+	}
+	return 0, nil
+}
+
+// path joins the escaped segments of a pool path, e.g.
+// path("pools", name, "status")
+func path(segments ...string) string {
+	escaped := make([]string, len(segments))
+	for i, s := range segments {
+		escaped[i] = url.PathEscape(s)
+	}
+	return "/" + strings.Join(escaped, "/")
+}
+
+// boolQuery returns the query with key=true when set
+func boolQuery(key string, set bool) url.Values {
+	if !set {
+		return nil
+	}
+	return url.Values{key: {"true"}}
+}
@@ -0,0 +1,281 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	stderrors "errors"
+
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/zfs/dataset"
+	"github.com/stratastor/rodent/pkg/zfs/pool"
+)
+
+// newTestClient returns a client of a server running handler, with quick
+// retries
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	cfg := NewConfig(srv.URL)
+	cfg.RetryCount = 2
+	cfg.RetryWaitTime = time.Millisecond
+	cfg.RetryMaxWaitTime = 5 * time.Millisecond
+	cfg.BearerToken = "secret"
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestNewRejectsRelativeURL(t *testing.T) {
+	for _, base := range []string{"", "localhost:8042", "/api"} {
+		if _, err := New(NewConfig(base)); err == nil {
+			t.Errorf("New(%q) succeeded", base)
+		} else if re, ok := err.(*errors.RodentError); !ok || re.Code != errors.ClientConfig {
+			t.Errorf("New(%q): %v", base, err)
+		}
+	}
+}
+
+func TestRequestAndResult(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/dataset/list" || r.Method != http.MethodPost {
+			t.Errorf("got %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var cfg dataset.ListConfig
+		if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil || cfg.Name != "tank" || !cfg.Recursive {
+			t.Errorf("body: %+v %v", cfg, err)
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"result": dataset.ListResult{Datasets: map[string]dataset.Dataset{"tank/a": {Name: "tank/a"}}},
+		})
+	})
+
+	result, err := c.ListDatasets(context.Background(), dataset.ListConfig{Name: "tank", Recursive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := result.Datasets["tank/a"]; !ok {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestPathAndQuery(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/pools/importable":
+			if dirs := r.URL.Query()["dir"]; len(dirs) != 2 || r.URL.Query().Get("destroyed") != "true" {
+				t.Errorf("query = %v", r.URL.Query())
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"pools": []pool.ImportablePool{{Name: "tank"}},
+			})
+		case "/api/v1/pools/tank/properties/comment":
+			if r.Method != http.MethodPut {
+				t.Errorf("method = %s", r.Method)
+			}
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != `{"value":"a b"}` {
+				t.Errorf("body = %s", body)
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL)
+		}
+	})
+
+	pools, err := c.ListImportable(context.Background(), pool.ImportableConfig{
+		Paths:     []string{"/dev/disk/by-id", "/dev"},
+		Destroyed: true,
+	})
+	if err != nil || len(pools) != 1 || pools[0].Name != "tank" {
+		t.Errorf("ListImportable = %v, %v", pools, err)
+	}
+	if err := c.SetPoolProperty(context.Background(), "tank", "comment", "a b"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDecodeRodentError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, errors.New(errors.ZFSNotFound, "cannot open 'tank/x'").
+			WithMetadata("class", "not_found"))
+	})
+
+	err := c.CreateSnapshot(context.Background(), dataset.SnapshotConfig{NameConfig: dataset.NameConfig{Name: "tank/x"}, SnapName: "s"})
+	re, ok := err.(*errors.RodentError)
+	if !ok {
+		t.Fatalf("error %T %v is not a RodentError", err, err)
+	}
+	if re.Code != errors.ZFSNotFound || re.Domain != errors.DomainZFS ||
+		re.HTTPStatus != http.StatusNotFound || re.Metadata["class"] != "not_found" {
+		t.Errorf("decoded %+v", re)
+	}
+	if !errors.Is(err, errors.New(errors.ZFSNotFound, "")) {
+		t.Error("errors.Is doesn't match the code")
+	}
+
+	// Bodies that aren't a RodentError keep the status
+	c = newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "404 page not found", http.StatusNotFound)
+	})
+	err = c.Resilver(context.Background(), "tank")
+	if re, ok := err.(*errors.RodentError); !ok || re.Code != errors.ClientResponse ||
+		re.Metadata["http_status"] != "404" {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	snapshot := dataset.SnapshotConfig{NameConfig: dataset.NameConfig{Name: "tank"}, SnapName: "s"}
+	tests := []struct {
+		name   string
+		status int
+		header string
+		call   func(*Client) error
+		want   int32
+	}{
+		{
+			name:   "queue full mutation",
+			status: http.StatusServiceUnavailable,
+			header: "1",
+			call: func(c *Client) error {
+				return c.CreateSnapshot(context.Background(), snapshot)
+			},
+			want: 2,
+		},
+		{
+			name:   "gateway error read",
+			status: http.StatusBadGateway,
+			call: func(c *Client) error {
+				_, err := c.ListSnapshots(context.Background(), dataset.ListConfig{Name: "tank"})
+				return err
+			},
+			want: 2,
+		},
+		{
+			name:   "gateway error mutation",
+			status: http.StatusBadGateway,
+			call: func(c *Client) error {
+				return c.CreateSnapshot(context.Background(), snapshot)
+			},
+			want: 1,
+		},
+		{
+			name:   "service unavailable without retry-after",
+			status: http.StatusServiceUnavailable,
+			call: func(c *Client) error {
+				_, err := c.ListPools(context.Background())
+				return err
+			},
+			want: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&calls, 1) == 1 {
+					if tt.header != "" {
+						w.Header().Set("Retry-After", tt.header)
+					}
+					writeJSON(w, tt.status, errors.New(errors.CommandQueueFull, ""))
+					return
+				}
+				writeJSON(w, http.StatusOK, map[string]interface{}{})
+			})
+
+			err := tt.call(c)
+			if got := atomic.LoadInt32(&calls); got != tt.want {
+				t.Errorf("calls = %d, want %d", got, tt.want)
+			}
+			if tt.want == 2 && err != nil {
+				t.Errorf("retried call failed: %v", err)
+			}
+			if tt.want == 1 && err == nil {
+				t.Error("unretried call succeeded")
+			}
+		})
+	}
+}
+
+func TestContextCancel(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		// The server notices the client going away once the body is read
+		io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := c.Transfer(ctx, dataset.TransferConfig{})
+	if !stderrors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want context.DeadlineExceeded", err)
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Error("the call outlived its context")
+	}
+}
+
+func TestListSnapshotsStream(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept"), mimeNDJSON) {
+			t.Errorf("Accept = %q", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", mimeNDJSON)
+		enc := json.NewEncoder(w)
+		enc.Encode(dataset.Dataset{Name: "tank@a"})
+		enc.Encode(dataset.Dataset{Name: "tank@b"})
+		enc.Encode(map[string]interface{}{"error": errors.New(errors.CommandTimeout, "")})
+	})
+
+	var names []string
+	err := c.ListSnapshotsStream(context.Background(), dataset.ListConfig{Name: "tank"}, func(ds dataset.Dataset) error {
+		names = append(names, ds.Name)
+		return nil
+	})
+	if len(names) != 2 || names[0] != "tank@a" || names[1] != "tank@b" {
+		t.Errorf("streamed %v", names)
+	}
+	if re, ok := err.(*errors.RodentError); !ok || re.Code != errors.CommandTimeout {
+		t.Errorf("late error = %v", err)
+	}
+}
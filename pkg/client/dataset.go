@@ -0,0 +1,253 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/stratastor/rodent/pkg/zfs/dataset"
+)
+
+// Dataset responses wrap their result in {"result": ...}
+type (
+	listResponse struct {
+		Result dataset.ListResult `json:"result"`
+	}
+	diffResponse struct {
+		Result dataset.DiffResult `json:"result"`
+	}
+	allowResponse struct {
+		Result dataset.AllowResult `json:"result"`
+	}
+	tokenResponse struct {
+		Result string `json:"result"`
+	}
+)
+
+// list calls a dataset listing endpoint
+func (c *Client) list(ctx context.Context, path string, body interface{}) (dataset.ListResult, error) {
+	var out listResponse
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   path,
+		body:   body,
+		result: &out,
+		safe:   true,
+	})
+	return out.Result, err
+}
+
+// ListDatasets lists datasets of any type
+func (c *Client) ListDatasets(ctx context.Context, cfg dataset.ListConfig) (dataset.ListResult, error) {
+	return c.list(ctx, "/dataset/list", cfg)
+}
+
+func (c *Client) DestroyDataset(ctx context.Context, cfg dataset.DestroyConfig) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/dataset", body: cfg})
+}
+
+func (c *Client) RenameDataset(ctx context.Context, cfg dataset.RenameConfig) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/dataset/rename", body: cfg})
+}
+
+// Diff lists the differences between two snapshots, or a snapshot and its
+// dataset
+func (c *Client) Diff(ctx context.Context, cfg dataset.DiffConfig) (dataset.DiffResult, error) {
+	var out diffResponse
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/dataset/diff",
+		body:   cfg,
+		result: &out,
+		safe:   true,
+	})
+	return out.Result, err
+}
+
+// DiffStream calls fn with each difference as the server reports it
+func (c *Client) DiffStream(ctx context.Context, cfg dataset.DiffConfig, fn func(dataset.DiffEntry) error) error {
+	return c.stream(ctx, request{
+		method: http.MethodPost,
+		path:   "/dataset/diff",
+		body:   cfg,
+		safe:   true,
+	}, func(line []byte) error {
+		var entry dataset.DiffEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return err
+		}
+		return fn(entry)
+	})
+}
+
+func (c *Client) ListDatasetProperties(ctx context.Context, cfg dataset.NameConfig) (dataset.ListResult, error) {
+	return c.list(ctx, "/dataset/properties/list", cfg)
+}
+
+func (c *Client) GetDatasetProperty(ctx context.Context, cfg dataset.PropertyConfig) (dataset.ListResult, error) {
+	return c.list(ctx, "/dataset/property/fetch", cfg)
+}
+
+func (c *Client) SetDatasetProperty(ctx context.Context, cfg dataset.SetPropertyConfig) error {
+	return c.do(ctx, request{method: http.MethodPut, path: "/dataset/property", body: cfg})
+}
+
+func (c *Client) InheritDatasetProperty(ctx context.Context, cfg dataset.InheritConfig) error {
+	return c.do(ctx, request{method: http.MethodPut, path: "/dataset/property/inherit", body: cfg})
+}
+
+func (c *Client) ListFilesystems(ctx context.Context, cfg dataset.ListConfig) (dataset.ListResult, error) {
+	return c.list(ctx, "/dataset/filesystems/list", cfg)
+}
+
+func (c *Client) CreateFilesystem(ctx context.Context, cfg dataset.FilesystemConfig) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/dataset/filesystem", body: cfg})
+}
+
+func (c *Client) Mount(ctx context.Context, cfg dataset.MountConfig) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/dataset/filesystem/mount", body: cfg})
+}
+
+func (c *Client) Unmount(ctx context.Context, cfg dataset.UnmountConfig) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/dataset/filesystem/unmount", body: cfg})
+}
+
+func (c *Client) ListVolumes(ctx context.Context, cfg dataset.ListConfig) (dataset.ListResult, error) {
+	return c.list(ctx, "/dataset/volumes/list", cfg)
+}
+
+func (c *Client) CreateVolume(ctx context.Context, cfg dataset.VolumeConfig) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/dataset/volume", body: cfg})
+}
+
+func (c *Client) ResizeVolume(
+	ctx context.Context,
+	cfg dataset.VolumeResizeConfig,
+) (*dataset.VolumeResizeResult, error) {
+	var out dataset.VolumeResizeResult
+	if err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/dataset/volume/resize",
+		body:   cfg,
+		result: &out,
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CloneVolume clones a volume into a new, promoted volume
+func (c *Client) CloneVolume(
+	ctx context.Context,
+	cfg dataset.VolumeCloneConfig,
+) (*dataset.VolumeCloneResult, error) {
+	var out dataset.VolumeCloneResult
+	if err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/dataset/volume/clone",
+		body:   cfg,
+		result: &out,
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) ListSnapshots(ctx context.Context, cfg dataset.ListConfig) (dataset.ListResult, error) {
+	return c.list(ctx, "/dataset/snapshots/list", cfg)
+}
+
+// ListSnapshotsStream calls fn with each snapshot as zfs lists it, without
+// holding the whole listing in memory
+func (c *Client) ListSnapshotsStream(
+	ctx context.Context,
+	cfg dataset.ListConfig,
+	fn func(dataset.Dataset) error,
+) error {
+	return c.stream(ctx, request{
+		method: http.MethodPost,
+		path:   "/dataset/snapshots/list",
+		body:   cfg,
+		safe:   true,
+	}, func(line []byte) error {
+		var ds dataset.Dataset
+		if err := json.Unmarshal(line, &ds); err != nil {
+			return err
+		}
+		return fn(ds)
+	})
+}
+
+func (c *Client) CreateSnapshot(ctx context.Context, cfg dataset.SnapshotConfig) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/dataset/snapshot", body: cfg})
+}
+
+// Rollback rolls a dataset back to a snapshot
+func (c *Client) Rollback(ctx context.Context, cfg dataset.RollbackConfig) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/dataset/snapshot/rollback", body: cfg})
+}
+
+// Clone creates a clone of a snapshot
+func (c *Client) Clone(ctx context.Context, cfg dataset.CloneConfig) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/dataset/clone", body: cfg})
+}
+
+func (c *Client) PromoteClone(ctx context.Context, cfg dataset.NameConfig) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/dataset/clone/promote", body: cfg})
+}
+
+func (c *Client) ListBookmarks(ctx context.Context, cfg dataset.ListConfig) (dataset.ListResult, error) {
+	return c.list(ctx, "/dataset/bookmarks/list", cfg)
+}
+
+func (c *Client) CreateBookmark(ctx context.Context, cfg dataset.BookmarkConfig) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/dataset/bookmark", body: cfg})
+}
+
+// ListPermissions lists the permissions delegated on a dataset
+func (c *Client) ListPermissions(ctx context.Context, cfg dataset.NameConfig) (dataset.AllowResult, error) {
+	var out allowResponse
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/dataset/permissions/list",
+		body:   cfg,
+		result: &out,
+		safe:   true,
+	})
+	return out.Result, err
+}
+
+// Allow delegates permissions on a dataset
+func (c *Client) Allow(ctx context.Context, cfg dataset.AllowConfig) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/dataset/permissions", body: cfg})
+}
+
+// Unallow removes delegated permissions
+func (c *Client) Unallow(ctx context.Context, cfg dataset.UnallowConfig) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/dataset/permissions", body: cfg})
+}
+
+func (c *Client) Share(ctx context.Context, cfg dataset.ShareConfig) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/dataset/share", body: cfg})
+}
+
+func (c *Client) Unshare(ctx context.Context, cfg dataset.UnshareConfig) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/dataset/share", body: cfg})
+}
@@ -0,0 +1,384 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/stratastor/rodent/pkg/zfs/pool"
+)
+
+func (c *Client) CreatePool(ctx context.Context, cfg pool.CreateConfig) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/pools", body: cfg})
+}
+
+// PlanPool validates a pool layout and previews it with zpool create -n
+func (c *Client) PlanPool(ctx context.Context, cfg pool.PlanConfig) (*pool.CreatePlan, error) {
+	var out pool.CreatePlan
+	if err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/pools/plan",
+		body:   cfg,
+		result: &out,
+		safe:   true,
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) ListPools(ctx context.Context) (pool.ListResult, error) {
+	var out pool.ListResult
+	err := c.do(ctx, request{method: http.MethodGet, path: "/pools", result: &out})
+	return out, err
+}
+
+func (c *Client) DestroyPool(ctx context.Context, name string, force bool) error {
+	return c.do(ctx, request{
+		method: http.MethodDelete,
+		path:   path("pools", name),
+		query:  boolQuery("force", force),
+	})
+}
+
+// ListImportable lists pools zpool import finds on the server
+func (c *Client) ListImportable(ctx context.Context, cfg pool.ImportableConfig) ([]pool.ImportablePool, error) {
+	query := url.Values{"dir": cfg.Paths}
+	if cfg.Destroyed {
+		query.Set("destroyed", "true")
+	}
+	var out struct {
+		Pools []pool.ImportablePool `json:"pools"`
+	}
+	err := c.do(ctx, request{method: http.MethodGet, path: "/pools/importable", query: query, result: &out})
+	return out.Pools, err
+}
+
+func (c *Client) ImportPool(ctx context.Context, cfg pool.ImportConfig) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/pools/import", body: cfg})
+}
+
+func (c *Client) ExportPool(ctx context.Context, name string, force bool) error {
+	return c.do(ctx, request{
+		method: http.MethodPost,
+		path:   path("pools", name, "export"),
+		query:  boolQuery("force", force),
+	})
+}
+
+// PoolStatus returns the status of a pool; with progress, including the
+// progress of scans, initializing and trimming
+func (c *Client) PoolStatus(ctx context.Context, name string, progress bool) (pool.PoolStatus, error) {
+	var out pool.PoolStatus
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   path("pools", name, "status"),
+		query:  boolQuery("progress", progress),
+		result: &out,
+	})
+	return out, err
+}
+
+func (c *Client) GetPoolProperties(ctx context.Context, name string) (pool.ListResult, error) {
+	var out pool.ListResult
+	err := c.do(ctx, request{method: http.MethodGet, path: path("pools", name, "properties"), result: &out})
+	return out, err
+}
+
+func (c *Client) GetPoolProperty(ctx context.Context, name, property string) (pool.ListResult, error) {
+	var out pool.ListResult
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   path("pools", name, "properties", property),
+		result: &out,
+	})
+	return out, err
+}
+
+func (c *Client) SetPoolProperty(ctx context.Context, name, property, value string) error {
+	return c.do(ctx, request{
+		method: http.MethodPut,
+		path:   path("pools", name, "properties", property),
+		body:   map[string]interface{}{"value": value},
+	})
+}
+
+// History returns the command history of a pool
+func (c *Client) History(ctx context.Context, name string, cfg pool.HistoryConfig) ([]pool.HistoryEntry, error) {
+	query := url.Values{}
+	if cfg.Internal {
+		query.Set("internal", "true")
+	}
+	if cfg.Long {
+		query.Set("long", "true")
+	}
+	if cfg.Dataset != "" {
+		query.Set("dataset", cfg.Dataset)
+	}
+	if !cfg.Since.IsZero() {
+		query.Set("since", cfg.Since.Format(time.RFC3339))
+	}
+	if !cfg.Until.IsZero() {
+		query.Set("until", cfg.Until.Format(time.RFC3339))
+	}
+	var out struct {
+		History []pool.HistoryEntry `json:"history"`
+	}
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   path("pools", name, "history"),
+		query:  query,
+		result: &out,
+	})
+	return out.History, err
+}
+
+// Scrub starts, pauses or stops a scrub
+func (c *Client) Scrub(ctx context.Context, name string, cfg pool.ScrubConfig) error {
+	return c.do(ctx, request{method: http.MethodPost, path: path("pools", name, "scrub"), body: cfg})
+}
+
+// ScrubHistory returns the most recent completed scrubs of a pool; all of
+// them with a limit of 0
+func (c *Client) ScrubHistory(ctx context.Context, name string, limit int) ([]pool.ScrubRecord, error) {
+	var query url.Values
+	if limit > 0 {
+		query = url.Values{"limit": {strconv.Itoa(limit)}}
+	}
+	var out struct {
+		History []pool.ScrubRecord `json:"history"`
+	}
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   path("pools", name, "scrub", "history"),
+		query:  query,
+		result: &out,
+	})
+	return out.History, err
+}
+
+func (c *Client) Resilver(ctx context.Context, name string) error {
+	return c.do(ctx, request{method: http.MethodPost, path: path("pools", name, "resilver")})
+}
+
+func (c *Client) Initialize(ctx context.Context, name string, cfg pool.InitializeConfig) error {
+	return c.do(ctx, request{method: http.MethodPost, path: path("pools", name, "initialize"), body: cfg})
+}
+
+func (c *Client) Trim(ctx context.Context, name string, cfg pool.TrimConfig) error {
+	return c.do(ctx, request{method: http.MethodPost, path: path("pools", name, "trim"), body: cfg})
+}
+
+func (c *Client) Checkpoint(ctx context.Context, name string) error {
+	return c.do(ctx, request{method: http.MethodPost, path: path("pools", name, "checkpoint")})
+}
+
+func (c *Client) CheckpointInfo(ctx context.Context, name string) (*pool.CheckpointInfo, error) {
+	var out pool.CheckpointInfo
+	if err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   path("pools", name, "checkpoint"),
+		result: &out,
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DiscardCheckpoint(ctx context.Context, name string, wait bool) error {
+	return c.do(ctx, request{
+		method: http.MethodDelete,
+		path:   path("pools", name, "checkpoint"),
+		query:  boolQuery("wait", wait),
+	})
+}
+
+// StartMaintenance checkpoints a pool and applies a change that can be
+// confirmed or rolled back
+func (c *Client) StartMaintenance(
+	ctx context.Context,
+	name string,
+	change pool.MaintenanceChange,
+) (*pool.MaintenanceSession, error) {
+	return c.maintenance(ctx, request{
+		method: http.MethodPost,
+		path:   path("pools", name, "maintenance"),
+		body:   change,
+	})
+}
+
+func (c *Client) ListMaintenance(ctx context.Context, name string) ([]pool.MaintenanceSession, error) {
+	var out struct {
+		Sessions []pool.MaintenanceSession `json:"sessions"`
+	}
+	err := c.do(ctx, request{method: http.MethodGet, path: path("pools", name, "maintenance"), result: &out})
+	return out.Sessions, err
+}
+
+func (c *Client) GetMaintenance(ctx context.Context, name, id string) (*pool.MaintenanceSession, error) {
+	return c.maintenance(ctx, request{method: http.MethodGet, path: path("pools", name, "maintenance", id)})
+}
+
+func (c *Client) ConfirmMaintenance(ctx context.Context, name, id string) (*pool.MaintenanceSession, error) {
+	return c.maintenance(ctx, request{
+		method: http.MethodPost,
+		path:   path("pools", name, "maintenance", id, "confirm"),
+	})
+}
+
+func (c *Client) RollbackMaintenance(
+	ctx context.Context,
+	name, id string,
+	force bool,
+) (*pool.MaintenanceSession, error) {
+	return c.maintenance(ctx, request{
+		method: http.MethodPost,
+		path:   path("pools", name, "maintenance", id, "rollback"),
+		query:  boolQuery("force", force),
+	})
+}
+
+// AbandonMaintenance discards the checkpoint of a session without confirming it
+func (c *Client) AbandonMaintenance(ctx context.Context, name, id string) (*pool.MaintenanceSession, error) {
+	return c.maintenance(ctx, request{
+		method: http.MethodPost,
+		path:   path("pools", name, "maintenance", id, "abandon"),
+	})
+}
+
+func (c *Client) maintenance(ctx context.Context, r request) (*pool.MaintenanceSession, error) {
+	var out pool.MaintenanceSession
+	r.result = &out
+	if err := c.do(ctx, r); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) AttachDevice(ctx context.Context, name, device, newDevice string) error {
+	return c.do(ctx, request{
+		method: http.MethodPost,
+		path:   path("pools", name, "devices", "attach"),
+		body:   map[string]interface{}{"device": device, "new_device": newDevice},
+	})
+}
+
+func (c *Client) DetachDevice(ctx context.Context, name, device string) error {
+	return c.do(ctx, request{
+		method: http.MethodPost,
+		path:   path("pools", name, "devices", "detach"),
+		body:   map[string]interface{}{"device": device},
+	})
+}
+
+func (c *Client) ReplaceDevice(ctx context.Context, name, oldDevice, newDevice string) error {
+	return c.do(ctx, request{
+		method: http.MethodPost,
+		path:   path("pools", name, "devices", "replace"),
+		body:   map[string]interface{}{"old_device": oldDevice, "new_device": newDevice},
+	})
+}
+
+func (c *Client) OnlineDevice(ctx context.Context, name string, devices []string, expand bool) error {
+	return c.do(ctx, request{
+		method: http.MethodPost,
+		path:   path("pools", name, "devices", "online"),
+		body:   map[string]interface{}{"devices": devices, "expand": expand},
+	})
+}
+
+func (c *Client) OfflineDevice(ctx context.Context, name, device string, temporary, force bool) error {
+	return c.do(ctx, request{
+		method: http.MethodPost,
+		path:   path("pools", name, "devices", "offline"),
+		body:   map[string]interface{}{"device": device, "temporary": temporary, "force": force},
+	})
+}
+
+// ClearErrors clears the errors of a device, or of all devices of the pool
+// when device is empty
+func (c *Client) ClearErrors(ctx context.Context, name, device string) error {
+	return c.do(ctx, request{
+		method: http.MethodPost,
+		path:   path("pools", name, "devices", "clear"),
+		body:   map[string]interface{}{"device": device},
+	})
+}
+
+func (c *Client) Reopen(ctx context.Context, name string, noRestart bool) error {
+	return c.do(ctx, request{
+		method: http.MethodPost,
+		path:   path("pools", name, "reopen"),
+		query:  boolQuery("no_restart", noRestart),
+	})
+}
+
+// SpareActions lists the hot spare replacements the spare monitor made
+func (c *Client) SpareActions(ctx context.Context, name string) ([]pool.SpareAction, error) {
+	var out struct {
+		Actions []pool.SpareAction `json:"actions"`
+	}
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   path("pools", name, "spares", "actions"),
+		result: &out,
+	})
+	return out.Actions, err
+}
+
+// AddVDevs adds vdevs to the pool cfg.Name. With cfg.DryRun it returns
+// the resulting layout instead; otherwise the preview is nil.
+func (c *Client) AddVDevs(ctx context.Context, cfg pool.AddConfig) (*pool.LayoutPreview, error) {
+	return c.layout(ctx, request{
+		method: http.MethodPost,
+		path:   path("pools", cfg.Name, "vdevs"),
+		body:   cfg,
+	}, cfg.DryRun)
+}
+
+// RemoveDevice starts evacuating top-level vdevs of the pool cfg.Name.
+// With cfg.DryRun it returns the memory the removal will use instead;
+// otherwise the preview is nil.
+func (c *Client) RemoveDevice(ctx context.Context, cfg pool.RemoveConfig) (*pool.LayoutPreview, error) {
+	return c.layout(ctx, request{
+		method: http.MethodPost,
+		path:   path("pools", cfg.Name, "vdevs", "remove"),
+		body:   cfg,
+	}, cfg.DryRun)
+}
+
+func (c *Client) CancelRemoval(ctx context.Context, name string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: path("pools", name, "vdevs", "remove")})
+}
+
+func (c *Client) layout(ctx context.Context, r request, dryRun bool) (*pool.LayoutPreview, error) {
+	if !dryRun {
+		return nil, c.do(ctx, r)
+	}
+	var out pool.LayoutPreview
+	r.result = &out
+	r.safe = true
+	if err := c.do(ctx, r); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
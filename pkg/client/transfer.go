@@ -0,0 +1,46 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/stratastor/rodent/pkg/zfs/dataset"
+)
+
+// Transfer sends a snapshot to a local or remote dataset. It returns once
+// the receive completes, so bound long transfers with the context rather
+// than a client timeout.
+func (c *Client) Transfer(ctx context.Context, cfg dataset.TransferConfig) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/dataset/transfer/send", body: cfg})
+}
+
+// GetResumeToken returns the token to resume an interrupted receive into
+// a dataset
+func (c *Client) GetResumeToken(ctx context.Context, cfg dataset.NameConfig) (string, error) {
+	var out tokenResponse
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/dataset/transfer/resume-token/fetch",
+		body:   cfg,
+		result: &out,
+		safe:   true,
+	})
+	return out.Result, err
+}
@@ -0,0 +1,485 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package disk
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+// sectorSize is the unit of the size attribute in sysfs
+const sectorSize = 512
+
+// Virtual block devices that are never offered as pool members
+var virtualPrefixes = []string{"ram", "zram", "zd"}
+
+// Inventory discovers disks from sysfs, udev and the mount table
+type Inventory struct {
+	cfg     Config
+	members MemberSource
+}
+
+// NewInventory creates a disk inventory. members may be nil, in which case
+// pool membership is only detected from ZFS labels.
+func NewInventory(members MemberSource, cfg Config) *Inventory {
+	return &Inventory{cfg: cfg, members: members}
+}
+
+// List returns all disks sorted by kernel name
+func (i *Inventory) List(ctx context.Context) ([]Disk, error) {
+	entries, err := os.ReadDir(i.cfg.SysBlockDir)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.DiskInventory)
+	}
+
+	mounts, err := i.readMounts()
+	if err != nil {
+		return nil, errors.Wrap(err, errors.DiskInventory)
+	}
+	byID := i.readLinks("disk/by-id")
+
+	pools := make(map[string]string)
+	if i.members != nil {
+		members, err := i.members.MemberDevices(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for path, pool := range members {
+			pools[i.kernelName(path)] = pool
+		}
+	}
+
+	disks := []Disk{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if isVirtual(name) {
+			continue
+		}
+		disk, ok := i.readDisk(name, mounts, byID, pools)
+		if ok {
+			disks = append(disks, disk)
+		}
+	}
+
+	sort.Slice(disks, func(a, b int) bool { return disks[a].Name < disks[b].Name })
+	return disks, nil
+}
+
+// Get returns a disk by kernel name or device path
+func (i *Inventory) Get(ctx context.Context, name string) (*Disk, error) {
+	disks, err := i.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	kernel := name
+	if strings.HasPrefix(name, "/") {
+		kernel = i.kernelName(name)
+	}
+	for _, d := range disks {
+		if d.Name == kernel {
+			return &d, nil
+		}
+	}
+	return nil, errors.New(errors.DiskNotFound, name)
+}
+
+// CheckAvailable verifies that each device is a known disk or partition
+// that is not in use and not on the root disk. Unless force is set, devices
+// carrying a ZFS label or any other signature, such as a filesystem, LVM or
+// swap, are refused too: zpool create runs with -f and would overwrite them.
+func (i *Inventory) CheckAvailable(ctx context.Context, force bool, paths ...string) error {
+	disks, err := i.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		kernel := i.kernelName(path)
+		if err := checkDevice(disks, kernel, force); err != nil {
+			return err.WithMetadata("device", path)
+		}
+	}
+	return nil
+}
+
+func checkDevice(disks []Disk, kernel string, force bool) *errors.RodentError {
+	for _, d := range disks {
+		if d.Name == kernel {
+			switch {
+			case d.Root:
+				return errors.New(errors.DiskInUse, "disk holds the root filesystem")
+			case d.InUse:
+				return errors.New(errors.DiskInUse, usage(d.Mounts, d.Holders, d.Pool))
+			case force:
+				return nil
+			case d.ZFSLabel:
+				return errors.New(errors.DiskInUse, zfsLabelInUse)
+			case d.FSType != "":
+				return errors.New(errors.DiskInUse, signatureInUse(d.FSType))
+			}
+			for _, p := range d.Partitions {
+				if p.FSType != "" {
+					return errors.New(errors.DiskInUse,
+						fmt.Sprintf("partition %s holds a %s signature", p.Name, p.FSType))
+				}
+			}
+			return nil
+		}
+
+		for _, p := range d.Partitions {
+			if p.Name != kernel {
+				continue
+			}
+			switch {
+			case d.Root:
+				return errors.New(errors.DiskInUse, "partition is on the root disk")
+			case len(p.Mounts) > 0 || len(p.Holders) > 0 || p.Pool != "":
+				return errors.New(errors.DiskInUse, usage(p.Mounts, p.Holders, p.Pool))
+			case force:
+				return nil
+			case p.ZFSLabel:
+				return errors.New(errors.DiskInUse, zfsLabelInUse)
+			case p.FSType != "":
+				return errors.New(errors.DiskInUse, signatureInUse(p.FSType))
+			}
+			return nil
+		}
+	}
+	return errors.New(errors.DiskNotFound, kernel)
+}
+
+const zfsLabelInUse = "carries a ZFS label, possibly of an exported or destroyed pool; force to overwrite"
+
+func signatureInUse(fsType string) string {
+	return fmt.Sprintf("holds a %s signature; force to overwrite", fsType)
+}
+
+// usage describes why a disk or partition is in use
+func usage(mounts, holders []string, pool string) string {
+	for _, m := range mounts {
+		if m == swapMount && pool == "" {
+			return "active swap"
+		}
+	}
+	switch {
+	case pool != "":
+		return fmt.Sprintf("member of pool %s", pool)
+	case len(mounts) > 0:
+		return fmt.Sprintf("mounted on %s", strings.Join(mounts, ", "))
+	case len(holders) > 0:
+		return fmt.Sprintf("held by %s", strings.Join(holders, ", "))
+	}
+	return "in use"
+}
+
+// readDisk reads a disk and its partitions from sysfs. Devices without
+// media, such as empty card readers or unattached loop devices, are skipped.
+func (i *Inventory) readDisk(
+	name string,
+	mounts map[string][]string,
+	byID map[string][]string,
+	pools map[string]string,
+) (Disk, bool) {
+	dir := filepath.Join(i.cfg.SysBlockDir, name)
+
+	sectors, _ := readUint(filepath.Join(dir, "size"))
+	if sectors == 0 {
+		return Disk{}, false
+	}
+
+	udev := i.readUdev(dir)
+	disk := Disk{
+		Name:       name,
+		Path:       "/dev/" + name,
+		ByID:       byID[name],
+		Size:       sectors * sectorSize,
+		Rotational: readString(filepath.Join(dir, "queue", "rotational")) == "1",
+		Removable:  readString(filepath.Join(dir, "removable")) == "1",
+		Vendor:     readString(filepath.Join(dir, "device", "vendor")),
+		Model:      readString(filepath.Join(dir, "device", "model")),
+		Serial:     readString(filepath.Join(dir, "device", "serial")),
+		WWN:        udev["ID_WWN"],
+		Holders:    readNames(filepath.Join(dir, "holders")),
+		Pool:       pools[name],
+		ZFSLabel:   udev["ID_FS_TYPE"] == "zfs_member",
+		FSType:     udev["ID_FS_TYPE"],
+	}
+	if disk.Model == "" {
+		disk.Model = udev["ID_MODEL"]
+	}
+	if disk.Serial == "" {
+		disk.Serial = udev["ID_SERIAL_SHORT"]
+	}
+	if disk.WWN == "" {
+		disk.WWN = readString(filepath.Join(dir, "wwid"))
+	}
+	disk.Mounts = i.stackMounts(name, disk.Holders, mounts)
+
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		pdir := filepath.Join(dir, entry.Name())
+		if _, err := os.Stat(filepath.Join(pdir, "partition")); err != nil {
+			continue
+		}
+		psectors, _ := readUint(filepath.Join(pdir, "size"))
+		pudev := i.readUdev(pdir)
+		part := Partition{
+			Name:     entry.Name(),
+			Path:     "/dev/" + entry.Name(),
+			ByID:     byID[entry.Name()],
+			Size:     psectors * sectorSize,
+			Holders:  readNames(filepath.Join(pdir, "holders")),
+			Pool:     pools[entry.Name()],
+			ZFSLabel: pudev["ID_FS_TYPE"] == "zfs_member",
+			FSType:   pudev["ID_FS_TYPE"],
+		}
+		part.Mounts = i.stackMounts(part.Name, part.Holders, mounts)
+
+		disk.Mounts = append(disk.Mounts, part.Mounts...)
+		if disk.Pool == "" {
+			disk.Pool = part.Pool
+		}
+		disk.ZFSLabel = disk.ZFSLabel || part.ZFSLabel
+		disk.InUse = disk.InUse || len(part.Holders) > 0
+		disk.Partitions = append(disk.Partitions, part)
+	}
+
+	for _, m := range disk.Mounts {
+		if m == "/" {
+			disk.Root = true
+		}
+	}
+	disk.InUse = disk.InUse || len(disk.Mounts) > 0 || len(disk.Holders) > 0 || disk.Pool != ""
+
+	return disk, true
+}
+
+// stackMounts returns the mount points of a device and of the devices
+// stacked on it, e.g. an LVM volume on a partition
+func (i *Inventory) stackMounts(name string, holders []string, mounts map[string][]string) []string {
+	result := append([]string(nil), mounts[name]...)
+	seen := map[string]bool{name: true}
+
+	queue := append([]string(nil), holders...)
+	for len(queue) > 0 {
+		holder := queue[0]
+		queue = queue[1:]
+		if seen[holder] {
+			continue
+		}
+		seen[holder] = true
+		result = append(result, mounts[holder]...)
+		queue = append(queue, readNames(filepath.Join(i.cfg.SysBlockDir, holder, "holders"))...)
+	}
+	return result
+}
+
+// readMounts maps kernel device names to their mount points. Active swap
+// devices are listed as mounted on [SWAP], as lsblk shows them.
+func (i *Inventory) readMounts() (map[string][]string, error) {
+	f, err := os.Open(i.cfg.MountsFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mounts := make(map[string][]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.HasPrefix(fields[0], "/dev/") {
+			continue
+		}
+		name := i.kernelName(unescapeMount(fields[0]))
+		mounts[name] = append(mounts[name], unescapeMount(fields[1]))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	swaps, err := i.readSwaps()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range swaps {
+		mounts[name] = append(mounts[name], swapMount)
+	}
+	return mounts, nil
+}
+
+// swapMount marks active swap devices in mount lists
+const swapMount = "[SWAP]"
+
+// readSwaps returns the kernel names of the active swap devices. Swap
+// files are skipped, they don't occupy a device of their own.
+func (i *Inventory) readSwaps() ([]string, error) {
+	if i.cfg.SwapsFile == "" {
+		return nil, nil
+	}
+	f, err := os.Open(i.cfg.SwapsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Filename Type Size Used Priority
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[1] != "partition" || !strings.HasPrefix(fields[0], "/dev/") {
+			continue
+		}
+		names = append(names, i.kernelName(unescapeMount(fields[0])))
+	}
+	return names, scanner.Err()
+}
+
+// readLinks maps kernel device names to the links pointing at them in a
+// /dev/disk directory
+func (i *Inventory) readLinks(sub string) map[string][]string {
+	return readLinks(i.cfg.DevDir, sub)
+}
+
+func readLinks(devDir, sub string) map[string][]string {
+	links := make(map[string][]string)
+	dir := filepath.Join(devDir, sub)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return links
+	}
+	for _, entry := range entries {
+		target, err := filepath.EvalSymlinks(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		name := filepath.Base(target)
+		links[name] = append(links[name], filepath.Join("/dev", sub, entry.Name()))
+	}
+	for _, l := range links {
+		sort.Strings(l)
+	}
+	return links
+}
+
+// readUdev reads the udev properties (E: lines) of a block device
+func (i *Inventory) readUdev(sysDir string) map[string]string {
+	props := make(map[string]string)
+	dev := readString(filepath.Join(sysDir, "dev"))
+	if dev == "" {
+		return props
+	}
+	f, err := os.Open(filepath.Join(i.cfg.UdevDataDir, "b"+dev))
+	if err != nil {
+		return props
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, ok := strings.CutPrefix(scanner.Text(), "E:")
+		if !ok {
+			continue
+		}
+		if k, v, ok := strings.Cut(line, "="); ok {
+			props[k] = v
+		}
+	}
+	return props
+}
+
+// kernelName resolves a device path, including /dev/disk/by-* and
+// /dev/mapper links, to the kernel name of the device
+func (i *Inventory) kernelName(path string) string {
+	return resolveKernelName(i.cfg.DevDir, path)
+}
+
+func resolveKernelName(devDir, path string) string {
+	local := path
+	if rest, ok := strings.CutPrefix(path, "/dev/"); ok {
+		local = filepath.Join(devDir, rest)
+	}
+	if resolved, err := filepath.EvalSymlinks(local); err == nil {
+		local = resolved
+	}
+	return filepath.Base(local)
+}
+
+func isVirtual(name string) bool {
+	for _, prefix := range virtualPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// unescapeMount decodes the octal escapes (\040 for space) of /proc/mounts
+func unescapeMount(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func readString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func readUint(path string) (uint64, error) {
+	return strconv.ParseUint(readString(path), 10, 64)
+}
+
+func readNames(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
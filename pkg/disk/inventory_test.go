@@ -0,0 +1,221 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package disk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+type fakeMembers map[string]string
+
+func (f fakeMembers) MemberDevices(context.Context) (map[string]string, error) {
+	return f, nil
+}
+
+// fakeTree builds a sysfs, /dev, mounts and udev tree:
+//
+//	sda   root disk, sda1 on /boot, sda2 held by dm-0 mounted on /
+//	sdb   sdb1 is a member of pool tank
+//	sdc   unused, carries a stale ZFS label and a by-id link
+//	sdd   no media
+//	sde   unmounted ext4 filesystem
+//	sdf   sdf1 is active swap
+//	sdg   blank
+//	zd0   zvol
+func fakeTree(t *testing.T) Config {
+	t.Helper()
+	root := t.TempDir()
+	cfg := Config{
+		SysBlockDir: filepath.Join(root, "sys", "block"),
+		DevDir:      filepath.Join(root, "dev"),
+		MountsFile:  filepath.Join(root, "proc", "mounts"),
+		SwapsFile:   filepath.Join(root, "proc", "swaps"),
+		UdevDataDir: filepath.Join(root, "run", "udev", "data"),
+	}
+
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	symlink := func(target, link string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(link), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(target, link); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sys := func(parts ...string) string {
+		return filepath.Join(append([]string{cfg.SysBlockDir}, parts...)...)
+	}
+
+	write(sys("sda", "size"), "1000000")
+	write(sys("sda", "dev"), "8:0")
+	write(sys("sda", "queue", "rotational"), "0")
+	write(sys("sda", "device", "model"), "Boot SSD")
+	write(sys("sda", "sda1", "partition"), "1")
+	write(sys("sda", "sda1", "size"), "2048")
+	write(sys("sda", "sda2", "partition"), "2")
+	write(sys("sda", "sda2", "size"), "900000")
+	write(sys("sda", "sda2", "holders", "dm-0"), "")
+	write(sys("dm-0", "size"), "900000")
+
+	write(sys("sdb", "size"), "4000000")
+	write(sys("sdb", "queue", "rotational"), "1")
+	write(sys("sdb", "device", "serial"), "SERIALB")
+	write(sys("sdb", "sdb1", "partition"), "1")
+	write(sys("sdb", "sdb1", "size"), "3990000")
+
+	write(sys("sdc", "size"), "4000000")
+	write(sys("sdc", "dev"), "8:32")
+	write(sys("sdc", "queue", "rotational"), "1")
+	write(filepath.Join(cfg.UdevDataDir, "b8:32"),
+		"S:disk/by-id/ata-DISK_C\nE:ID_FS_TYPE=zfs_member\nE:ID_SERIAL_SHORT=SERIALC")
+
+	write(sys("sdd", "size"), "0")
+
+	write(sys("sde", "size"), "4000000")
+	write(sys("sde", "dev"), "8:64")
+	write(filepath.Join(cfg.UdevDataDir, "b8:64"), "E:ID_FS_TYPE=ext4")
+
+	write(sys("sdf", "size"), "4000000")
+	write(sys("sdf", "sdf1", "partition"), "1")
+	write(sys("sdf", "sdf1", "size"), "2000000")
+	write(sys("sdf", "sdf1", "dev"), "8:81")
+	write(filepath.Join(cfg.UdevDataDir, "b8:81"), "E:ID_FS_TYPE=swap")
+
+	write(sys("sdg", "size"), "4000000")
+	write(sys("zd0", "size"), "2048")
+
+	for _, name := range []string{"sda", "sda1", "sda2", "dm-0", "sdb", "sdb1", "sdc", "sde", "sdf", "sdf1", "sdg"} {
+		write(filepath.Join(cfg.DevDir, name), "")
+	}
+	symlink("../dm-0", filepath.Join(cfg.DevDir, "mapper", "vg-root"))
+	symlink("../../sdc", filepath.Join(cfg.DevDir, "disk", "by-id", "ata-DISK_C"))
+
+	write(cfg.MountsFile, "/dev/mapper/vg-root / ext4 rw 0 0\n"+
+		"/dev/sda1 /boot ext4 rw 0 0\n"+
+		"tmpfs /tmp tmpfs rw 0 0\n"+
+		`/dev/sdz1 /mnt/usb\040disk vfat rw 0 0`)
+	write(cfg.SwapsFile, "Filename\tType\tSize\tUsed\tPriority\n"+
+		"/dev/sdf1 partition 1000000 0 -2\n"+
+		"/swapfile file 1000000 0 -3\n")
+
+	return cfg
+}
+
+func TestInventoryList(t *testing.T) {
+	inv := NewInventory(fakeMembers{"/dev/sdb1": "tank"}, fakeTree(t))
+
+	disks, err := inv.List(context.Background())
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	byName := make(map[string]Disk)
+	for _, d := range disks {
+		byName[d.Name] = d
+	}
+	if len(disks) != 7 {
+		t.Fatalf("expected dm-0, sda, sdb, sdc, sde, sdf and sdg, got %v", disks)
+	}
+
+	sda := byName["sda"]
+	if !sda.Root || !sda.InUse || sda.Rotational || sda.Model != "Boot SSD" {
+		t.Errorf("unexpected sda: %+v", sda)
+	}
+	if len(sda.Partitions) != 2 || sda.Size != 1000000*sectorSize {
+		t.Errorf("unexpected sda partitions or size: %+v", sda)
+	}
+
+	sdb := byName["sdb"]
+	if sdb.Pool != "tank" || !sdb.InUse || !sdb.Rotational || sdb.Serial != "SERIALB" {
+		t.Errorf("unexpected sdb: %+v", sdb)
+	}
+
+	sdc := byName["sdc"]
+	if sdc.InUse || sdc.Root || !sdc.ZFSLabel || sdc.Serial != "SERIALC" {
+		t.Errorf("unexpected sdc: %+v", sdc)
+	}
+	if len(sdc.ByID) != 1 || sdc.ByID[0] != "/dev/disk/by-id/ata-DISK_C" {
+		t.Errorf("unexpected sdc links: %v", sdc.ByID)
+	}
+}
+
+func TestInventoryCheckAvailable(t *testing.T) {
+	inv := NewInventory(fakeMembers{"/dev/sdb1": "tank"}, fakeTree(t))
+	ctx := context.Background()
+
+	tests := []struct {
+		device string
+		force  bool
+		code   errors.ErrorCode
+	}{
+		{"/dev/sdg", false, 0},
+		{"/dev/disk/by-id/ata-DISK_C", false, errors.DiskInUse},
+		{"/dev/disk/by-id/ata-DISK_C", true, 0},
+		{"/dev/sdc", true, 0},
+		{"/dev/sde", false, errors.DiskInUse},
+		{"/dev/sde", true, 0},
+		{"/dev/sdf", true, errors.DiskInUse},
+		{"/dev/sdf1", true, errors.DiskInUse},
+		{"/dev/sda", true, errors.DiskInUse},
+		{"/dev/sda1", true, errors.DiskInUse},
+		{"/dev/sdb", true, errors.DiskInUse},
+		{"/dev/sdb1", true, errors.DiskInUse},
+		{"/dev/sdd", false, errors.DiskNotFound},
+		{"/dev/zd0", false, errors.DiskNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s force=%v", tt.device, tt.force), func(t *testing.T) {
+			err := inv.CheckAvailable(ctx, tt.force, tt.device)
+			if tt.code == 0 {
+				if err != nil {
+					t.Fatalf("expected device to be available: %v", err)
+				}
+				return
+			}
+			re, ok := err.(*errors.RodentError)
+			if !ok || re.Code != tt.code {
+				t.Fatalf("expected error code %d, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestUnescapeMount(t *testing.T) {
+	if got := unescapeMount(`/mnt/usb\040disk`); got != "/mnt/usb disk" {
+		t.Errorf("unexpected unescape result %q", got)
+	}
+	if got := unescapeMount(`/mnt/trailing\04`); got != `/mnt/trailing\04` {
+		t.Errorf("unexpected unescape result %q", got)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package disk
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Device naming schemes for paths handed to zpool
+const (
+	NamingByID   = "by-id"
+	NamingByPath = "by-path"
+	// NamingNone passes device paths through unchanged
+	NamingNone = "none"
+)
+
+// Opaque identifiers that udev also publishes under by-id. They are only
+// used when a disk has no link carrying its model and serial.
+var opaqueIDPrefixes = []string{"wwn-", "nvme-eui.", "nvme-uuid.", "dm-uuid-", "lvm-pv-uuid-"}
+
+// Naming maps kernel device names such as /dev/sdb, which can change across
+// reboots, to their stable /dev/disk/by-id or by-path links
+type Naming struct {
+	devDir string
+	scheme string
+}
+
+// NewNaming creates a device naming for one of the Naming* schemes
+func NewNaming(cfg Config, scheme string) (*Naming, error) {
+	switch scheme {
+	case NamingByID, NamingByPath, NamingNone:
+	case "":
+		scheme = NamingNone
+	default:
+		return nil, fmt.Errorf("unknown device naming scheme %q", scheme)
+	}
+	return &Naming{devDir: cfg.DevDir, scheme: scheme}, nil
+}
+
+// StablePath returns the stable link of a device, or the path itself when
+// naming is disabled, the path already is a stable link or none exists
+func (n *Naming) StablePath(path string) string {
+	if n.scheme == NamingNone || !strings.HasPrefix(path, "/dev/") ||
+		strings.HasPrefix(path, "/dev/disk/") {
+		return path
+	}
+
+	links := readLinks(n.devDir, filepath.Join("disk", n.scheme))[n.KernelName(path)]
+	if len(links) == 0 {
+		return path
+	}
+	for _, link := range links {
+		if !isOpaqueID(filepath.Base(link)) {
+			return link
+		}
+	}
+	return links[0]
+}
+
+// KernelName returns the current kernel name of a device, e.g. sdb
+func (n *Naming) KernelName(path string) string {
+	return resolveKernelName(n.devDir, path)
+}
+
+func isOpaqueID(name string) bool {
+	for _, prefix := range opaqueIDPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package disk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNamingStablePath(t *testing.T) {
+	dev := t.TempDir()
+	for _, name := range []string{"sdb", "sdb1", "sdc"} {
+		if err := os.WriteFile(filepath.Join(dev, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := map[string]string{
+		"disk/by-id/wwn-0x5000c500a1b2c3d4":                 "../../sdb",
+		"disk/by-id/ata-WDC_WD40EFRX_WD-WCC4E1234567":       "../../sdb",
+		"disk/by-id/ata-WDC_WD40EFRX_WD-WCC4E1234567-part1": "../../sdb1",
+		"disk/by-id/wwn-0x5000c500deadbeef":                 "../../sdc",
+		"disk/by-path/pci-0000:00:1f.2-ata-2":               "../../sdb",
+	}
+	for link, target := range links {
+		path := filepath.Join(dev, link)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(target, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	byID, err := NewNaming(Config{DevDir: dev}, NamingByID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byPath, err := NewNaming(Config{DevDir: dev}, NamingByPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	none, err := NewNaming(Config{DevDir: dev}, NamingNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		naming *Naming
+		path   string
+		want   string
+	}{
+		{byID, "/dev/sdb", "/dev/disk/by-id/ata-WDC_WD40EFRX_WD-WCC4E1234567"},
+		{byID, "/dev/sdb1", "/dev/disk/by-id/ata-WDC_WD40EFRX_WD-WCC4E1234567-part1"},
+		{byID, "/dev/sdc", "/dev/disk/by-id/wwn-0x5000c500deadbeef"},
+		{byID, "/dev/sdz", "/dev/sdz"},
+		{byID, "/dev/disk/by-path/pci-0000:00:1f.2-ata-2", "/dev/disk/by-path/pci-0000:00:1f.2-ata-2"},
+		{byPath, "/dev/sdb", "/dev/disk/by-path/pci-0000:00:1f.2-ata-2"},
+		{none, "/dev/sdb", "/dev/sdb"},
+	}
+	for _, tt := range tests {
+		if got := tt.naming.StablePath(tt.path); got != tt.want {
+			t.Errorf("%s StablePath(%s) = %s, want %s", tt.naming.scheme, tt.path, got, tt.want)
+		}
+	}
+
+	if got := byID.KernelName("/dev/disk/by-id/wwn-0x5000c500a1b2c3d4"); got != "sdb" {
+		t.Errorf("expected kernel name sdb, got %s", got)
+	}
+
+	if _, err := NewNaming(Config{DevDir: dev}, "by-label"); err == nil {
+		t.Error("expected unknown scheme to be rejected")
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package disk
+
+import "context"
+
+// Disk is a block device found in /sys/block with its partitions, mounts
+// and ZFS usage
+type Disk struct {
+	// Kernel name, e.g. sda or nvme0n1
+	Name       string      `json:"name"`
+	Path       string      `json:"path"`
+	ByID       []string    `json:"by_id,omitempty"`
+	Size       uint64      `json:"size"`
+	Rotational bool        `json:"rotational"`
+	Removable  bool        `json:"removable"`
+	Vendor     string      `json:"vendor,omitempty"`
+	Model      string      `json:"model,omitempty"`
+	Serial     string      `json:"serial,omitempty"`
+	WWN        string      `json:"wwn,omitempty"`
+	Partitions []Partition `json:"partitions,omitempty"`
+	Mounts     []string    `json:"mounts,omitempty"`
+	// Holders are device-mapper or md devices stacked on the disk
+	Holders []string `json:"holders,omitempty"`
+	// Pool is the imported pool using the disk or one of its partitions
+	Pool string `json:"pool,omitempty"`
+	// ZFSLabel is set when the disk or a partition carries a ZFS label,
+	// which may belong to an exported or destroyed pool
+	ZFSLabel bool `json:"zfs_label"`
+	// FSType is the signature found on the whole disk, e.g. ext4,
+	// LVM2_member, swap or zfs_member
+	FSType string `json:"fs_type,omitempty"`
+	// Root is set for the disk holding the root filesystem
+	Root bool `json:"root"`
+	// InUse is set when the disk or a partition is mounted, used as swap,
+	// held by another device or a member of an imported pool
+	InUse bool `json:"in_use"`
+}
+
+// Partition is a partition of a Disk
+type Partition struct {
+	Name     string   `json:"name"`
+	Path     string   `json:"path"`
+	ByID     []string `json:"by_id,omitempty"`
+	Size     uint64   `json:"size"`
+	Mounts   []string `json:"mounts,omitempty"`
+	Holders  []string `json:"holders,omitempty"`
+	Pool     string   `json:"pool,omitempty"`
+	ZFSLabel bool     `json:"zfs_label"`
+	FSType   string   `json:"fs_type,omitempty"`
+}
+
+// MemberSource reports the device paths used by imported pools, keyed by
+// path with the pool name as value. pool.Manager implements it; the
+// interface keeps this package free of ZFS imports.
+type MemberSource interface {
+	MemberDevices(ctx context.Context) (map[string]string, error)
+}
+
+// Config sets the filesystem locations the inventory is read from, so that
+// tests can point it at a fake tree
+type Config struct {
+	SysBlockDir string
+	DevDir      string
+	MountsFile  string
+	SwapsFile   string
+	UdevDataDir string
+}
+
+// DefaultConfig returns the locations used on a live system
+func DefaultConfig() Config {
+	return Config{
+		SysBlockDir: "/sys/block",
+		DevDir:      "/dev",
+		MountsFile:  "/proc/self/mounts",
+		SwapsFile:   "/proc/swaps",
+		UdevDataDir: "/run/udev/data",
+	}
+}
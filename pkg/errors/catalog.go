@@ -0,0 +1,132 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// CatalogEntry documents an error code
+type CatalogEntry struct {
+	Code       ErrorCode `json:"code"`
+	Name       string    `json:"name"`
+	Domain     Domain    `json:"domain"`
+	Message    string    `json:"message"`
+	HTTPStatus int       `json:"http_status"`
+}
+
+// Catalog returns every defined error code, ordered by code
+func Catalog() []CatalogEntry {
+	entries := make([]CatalogEntry, 0, len(errorDefinitions))
+	for code := range errorDefinitions {
+		entries = append(entries, catalogEntry(code))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Code < entries[j].Code })
+	return entries
+}
+
+// Lookup returns the catalog entry of code
+func Lookup(code ErrorCode) (CatalogEntry, bool) {
+	if _, ok := errorDefinitions[code]; !ok {
+		return CatalogEntry{}, false
+	}
+	return catalogEntry(code), true
+}
+
+func catalogEntry(code ErrorCode) CatalogEntry {
+	def := errorDefinitions[code]
+	return CatalogEntry{
+		Code:       code,
+		Name:       codeNames[code],
+		Domain:     def.domain,
+		Message:    def.message,
+		HTTPStatus: def.httpStatus,
+	}
+}
+
+// OpenAPIComponents returns the catalog as the components section of an
+// OpenAPI 3 document: an ErrorCode schema enumerating the codes, with
+// their names in x-enum-varnames, the RodentError schema responses carry,
+// and an Error response for each HTTP status an error maps to.
+func OpenAPIComponents() map[string]interface{} {
+	catalog := Catalog()
+
+	codes := make([]int, 0, len(catalog))
+	names := make([]string, 0, len(catalog))
+	descriptions := make([]string, 0, len(catalog))
+	domainSet := map[Domain]bool{}
+	statusSet := map[int]bool{}
+	for _, e := range catalog {
+		codes = append(codes, int(e.Code))
+		names = append(names, e.Name)
+		descriptions = append(descriptions,
+			fmt.Sprintf("%s (%s, HTTP %d)", e.Message, e.Domain, e.HTTPStatus))
+		domainSet[e.Domain] = true
+		statusSet[e.HTTPStatus] = true
+	}
+	var domains []string
+	for d := range domainSet {
+		domains = append(domains, string(d))
+	}
+	sort.Strings(domains)
+
+	errorRef := map[string]interface{}{"$ref": "#/components/schemas/RodentError"}
+	responses := map[string]interface{}{}
+	for status := range statusSet {
+		responses[fmt.Sprintf("Error%d", status)] = map[string]interface{}{
+			"description": http.StatusText(status),
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": errorRef},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"schemas": map[string]interface{}{
+			"ErrorCode": map[string]interface{}{
+				"type":                "integer",
+				"enum":                codes,
+				"x-enum-varnames":     names,
+				"x-enum-descriptions": descriptions,
+				"description":         "Rodent error code, see GET /api/v1/errors",
+			},
+			"ErrorDomain": map[string]interface{}{
+				"type": "string",
+				"enum": domains,
+			},
+			"RodentError": map[string]interface{}{
+				"type":     "object",
+				"required": []string{"code", "domain", "message", "timestamp"},
+				"properties": map[string]interface{}{
+					"code":    map[string]interface{}{"$ref": "#/components/schemas/ErrorCode"},
+					"domain":  map[string]interface{}{"$ref": "#/components/schemas/ErrorDomain"},
+					"message": map[string]interface{}{"type": "string"},
+					"details": map[string]interface{}{"type": "string"},
+					"metadata": map[string]interface{}{
+						"type":                 "object",
+						"additionalProperties": map[string]interface{}{"type": "string"},
+					},
+					"timestamp": map[string]interface{}{"type": "string", "format": "date-time"},
+				},
+			},
+		},
+		"responses": responses,
+	}
+}
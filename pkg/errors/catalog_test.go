@@ -0,0 +1,131 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package errors
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "regenerate names.go from types.go")
+
+// errorCodeIdents returns the error code constants declared in types.go,
+// in declaration order
+func errorCodeIdents(t *testing.T) []string {
+	t.Helper()
+	f, err := parser.ParseFile(token.NewFileSet(), "types.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var idents []string
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			// Domains are typed constants; codes are not
+			if vs.Type != nil {
+				continue
+			}
+			for _, name := range vs.Names {
+				idents = append(idents, name.Name)
+			}
+		}
+	}
+	return idents
+}
+
+func TestCodeNames(t *testing.T) {
+	idents := errorCodeIdents(t)
+
+	if *update {
+		header, err := os.ReadFile("types.go")
+		if err != nil {
+			t.Fatal(err)
+		}
+		license := header[:bytes.Index(header, []byte("*/"))+2]
+
+		var b bytes.Buffer
+		fmt.Fprintf(&b, "%s\n\n// Code generated by TestCodeNames -update; DO NOT EDIT.\n\n", license)
+		b.WriteString("package errors\n\n// codeNames are the identifiers of the error codes\n")
+		b.WriteString("var codeNames = map[ErrorCode]string{\n")
+		for _, ident := range idents {
+			fmt.Fprintf(&b, "\t%s: %q,\n", ident, ident)
+		}
+		b.WriteString("}\n")
+		src, err := format.Source(b.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile("names.go", src, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	if len(codeNames) != len(idents) {
+		t.Errorf("names.go has %d codes, types.go %d; run go test ./pkg/errors -run TestCodeNames -update",
+			len(codeNames), len(idents))
+	}
+	for code, def := range errorDefinitions {
+		if codeNames[code] == "" {
+			t.Errorf("code %d (%s) has no name; run go test ./pkg/errors -run TestCodeNames -update",
+				code, def.message)
+		}
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	catalog := Catalog()
+	if len(catalog) != len(errorDefinitions) {
+		t.Fatalf("catalog has %d entries, want %d", len(catalog), len(errorDefinitions))
+	}
+	for i := 1; i < len(catalog); i++ {
+		if catalog[i-1].Code >= catalog[i].Code {
+			t.Fatalf("catalog not ordered at %d", catalog[i].Code)
+		}
+	}
+
+	e, ok := Lookup(ZFSNotFound)
+	if !ok || e.Name != "ZFSNotFound" || e.Domain != DomainZFS || e.HTTPStatus != 404 {
+		t.Errorf("Lookup(ZFSNotFound) = %+v, %v", e, ok)
+	}
+	if _, ok := Lookup(9999); ok {
+		t.Error("Lookup(9999) found an entry")
+	}
+
+	components := OpenAPIComponents()
+	schemas := components["schemas"].(map[string]interface{})
+	codes := schemas["ErrorCode"].(map[string]interface{})["enum"].([]int)
+	if len(codes) != len(catalog) {
+		t.Errorf("ErrorCode enum has %d codes, want %d", len(codes), len(catalog))
+	}
+	if _, ok := components["responses"].(map[string]interface{})["Error404"]; !ok {
+		t.Error("no Error404 response")
+	}
+}
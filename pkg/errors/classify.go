@@ -0,0 +1,109 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package errors
+
+import (
+	"regexp"
+	"strings"
+)
+
+// StderrClass is the kind of failure a zfs/zpool error message reports
+type StderrClass string
+
+const (
+	ClassNotFound         StderrClass = "not_found"
+	ClassAlreadyExists    StderrClass = "already_exists"
+	ClassBusy             StderrClass = "busy"
+	ClassNoSpace          StderrClass = "no_space"
+	ClassPermissionDenied StderrClass = "permission_denied"
+	ClassInvalidProperty  StderrClass = "invalid_property"
+)
+
+type stderrRule struct {
+	class   StderrClass
+	code    ErrorCode
+	pattern *regexp.Regexp
+	hint    string
+}
+
+// stderrRules match the messages libzfs prints, e.g.
+//
+//	cannot open 'tank/missing': dataset does not exist
+//
+// The first matching rule wins.
+var stderrRules = []stderrRule{
+	{
+		ClassPermissionDenied,
+		ZFSPermissionDenied,
+		regexp.MustCompile(`permission denied|operation not permitted|insufficient privileges`),
+		"Rodent lacks the privileges for this operation; check the sudo rules for the zfs and zpool binaries or the zfs allow delegations",
+	},
+	{
+		ClassNoSpace,
+		ZFSNoSpace,
+		regexp.MustCompile(`out of space|no space left|quota exceeded|greater than available space|exceeds (the )?quota`),
+		"Free space by destroying unneeded snapshots or data, or raise the quota or reservation",
+	},
+	{
+		ClassBusy,
+		ZFSBusy,
+		regexp.MustCompile(`is busy|resource busy|in use|currently (scrubbing|resilvering)|already in progress`),
+		"Stop what is using the dataset, pool or device (mounts, shares, holds or a running scan) and retry, or force the operation where supported",
+	},
+	{
+		ClassAlreadyExists,
+		ZFSAlreadyExists,
+		regexp.MustCompile(`already exists|bookmark exists|destination '[^']*' exists`),
+		"Choose another name, or rename or destroy the existing one first",
+	},
+	{
+		ClassNotFound,
+		ZFSNotFound,
+		regexp.MustCompile(`does not exist|no such (pool|dataset|device|file or directory)|could not find any snapshots`),
+		"Check the name; the dataset, snapshot, pool or device may have been renamed or destroyed",
+	},
+	{
+		ClassInvalidProperty,
+		ZFSInvalidProperty,
+		regexp.MustCompile(`invalid property|bad property|bad numeric value|must be one of|is readonly|read-only property|property cannot be`),
+		"Check the property name and value; zfs get all and zpool get all list the valid properties, and read-only ones can't be set",
+	},
+}
+
+// ClassifyStderr maps the error output of zfs/zpool to the failure it
+// reports. ok is false for output that doesn't match a known class.
+func ClassifyStderr(stderr string) (class StderrClass, code ErrorCode, hint string, ok bool) {
+	msg := strings.ToLower(stderr)
+	for _, r := range stderrRules {
+		if r.pattern.MatchString(msg) {
+			return r.class, r.code, r.hint, true
+		}
+	}
+	return "", 0, "", false
+}
+
+// isClassified reports whether code was assigned by ClassifyStderr. Wrap
+// keeps such codes as they say more than the operation that failed.
+func isClassified(code ErrorCode) bool {
+	for _, r := range stderrRules {
+		if r.code == code {
+			return true
+		}
+	}
+	return false
+}
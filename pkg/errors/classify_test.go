@@ -0,0 +1,119 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package errors
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestClassifyStderr(t *testing.T) {
+	tests := []struct {
+		stderr string
+		class  StderrClass
+		code   ErrorCode
+		status int
+	}{
+		{"cannot open 'tank/missing': dataset does not exist", ClassNotFound, ZFSNotFound, http.StatusNotFound},
+		{"cannot open 'nopool': no such pool", ClassNotFound, ZFSNotFound, http.StatusNotFound},
+		{"cannot destroy snapshots in tank/a@x: could not find any snapshots to destroy; check snapshot names.", ClassNotFound, ZFSNotFound, http.StatusNotFound},
+		{"cannot offline /dev/sdz: no such device in pool", ClassNotFound, ZFSNotFound, http.StatusNotFound},
+		{"cannot create 'tank/a': dataset already exists", ClassAlreadyExists, ZFSAlreadyExists, http.StatusConflict},
+		{"cannot create 'tank': pool already exists", ClassAlreadyExists, ZFSAlreadyExists, http.StatusConflict},
+		{"cannot create bookmark 'tank/a#b': bookmark exists", ClassAlreadyExists, ZFSAlreadyExists, http.StatusConflict},
+		{"cannot receive new filesystem stream: destination 'tank/b' exists\nmust specify -F to overwrite it", ClassAlreadyExists, ZFSAlreadyExists, http.StatusConflict},
+		{"cannot destroy 'tank/a': dataset is busy", ClassBusy, ZFSBusy, http.StatusConflict},
+		{"cannot export 'tank': pool is busy", ClassBusy, ZFSBusy, http.StatusConflict},
+		{"cannot unmount '/tank/a': Device or resource busy", ClassBusy, ZFSBusy, http.StatusConflict},
+		{"cannot scrub tank: currently scrubbing; use 'zpool scrub -s' to cancel current scrub", ClassBusy, ZFSBusy, http.StatusConflict},
+		{"/dev/sdb is in use and contains a unknown filesystem.", ClassBusy, ZFSBusy, http.StatusConflict},
+		{"cannot create 'tank/vol': out of space", ClassNoSpace, ZFSNoSpace, http.StatusInsufficientStorage},
+		{"cannot set property for 'tank/vol': size is greater than available space", ClassNoSpace, ZFSNoSpace, http.StatusInsufficientStorage},
+		{"cannot create snapshot 'tank/a@s': out of space", ClassNoSpace, ZFSNoSpace, http.StatusInsufficientStorage},
+		{"cannot create 'tank/a': permission denied", ClassPermissionDenied, ZFSPermissionDenied, http.StatusForbidden},
+		{"cannot destroy 'tank/a': Operation not permitted", ClassPermissionDenied, ZFSPermissionDenied, http.StatusForbidden},
+		{"cannot set property for 'tank/a': invalid property 'compresion'", ClassInvalidProperty, ZFSInvalidProperty, http.StatusBadRequest},
+		{"cannot set property for 'tank/a': 'compression' must be one of 'on | off | lz4 | zstd'", ClassInvalidProperty, ZFSInvalidProperty, http.StatusBadRequest},
+		{"cannot set property for 'tank/a': 'used' is readonly", ClassInvalidProperty, ZFSInvalidProperty, http.StatusBadRequest},
+		{"bad numeric value '10X'", ClassInvalidProperty, ZFSInvalidProperty, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		class, code, hint, ok := ClassifyStderr(tt.stderr)
+		if !ok || class != tt.class || code != tt.code || hint == "" {
+			t.Errorf("ClassifyStderr(%q) = %q, %d, %v; want %q, %d", tt.stderr, class, code, ok, tt.class, tt.code)
+			continue
+		}
+		if status := New(code, "").HTTPStatus; status != tt.status {
+			t.Errorf("%q: status %d, want %d", tt.stderr, status, tt.status)
+		}
+	}
+
+	for _, stderr := range []string{"", "internal error: Invalid argument", "usage:\n\tcreate [-p] <filesystem>"} {
+		if class, _, _, ok := ClassifyStderr(stderr); ok {
+			t.Errorf("ClassifyStderr(%q) = %q, want no class", stderr, class)
+		}
+	}
+}
+
+func TestNewCommandErrorClassified(t *testing.T) {
+	tests := []struct {
+		name     string
+		exitCode int
+		stderr   string
+		code     ErrorCode
+		class    string
+	}{
+		{"classified", 1, "cannot open 'tank/a': dataset does not exist", ZFSNotFound, "not_found"},
+		{"unclassified", 1, "internal error: Invalid argument", CommandExecution, ""},
+		{"not_started", -1, "failed to start command: fork/exec /usr/sbin/zfs: no such file or directory", CommandExecution, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewCommandError("zfs list", tt.exitCode, tt.stderr)
+			if err.Code != tt.code || err.Metadata["class"] != tt.class {
+				t.Errorf("got [%d] class %q, want [%d] class %q", err.Code, err.Metadata["class"], tt.code, tt.class)
+			}
+			if (err.Metadata["hint"] != "") != (tt.class != "") {
+				t.Errorf("hint %q for class %q", err.Metadata["hint"], tt.class)
+			}
+		})
+	}
+}
+
+func TestWrapKeepsClassified(t *testing.T) {
+	cmdErr := NewCommandError("zfs destroy", 1, "cannot destroy 'tank/a': dataset is busy")
+
+	err := Wrap(cmdErr, ZFSDatasetDestroy)
+	if err.Code != ZFSBusy || err.HTTPStatus != http.StatusConflict {
+		t.Errorf("got [%d] %d, want [%d] %d", err.Code, err.HTTPStatus, ZFSBusy, http.StatusConflict)
+	}
+	if err.Metadata["operation"] == "" || err.Metadata["stderr"] == "" || err.Metadata["hint"] == "" {
+		t.Errorf("metadata not kept: %v", err.Metadata)
+	}
+	if _, ok := cmdErr.Metadata["operation"]; ok {
+		t.Error("Wrap modified the wrapped error")
+	}
+
+	// Unclassified failures take the code of the operation
+	err = Wrap(NewCommandError("zfs destroy", 1, "internal error"), ZFSDatasetDestroy)
+	if err.Code != ZFSDatasetDestroy {
+		t.Errorf("got [%d], want [%d]", err.Code, ZFSDatasetDestroy)
+	}
+}
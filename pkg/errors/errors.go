@@ -124,7 +124,23 @@ func Is(err, target error) bool {
 }
 
 // Wrap wraps an existing error with additional context
+//
+// Errors classified from command output keep their code and status: that
+// a dataset doesn't exist says more than that listing it failed. The
+// operation is recorded in the metadata instead.
 func Wrap(err error, code ErrorCode) *RodentError {
+	if re, ok := err.(*RodentError); ok && isClassified(re.Code) && !isClassified(code) {
+		newErr := *re
+		newErr.Metadata = nil
+		for k, v := range re.Metadata {
+			newErr.WithMetadata(k, v)
+		}
+		newErr.WithMetadata("operation_code", fmt.Sprintf("%d", code))
+		if def, ok := errorDefinitions[code]; ok {
+			newErr.WithMetadata("operation", def.message)
+		}
+		return &newErr
+	}
 	if re, ok := err.(*RodentError); ok {
 		// Create new error but preserve metadata
 		newErr := New(code, re.Details)
@@ -168,9 +184,25 @@ type CommandError struct {
 	StdErr   string
 }
 
+// NewCommandError reports a failed command. Failures ClassifyStderr
+// recognizes get its code, with the class and a remediation hint in the
+// metadata; others, and commands that didn't start (exitCode -1), are
+// CommandExecution.
 func NewCommandError(cmd string, exitCode int, stderr string) *RodentError {
-	return New(CommandExecution, "Command execution failed").
+	code := ErrorCode(CommandExecution)
+	class, classCode, hint, classified := ClassifyStderr(stderr)
+	classified = classified && exitCode > 0
+	if classified {
+		code = classCode
+	}
+
+	err := New(code, "Command execution failed").
 		WithMetadata("command", cmd).
 		WithMetadata("exit_code", fmt.Sprintf("%d", exitCode)).
 		WithMetadata("stderr", stderr)
+	if classified {
+		err.WithMetadata("class", string(class)).
+			WithMetadata("hint", hint)
+	}
+	return err
 }
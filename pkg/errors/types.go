@@ -26,6 +26,10 @@ const (
 	DomainCommand   Domain = "CMD"
 	DomainHealth    Domain = "HEALTH"
 	DomainLifecycle Domain = "LIFECYCLE"
+	DomainDisk      Domain = "DISK"
+	DomainShare     Domain = "SHARE"
+	DomainISCSI     Domain = "ISCSI"
+	DomainClient    Domain = "CLIENT"
 )
 
 // ErrorCode represents unique error identifiers
@@ -57,10 +61,14 @@ type RodentError struct {
 // Error code ranges:
 // 1000-1099: Configuration errors
 // 1100-1199: Server errors
+// 1200-1299: API client
 // 1300-1399: Command execution
 // 1400-1499: Health check
 // 1500-1599: Lifecycle management
 // 1600-1699: Rodent errors
+// 1700-1799: Disk inventory
+// 1800-1899: NFS/SMB shares
+// 1900-1999: iSCSI exports
 // 2000-2999: ZFS operations
 // Domain-specific error code ranges:
 const (
@@ -88,6 +96,14 @@ const (
 	ServerResponseError                   // Response generation error
 	ServerContextCancelled                // Context cancelled
 	ServerTLSError                        // TLS configuration error
+	ServerNotFound                        // Requested resource not found
+)
+
+const (
+	// API Client Errors (1200-1299)
+	ClientRequest  = 1200 + iota // Failed to send request
+	ClientResponse               // Unexpected response
+	ClientConfig                 // Invalid client configuration
 )
 
 const (
@@ -176,6 +192,32 @@ const (
 	ZFSPoolDeviceOperation
 	ZFSPoolTooManyDevices
 	ZFSPoolRestrictedDevice
+
+	ZFSProgramNotFound
+	ZFSProgramInvalidArgument
+	ZFSProgramFailed
+	ZFSProgramCustomDenied
+
+	ZFSPoolCheckpoint
+	ZFSPoolMaintenanceNotFound
+	ZFSPoolMaintenanceState
+	ZFSPoolRedundancyMismatch
+	ZFSPoolInitialize
+	ZFSPoolTrim
+	ZFSPoolScrubHistory
+	ZFSPoolHistory
+	ZFSPoolLayout
+	ZFSVolumeResize
+	ZFSVolumeShrink
+	ZFSVolumeClone
+	ZFSPoolFeatureDisabled
+
+	// Failures classified from zfs/zpool stderr, see ClassifyStderr
+	ZFSNotFound
+	ZFSAlreadyExists
+	ZFSBusy
+	ZFSNoSpace
+	ZFSInvalidProperty
 )
 
 const (
@@ -190,6 +232,8 @@ const (
 	CommandContext                    // Context handling error
 	CommandPipe                       // Command pipe error
 	CommandWorkDir                    // Working directory error
+	CommandUnsupported                // Not supported by the installed release
+	CommandQueueFull                  // Too many commands queued
 )
 
 const (
@@ -225,6 +269,30 @@ const (
 	RodentMisc = 1600 + iota // Miscellaneous program error
 )
 
+const (
+	// Disk Errors (1700-1799)
+	DiskInventory = 1700 + iota // Failed to read disk inventory
+	DiskNotFound                // Disk not found
+	DiskInUse                   // Disk is in use or holds the root filesystem
+)
+
+const (
+	// Share Errors (1800-1899)
+	ShareInvalidConfig = 1800 + iota // Invalid share configuration
+	ShareNotFound                    // Share not found
+	ShareOperation                   // Share operation failed
+	ShareExists                      // Share already exists
+)
+
+const (
+	// iSCSI Errors (1900-1999)
+	ISCSIInvalidConfig      = 1900 + iota // Invalid iSCSI export configuration
+	ISCSINotFound                         // iSCSI export not found
+	ISCSIExists                           // iSCSI export already exists
+	ISCSIOperation                        // iSCSI target operation failed
+	ISCSIBackendUnavailable               // iSCSI target backend unavailable
+)
+
 var errorDefinitions = map[ErrorCode]struct {
 	message    string
 	domain     Domain
@@ -317,6 +385,12 @@ var errorDefinitions = map[ErrorCode]struct {
 		DomainServer,
 		http.StatusInternalServerError,
 	},
+	ServerNotFound: {"Resource not found", DomainServer, http.StatusNotFound},
+
+	// API client errors
+	ClientRequest:  {"Failed to send API request", DomainClient, http.StatusBadGateway},
+	ClientResponse: {"Unexpected API response", DomainClient, http.StatusBadGateway},
+	ClientConfig:   {"Invalid API client configuration", DomainClient, http.StatusBadRequest},
 
 	// ZFS errors
 	ZFSCommandFailed: {
@@ -452,6 +526,67 @@ var errorDefinitions = map[ErrorCode]struct {
 	ZFSPoolRestrictedDevice: {"ZFS device not allowed", DomainZFS, http.StatusForbidden},
 	ZFSPoolTooManyDevices:   {"ZFS too many devices", DomainZFS, http.StatusForbidden},
 
+	ZFSProgramNotFound: {"Channel program not found", DomainZFS, http.StatusNotFound},
+	ZFSProgramInvalidArgument: {
+		"Invalid channel program argument",
+		DomainZFS,
+		http.StatusBadRequest,
+	},
+	ZFSProgramFailed: {
+		"Channel program execution failed",
+		DomainZFS,
+		http.StatusBadRequest,
+	},
+	ZFSProgramCustomDenied: {
+		"Custom channel programs are not allowed",
+		DomainZFS,
+		http.StatusForbidden,
+	},
+
+	ZFSPoolCheckpoint: {"Pool checkpoint operation failed", DomainZFS, http.StatusBadRequest},
+	ZFSPoolMaintenanceNotFound: {
+		"Maintenance session not found",
+		DomainZFS,
+		http.StatusNotFound,
+	},
+	ZFSPoolMaintenanceState: {
+		"Invalid maintenance session state",
+		DomainZFS,
+		http.StatusConflict,
+	},
+	ZFSPoolRedundancyMismatch: {
+		"Mismatched vdev redundancy",
+		DomainZFS,
+		http.StatusConflict,
+	},
+	ZFSPoolInitialize: {"Failed to initialize pool devices", DomainZFS, http.StatusBadRequest},
+	ZFSPoolTrim:       {"Failed to trim pool devices", DomainZFS, http.StatusBadRequest},
+	ZFSPoolScrubHistory: {
+		"Failed to access scrub history",
+		DomainZFS,
+		http.StatusInternalServerError,
+	},
+	ZFSPoolHistory: {"Failed to get pool history", DomainZFS, http.StatusBadRequest},
+	ZFSPoolLayout:  {"Invalid pool layout", DomainZFS, http.StatusBadRequest},
+
+	ZFSVolumeResize: {"Failed to resize volume", DomainZFS, http.StatusInternalServerError},
+	ZFSVolumeShrink: {
+		"Volume can't shrink below its referenced data",
+		DomainZFS,
+		http.StatusConflict,
+	},
+	ZFSVolumeClone: {"Failed to clone volume", DomainZFS, http.StatusInternalServerError},
+	ZFSPoolFeatureDisabled: {
+		"Pool feature is not enabled",
+		DomainZFS,
+		http.StatusConflict,
+	},
+	ZFSNotFound:        {"ZFS dataset, pool or device not found", DomainZFS, http.StatusNotFound},
+	ZFSAlreadyExists:   {"ZFS dataset or pool already exists", DomainZFS, http.StatusConflict},
+	ZFSBusy:            {"ZFS dataset, pool or device is busy", DomainZFS, http.StatusConflict},
+	ZFSNoSpace:         {"Out of space or quota", DomainZFS, http.StatusInsufficientStorage},
+	ZFSInvalidProperty: {"Invalid ZFS property or value", DomainZFS, http.StatusBadRequest},
+
 	// Command execution errors
 	CommandNotFound:  {"Command not found", DomainCommand, http.StatusNotFound},
 	CommandExecution: {"Command execution failed", DomainCommand, http.StatusBadRequest},
@@ -479,6 +614,16 @@ var errorDefinitions = map[ErrorCode]struct {
 		http.StatusInternalServerError,
 	},
 	CommandWorkDir: {"Working directory error", DomainCommand, http.StatusInternalServerError},
+	CommandUnsupported: {
+		"Not supported by the installed ZFS release",
+		DomainCommand,
+		http.StatusNotImplemented,
+	},
+	CommandQueueFull: {
+		"Command queue is full",
+		DomainCommand,
+		http.StatusServiceUnavailable,
+	},
 
 	// Health check errors
 	HealthCheckFailed:  {"Health check failed", DomainHealth, http.StatusServiceUnavailable},
@@ -566,4 +711,22 @@ var errorDefinitions = map[ErrorCode]struct {
 
 	// Rodent errors
 	RodentMisc: {"Miscellaneous program error", DomainLifecycle, http.StatusInternalServerError},
+
+	// Disk inventory errors
+	DiskInventory: {"Failed to read disk inventory", DomainDisk, http.StatusInternalServerError},
+	DiskNotFound:  {"Disk not found", DomainDisk, http.StatusNotFound},
+	DiskInUse:     {"Disk is in use", DomainDisk, http.StatusConflict},
+
+	// Share errors
+	ShareInvalidConfig: {"Invalid share configuration", DomainShare, http.StatusBadRequest},
+	ShareNotFound:      {"Share not found", DomainShare, http.StatusNotFound},
+	ShareOperation:     {"Share operation failed", DomainShare, http.StatusInternalServerError},
+	ShareExists:        {"Share already exists", DomainShare, http.StatusConflict},
+
+	// iSCSI errors
+	ISCSIInvalidConfig:      {"Invalid iSCSI export configuration", DomainISCSI, http.StatusBadRequest},
+	ISCSINotFound:           {"iSCSI export not found", DomainISCSI, http.StatusNotFound},
+	ISCSIExists:             {"iSCSI export already exists", DomainISCSI, http.StatusConflict},
+	ISCSIOperation:          {"iSCSI target operation failed", DomainISCSI, http.StatusInternalServerError},
+	ISCSIBackendUnavailable: {"iSCSI target backend unavailable", DomainISCSI, http.StatusServiceUnavailable},
 }
@@ -0,0 +1,256 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package openapi builds OpenAPI 3 documents from route tables and the Go
+// types requests and responses are bound to.
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Version of the OpenAPI specification documents are written in
+const Version = "3.0.3"
+
+// Document is an OpenAPI 3 document
+type Document struct {
+	OpenAPI    string                        `json:"openapi"`
+	Info       Info                          `json:"info"`
+	Paths      map[string]map[string]*PathOp `json:"paths"`
+	Components Components                    `json:"components"`
+	Tags       []Tag                         `json:"tags,omitempty"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// Components holds the schemas referenced by $ref, generated or supplied
+// as raw JSON-marshalable values
+type Components struct {
+	Schemas   map[string]interface{} `json:"schemas"`
+	Responses map[string]interface{} `json:"responses,omitempty"`
+}
+
+// PathOp is an operation of a path, keyed by lower case method
+type PathOp struct {
+	Summary     string               `json:"summary"`
+	Description string               `json:"description,omitempty"`
+	OperationID string               `json:"operationId"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Operation documents a route
+type Operation struct {
+	Summary     string
+	Description string
+	Tag         string
+	// Query parameters
+	Query []Param
+	// Request is a value of the type the body is bound to; nil for
+	// routes without a body
+	Request interface{}
+	// Response is a value of the type of the success response body; nil
+	// for routes that only return a status
+	Response interface{}
+	// Status of a successful response; 200 unless set
+	Status int
+	// Stream is a value of the type of each line of the NDJSON response
+	// served for Accept: application/x-ndjson
+	Stream interface{}
+}
+
+// Param is a query parameter
+type Param struct {
+	Name        string
+	Description string
+	// Type is a JSON schema type; string unless set
+	Type string
+}
+
+// Route is an operation at a method and gin path, e.g. /pools/:name
+type Route struct {
+	Method string
+	Path   string
+	Operation
+}
+
+var pathParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// Path converts a gin path to an OpenAPI path: /pools/:name becomes
+// /pools/{name}
+func Path(ginPath string) string {
+	return pathParam.ReplaceAllString(ginPath, "{$1}")
+}
+
+// Build returns the document of routes. components are merged into the
+// document's components, e.g. shared error schemas; errorSchema is the
+// schema of error responses.
+func Build(info Info, routes []Route, components Components, errorSchema *Schema) *Document {
+	g := NewGenerator()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]map[string]*PathOp{},
+	}
+
+	tags := map[string]bool{}
+	for _, r := range routes {
+		path := Path(r.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*PathOp{}
+		}
+		op := &PathOp{
+			Summary:     r.Summary,
+			Description: r.Description,
+			OperationID: operationID(r.Method, r.Path),
+			Responses:   map[string]*Response{},
+		}
+		if r.Tag != "" {
+			op.Tags = []string{r.Tag}
+			tags[r.Tag] = true
+		}
+
+		for _, m := range pathParam.FindAllStringSubmatch(r.Path, -1) {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:     m[1],
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+		for _, q := range r.Query {
+			typ := q.Type
+			if typ == "" {
+				typ = "string"
+			}
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:        q.Name,
+				In:          "query",
+				Description: q.Description,
+				Schema:      &Schema{Type: typ},
+			})
+		}
+
+		if r.Request != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content: map[string]MediaType{
+					"application/json": {Schema: g.Schema(r.Request)},
+				},
+			}
+		}
+
+		status := r.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		resp := &Response{Description: http.StatusText(status)}
+		if r.Response != nil {
+			resp.Content = map[string]MediaType{
+				"application/json": {Schema: g.Schema(r.Response)},
+			}
+		}
+		if r.Stream != nil {
+			if resp.Content == nil {
+				resp.Content = map[string]MediaType{}
+			}
+			resp.Content["application/x-ndjson"] = MediaType{Schema: g.Schema(r.Stream)}
+		}
+		op.Responses[strconv.Itoa(status)] = resp
+		op.Responses["default"] = &Response{
+			Description: "Error",
+			Content: map[string]MediaType{
+				"application/json": {Schema: errorSchema},
+			},
+		}
+
+		doc.Paths[path][strings.ToLower(r.Method)] = op
+	}
+
+	for name := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: name})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	doc.Components.Schemas = map[string]interface{}{}
+	for name, s := range g.Schemas() {
+		doc.Components.Schemas[name] = s
+	}
+	for name, s := range components.Schemas {
+		doc.Components.Schemas[name] = s
+	}
+	doc.Components.Responses = components.Responses
+	return doc
+}
+
+// operationID derives an id from the method and path, e.g.
+// getPoolsNameStatus for GET /api/v1/pools/:name/status
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == ':' || r == '*' || r == '-' || r == '_' || r == '.'
+	}) {
+		if part == "api" || part == "v1" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
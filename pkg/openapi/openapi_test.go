@@ -0,0 +1,137 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPath(t *testing.T) {
+	tests := map[string]string{
+		"/api/v1/pools": "/api/v1/pools",
+		"/api/v1/pools/:name/properties/:property": "/api/v1/pools/{name}/properties/{property}",
+		"/files/*path": "/files/{path}",
+	}
+	for in, want := range tests {
+		if got := Path(in); got != want {
+			t.Errorf("Path(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+type inner struct {
+	Size int64 `json:"size"`
+}
+
+type embedded struct {
+	Force bool `json:"force,omitempty"`
+}
+
+type sample struct {
+	embedded
+	Name     string            `json:"name" binding:"required"`
+	Props    map[string]string `json:"props"`
+	Inner    *inner            `json:"inner"`
+	Tags     []string          `json:"tags"`
+	Created  time.Time         `json:"created"`
+	Optional *string           `json:"optional"`
+	Skipped  string            `json:"-"`
+	private  string
+}
+
+func TestGeneratorSchema(t *testing.T) {
+	g := NewGenerator()
+	ref := g.Schema(sample{})
+	if ref.Ref != "#/components/schemas/openapi.sample" {
+		t.Fatalf("ref = %q", ref.Ref)
+	}
+
+	s := g.Schemas()["openapi.sample"]
+	if s == nil {
+		t.Fatal("component openapi.sample missing")
+	}
+	var props []string
+	for name := range s.Properties {
+		props = append(props, name)
+	}
+	if len(props) != 7 {
+		t.Errorf("properties = %v, want force, name, props, inner, tags, created, optional", props)
+	}
+	if !reflect.DeepEqual(s.Required, []string{"name"}) {
+		t.Errorf("required = %v", s.Required)
+	}
+	if p := s.Properties["props"]; p.Type != "object" || p.AdditionalProperties.Type != "string" {
+		t.Errorf("map schema = %+v", p)
+	}
+	if p := s.Properties["inner"]; p.Ref != "#/components/schemas/openapi.inner" {
+		t.Errorf("pointer to struct = %+v", p)
+	}
+	if p := s.Properties["optional"]; p.Type != "string" || !p.Nullable {
+		t.Errorf("pointer = %+v", p)
+	}
+	if p := s.Properties["created"]; p.Format != "date-time" {
+		t.Errorf("time = %+v", p)
+	}
+	if p := s.Properties["tags"]; p.Type != "array" || p.Items.Type != "string" {
+		t.Errorf("slice = %+v", p)
+	}
+	if p := g.Schemas()["openapi.inner"].Properties["size"]; p.Type != "integer" || p.Format != "int64" {
+		t.Errorf("int64 = %+v", p)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	doc := Build(Info{Title: "test", Version: "v1"}, []Route{{
+		Method: "POST",
+		Path:   "/api/v1/pools/:name/things",
+		Operation: Operation{
+			Summary:  "Add a thing",
+			Tag:      "pools",
+			Query:    []Param{{Name: "force", Type: "boolean"}},
+			Request:  sample{},
+			Response: Fields{"things": []inner{}},
+			Status:   201,
+		},
+	}}, Components{Schemas: map[string]interface{}{"Error": map[string]string{"type": "object"}}},
+		&Schema{Ref: "#/components/schemas/Error"})
+
+	op := doc.Paths["/api/v1/pools/{name}/things"]["post"]
+	if op == nil {
+		t.Fatal("operation missing")
+	}
+	if op.OperationID != "postPoolsNameThings" {
+		t.Errorf("operationId = %q", op.OperationID)
+	}
+	if len(op.Parameters) != 2 || op.Parameters[0].In != "path" || op.Parameters[1].In != "query" {
+		t.Errorf("parameters = %+v", op.Parameters)
+	}
+	if op.Responses["201"] == nil || op.Responses["default"] == nil {
+		t.Errorf("responses = %v", op.Responses)
+	}
+	for _, name := range []string{"openapi.sample", "openapi.inner", "Error"} {
+		if doc.Components.Schemas[name] == nil {
+			t.Errorf("component %s missing", name)
+		}
+	}
+	if _, err := json.Marshal(doc); err != nil {
+		t.Errorf("marshal: %v", err)
+	}
+}
@@ -18,30 +18,105 @@
 package server
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/stratastor/logger"
 	"github.com/stratastor/rodent/config"
+	"github.com/stratastor/rodent/pkg/disk"
+	"github.com/stratastor/rodent/pkg/share/iscsi"
+	"github.com/stratastor/rodent/pkg/share/nfs"
+	"github.com/stratastor/rodent/pkg/share/smb"
 	"github.com/stratastor/rodent/pkg/zfs/api"
 	"github.com/stratastor/rodent/pkg/zfs/command"
 	"github.com/stratastor/rodent/pkg/zfs/dataset"
 	"github.com/stratastor/rodent/pkg/zfs/pool"
+	"github.com/stratastor/rodent/pkg/zfs/program"
 )
 
-func registerZFSRoutes(engine *gin.Engine) {
+func registerZFSRoutes(ctx context.Context, engine *gin.Engine) error {
 	// Add error handler middleware
 	engine.Use(api.ErrorHandler())
 
 	cfg := config.GetConfig()
+	if err := command.ResolveBinaries(cfg.ZFS.Binaries.ZFS, cfg.ZFS.Binaries.Zpool); err != nil {
+		return err
+	}
 	// Create command executor with sudo support
 	executor := command.NewCommandExecutor(true, logger.Config{LogLevel: cfg.Server.LogLevel})
+	executor.SetQueueConfig(command.QueueConfig{
+		MaxConcurrent: cfg.ZFS.Executor.MaxConcurrent,
+		QueueDepth:    cfg.ZFS.Executor.QueueDepth,
+		Limits: [...]int{
+			command.PriorityMutating:   cfg.ZFS.Executor.Limits.Mutating,
+			command.PriorityRead:       cfg.ZFS.Executor.Limits.Read,
+			command.PriorityBackground: cfg.ZFS.Executor.Limits.Background,
+		},
+	})
+	if err := probeCapabilities(ctx, cfg, executor); err != nil {
+		return err
+	}
 
 	// Initialize managers
 	datasetManager := dataset.NewManager(executor)
 	poolManager := pool.NewManager(executor)
+	diskInventory := disk.NewInventory(poolManager, disk.DefaultConfig())
+	nfsManager := nfs.NewManager(executor, nfs.DefaultExportSource())
+	smbManager := smb.NewManager(executor, smb.ExecRunner{Sudo: true}, smb.Config{
+		IncludeFile:  cfg.Shares.SMB.IncludeFile,
+		MainConfig:   cfg.Shares.SMB.MainConfig,
+		ShadowFormat: cfg.Shares.SMB.ShadowFormat,
+	})
+	iscsiManager := iscsi.NewManager(executor,
+		iscsi.NewTargetCLI(iscsi.ExecRunner{Sudo: true}, cfg.Shares.ISCSI.SaveConfig),
+		iscsi.Config{
+			BaseIQN: cfg.Shares.ISCSI.BaseIQN,
+			Portals: cfg.Shares.ISCSI.Portals,
+		})
+
+	naming, err := disk.NewNaming(disk.DefaultConfig(), cfg.ZFS.DeviceNaming)
+	if err != nil {
+		return err
+	}
+	poolManager.SetDeviceNamer(naming)
+	programManager := program.NewManager(executor, program.Config{
+		AllowCustom:      cfg.ZFS.ChannelPrograms.AllowCustom,
+		AdminToken:       cfg.ZFS.ChannelPrograms.AdminToken,
+		InstructionLimit: cfg.ZFS.ChannelPrograms.InstructionLimit,
+		MemoryLimit:      cfg.ZFS.ChannelPrograms.MemoryLimit,
+	})
+
+	// Replace faulted devices with hot spares in the background
+	if cfg.ZFS.AutoReplace.Enabled {
+		interval, err := time.ParseDuration(cfg.ZFS.AutoReplace.Interval)
+		if err != nil || interval <= 0 {
+			interval = time.Minute
+		}
+		l, err := logger.NewTag(config.NewLoggerConfig(cfg), "spares")
+		if err != nil {
+			return err
+		}
+		poolManager.StartSpareMonitor(ctx, interval, l)
+	}
+
+	if err := startScrubScheduler(ctx, cfg, poolManager); err != nil {
+		return err
+	}
 
 	// Create API handlers
 	datasetHandler := api.NewDatasetHandler(datasetManager)
-	poolHandler := api.NewPoolHandler(poolManager)
+	poolHandler := api.NewPoolHandler(poolManager, diskInventory)
+	diskHandler := api.NewDiskHandler(diskInventory)
+	nfsHandler := api.NewNFSHandler(nfsManager)
+	smbHandler := api.NewSMBHandler(smbManager)
+	iscsiHandler := api.NewISCSIHandler(iscsiManager)
+	programHandler := api.NewProgramHandler(programManager)
+	capabilitiesHandler := api.NewCapabilitiesHandler(executor)
+	executorHandler := api.NewExecutorHandler(executor)
+	errorCatalogHandler := api.NewErrorCatalogHandler()
+	openAPIHandler := api.NewOpenAPIHandler(engine.Routes)
 
 	// API group with version
 	v1 := engine.Group("/api/v1")
@@ -49,8 +124,79 @@ func registerZFSRoutes(engine *gin.Engine) {
 		// Register ZFS routes
 		datasetHandler.RegisterRoutes(v1)
 		poolHandler.RegisterRoutes(v1)
+		programHandler.RegisterRoutes(v1)
+		diskHandler.RegisterRoutes(v1)
+		nfsHandler.RegisterRoutes(v1)
+		smbHandler.RegisterRoutes(v1)
+		iscsiHandler.RegisterRoutes(v1)
+		capabilitiesHandler.RegisterRoutes(v1)
+		executorHandler.RegisterRoutes(v1)
+		errorCatalogHandler.RegisterRoutes(v1)
+		openAPIHandler.RegisterRoutes(v1)
 
 		// Health check routes
 		// v1.GET("/health", healthCheck)
 	}
+
+	return nil
+}
+
+// probeCapabilities detects the installed OpenZFS release, its subcommands
+// and the pool feature flags. Capabilities that can't be probed are assumed
+// to be supported.
+func probeCapabilities(ctx context.Context, cfg *config.Config, executor *command.CommandExecutor) error {
+	l, err := logger.NewTag(config.NewLoggerConfig(cfg), "zfs")
+	if err != nil {
+		return err
+	}
+
+	caps, err := executor.Probe(ctx)
+	if err != nil {
+		l.Warn("Failed to probe ZFS capabilities", "error", err)
+		return nil
+	}
+	l.Info("Probed ZFS capabilities", "version", caps.Version, "kmod", caps.KmodVersion)
+	return nil
+}
+
+// startScrubScheduler opens the scrub history and starts the scheduler that
+// records completed scrubs and runs scheduled ones
+func startScrubScheduler(ctx context.Context, cfg *config.Config, poolManager *pool.Manager) error {
+	l, err := logger.NewTag(config.NewLoggerConfig(cfg), "scrub")
+	if err != nil {
+		return err
+	}
+
+	history, err := pool.NewScrubHistory(cfg.ZFS.Scrub.HistoryPath)
+	if err != nil {
+		l.Warn("Scrub history unavailable", "error", err)
+	} else {
+		poolManager.SetScrubHistory(history)
+	}
+
+	schedCfg := pool.ScrubSchedulerConfig{
+		MaxConcurrent: cfg.ZFS.Scrub.MaxConcurrent,
+	}
+	schedCfg.Interval, err = time.ParseDuration(cfg.ZFS.Scrub.Interval)
+	if err != nil || schedCfg.Interval <= 0 {
+		schedCfg.Interval = 5 * time.Minute
+	}
+	if cfg.ZFS.Scrub.QuietHours != "" {
+		if schedCfg.QuietHours, err = pool.ParseQuietHours(cfg.ZFS.Scrub.QuietHours); err != nil {
+			return err
+		}
+	}
+	for _, s := range cfg.ZFS.Scrub.Schedules {
+		cadence, err := pool.ParseCadence(s.Cadence)
+		if err != nil {
+			return fmt.Errorf("scrub schedule for pool %s: %w", s.Pool, err)
+		}
+		schedCfg.Schedules = append(schedCfg.Schedules, pool.ScrubSchedule{
+			Pool:    s.Pool,
+			Cadence: cadence,
+		})
+	}
+
+	poolManager.StartScrubScheduler(ctx, schedCfg, l)
+	return nil
 }
@@ -83,7 +83,9 @@ func Start(ctx context.Context, port int) error {
 		c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 	})
 
-	registerZFSRoutes(engine)
+	if err := registerZFSRoutes(ctx, engine); err != nil {
+		return err
+	}
 
 	srv = &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.Server.Port),
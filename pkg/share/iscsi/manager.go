@@ -0,0 +1,184 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iscsi
+
+import (
+	"context"
+	"sync"
+
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/zfs/command"
+)
+
+// Manager exports ZFS volumes over iSCSI through a target Backend
+type Manager struct {
+	executor *command.CommandExecutor
+	backend  Backend
+	cfg      Config
+	// mu serializes changes to the target configuration
+	mu sync.Mutex
+
+	// checkVolume fails when a dataset isn't an existing volume
+	checkVolume func(ctx context.Context, volume string) error
+}
+
+func NewManager(executor *command.CommandExecutor, backend Backend, cfg Config) *Manager {
+	defaults := DefaultConfig()
+	if cfg.BaseIQN == "" {
+		cfg.BaseIQN = defaults.BaseIQN
+	}
+	if len(cfg.Portals) == 0 {
+		cfg.Portals = defaults.Portals
+	}
+	m := &Manager{executor: executor, backend: backend, cfg: cfg}
+	m.checkVolume = m.zfsCheckVolume
+	return m
+}
+
+// List returns the iSCSI exports of all volumes. CHAP secrets are left
+// out.
+func (m *Manager) List(ctx context.Context) ([]Export, error) {
+	exports, err := m.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range exports {
+		redact(&exports[i])
+	}
+	return exports, nil
+}
+
+// Get returns the iSCSI export of a volume
+func (m *Manager) Get(ctx context.Context, volume string) (*Export, error) {
+	export, err := m.get(ctx, volume)
+	if err != nil {
+		return nil, err
+	}
+	redact(export)
+	return export, nil
+}
+
+// Set creates the iSCSI export of a volume or updates its portals and
+// ACLs. The LUN of an existing export can't be changed.
+func (m *Manager) Set(ctx context.Context, export Export) (*Export, error) {
+	if err := m.backend.Available(ctx); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if export.IQN == "" {
+		export.IQN = TargetIQN(m.cfg.BaseIQN, export.Volume)
+	}
+	if len(export.Portals) == 0 {
+		export.Portals = m.cfg.Portals
+	}
+	export.Device = DevicePath(export.Volume)
+	if err := Validate(export); err != nil {
+		return nil, err
+	}
+	if err := m.checkVolume(ctx, export.Volume); err != nil {
+		return nil, err
+	}
+
+	exports, err := m.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range exports {
+		if e.IQN == export.IQN && e.Volume != export.Volume {
+			return nil, errors.New(errors.ISCSIExists, "target name is used by another volume").
+				WithMetadata("iqn", export.IQN).
+				WithMetadata("volume", e.Volume)
+		}
+		if e.Volume == export.Volume && e.IQN != export.IQN {
+			return nil, errors.New(errors.ISCSIExists, "volume is exported by another target").
+				WithMetadata("iqn", e.IQN)
+		}
+	}
+
+	if err := m.backend.Apply(ctx, export); err != nil {
+		return nil, err
+	}
+	return m.Get(ctx, export.Volume)
+}
+
+// Delete removes the iSCSI export of a volume
+func (m *Manager) Delete(ctx context.Context, volume string) error {
+	if err := m.backend.Available(ctx); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	export, err := m.get(ctx, volume)
+	if err != nil {
+		return err
+	}
+	return m.backend.Delete(ctx, *export)
+}
+
+func (m *Manager) list(ctx context.Context) ([]Export, error) {
+	if err := m.backend.Available(ctx); err != nil {
+		return nil, err
+	}
+	return m.backend.List(ctx)
+}
+
+func (m *Manager) get(ctx context.Context, volume string) (*Export, error) {
+	exports, err := m.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range exports {
+		if e.Volume == volume {
+			return &e, nil
+		}
+	}
+	return nil, errors.New(errors.ISCSINotFound, volume)
+}
+
+// redact clears the CHAP secrets of an export
+func redact(export *Export) {
+	for i := range export.ACLs {
+		if chap := export.ACLs[i].CHAP; chap != nil {
+			redacted := *chap
+			redacted.Password = ""
+			redacted.MutualPassword = ""
+			export.ACLs[i].CHAP = &redacted
+		}
+	}
+}
+
+// zfsCheckVolume fails unless volume is an existing ZFS volume
+func (m *Manager) zfsCheckVolume(ctx context.Context, volume string) error {
+	args := []string{"list", "-H", "-o", "name", "-t", "volume", volume}
+
+	out, err := m.executor.Execute(ctx, command.CommandOptions{}, "zfs list", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return errors.Wrap(err, errors.ZFSDatasetNotFound).
+				WithMetadata("output", string(out))
+		}
+		return errors.Wrap(err, errors.ZFSDatasetNotFound).
+			WithMetadata("volume", volume)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iscsi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+// fakeBackend keeps exports in memory in place of a kernel target
+type fakeBackend struct {
+	exports     map[string]Export
+	unavailable bool
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{exports: make(map[string]Export)}
+}
+
+func (b *fakeBackend) Available(ctx context.Context) error {
+	if b.unavailable {
+		return errors.New(errors.ISCSIBackendUnavailable, "targetcli not found")
+	}
+	return nil
+}
+
+func (b *fakeBackend) List(ctx context.Context) ([]Export, error) {
+	exports := []Export{}
+	for _, e := range b.exports {
+		acls := make([]ACL, len(e.ACLs))
+		for i, acl := range e.ACLs {
+			acls[i] = acl
+			if acl.CHAP != nil {
+				chap := *acl.CHAP
+				acls[i].CHAP = &chap
+			}
+		}
+		e.ACLs = acls
+		exports = append(exports, e)
+	}
+	return exports, nil
+}
+
+func (b *fakeBackend) Apply(ctx context.Context, export Export) error {
+	b.exports[export.IQN] = export
+	return nil
+}
+
+func (b *fakeBackend) Delete(ctx context.Context, export Export) error {
+	delete(b.exports, export.IQN)
+	return nil
+}
+
+func newTestManager(backend Backend) *Manager {
+	m := NewManager(nil, backend, Config{})
+	m.checkVolume = func(ctx context.Context, volume string) error {
+		if volume == "tank/missing" {
+			return errors.New(errors.ZFSDatasetNotFound, volume)
+		}
+		return nil
+	}
+	return m
+}
+
+func errorCode(err error) errors.ErrorCode {
+	if re, ok := err.(*errors.RodentError); ok {
+		return re.Code
+	}
+	return 0
+}
+
+func TestManagerSet(t *testing.T) {
+	backend := newFakeBackend()
+	m := newTestManager(backend)
+	ctx := context.Background()
+
+	export, err := m.Set(ctx, Export{
+		Volume: "tank/vol1",
+		ACLs: []ACL{{
+			Initiator: "iqn.1994-05.com.redhat:client1",
+			CHAP:      &CHAP{UserID: "client1", Password: "secret-secret"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if export.IQN != "iqn.2024-01.in.tinkershack.rodent:tank.vol1" {
+		t.Errorf("Set() IQN = %q", export.IQN)
+	}
+	if export.Device != "/dev/zvol/tank/vol1" {
+		t.Errorf("Set() device = %q", export.Device)
+	}
+	if len(export.Portals) != 1 || export.Portals[0] != DefaultPortal {
+		t.Errorf("Set() portals = %v, want default portal", export.Portals)
+	}
+	if chap := export.ACLs[0].CHAP; chap == nil || chap.UserID != "client1" || chap.Password != "" {
+		t.Errorf("Set() CHAP = %+v, want user without secret", chap)
+	}
+
+	stored := backend.exports[export.IQN]
+	if stored.ACLs[0].CHAP.Password != "secret-secret" {
+		t.Error("redaction of the response changed the stored secret")
+	}
+
+	if _, err := m.Set(ctx, Export{
+		Volume: "tank/vol1",
+		IQN:    "iqn.2024-01.com.example:other",
+	}); errorCode(err) != errors.ISCSIExists {
+		t.Errorf("Set() second target for volume error = %v, want ISCSIExists", err)
+	}
+	if _, err := m.Set(ctx, Export{Volume: "tank/missing"}); errorCode(err) != errors.ZFSDatasetNotFound {
+		t.Errorf("Set() missing volume error = %v, want ZFSDatasetNotFound", err)
+	}
+}
+
+func TestManagerDelete(t *testing.T) {
+	backend := newFakeBackend()
+	m := newTestManager(backend)
+	ctx := context.Background()
+
+	if _, err := m.Set(ctx, Export{Volume: "tank/vol1"}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := m.Delete(ctx, "tank/vol1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := m.Get(ctx, "tank/vol1"); errorCode(err) != errors.ISCSINotFound {
+		t.Errorf("Get() after delete error = %v, want ISCSINotFound", err)
+	}
+	if err := m.Delete(ctx, "tank/vol1"); errorCode(err) != errors.ISCSINotFound {
+		t.Errorf("Delete() twice error = %v, want ISCSINotFound", err)
+	}
+}
+
+func TestManagerUnavailable(t *testing.T) {
+	backend := newFakeBackend()
+	backend.unavailable = true
+	m := newTestManager(backend)
+
+	if _, err := m.List(context.Background()); errorCode(err) != errors.ISCSIBackendUnavailable {
+		t.Errorf("List() error = %v, want ISCSIBackendUnavailable", err)
+	}
+	if _, err := m.Set(context.Background(), Export{Volume: "tank/vol1"}); errorCode(err) != errors.ISCSIBackendUnavailable {
+		t.Errorf("Set() error = %v, want ISCSIBackendUnavailable", err)
+	}
+}
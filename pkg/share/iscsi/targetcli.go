@@ -0,0 +1,447 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iscsi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+// DefaultSaveConfig is where targetcli persists the LIO configuration
+const DefaultSaveConfig = "/etc/target/saveconfig.json"
+
+// Runner runs targetcli. It is satisfied by ExecRunner and replaced in
+// tests.
+type Runner interface {
+	Run(ctx context.Context, name string, args ...string) ([]byte, error)
+	// RunInput runs a command with input on stdin, for values such as CHAP
+	// secrets that must not show up in the process list
+	RunInput(ctx context.Context, input string, name string, args ...string) ([]byte, error)
+}
+
+// sudoCommands need root and run through sudo when ExecRunner.Sudo is set:
+// targetcli itself and cat, which reads the root-only saved configuration
+var sudoCommands = map[string]bool{
+	"targetcli": true,
+	"cat":       true,
+}
+
+// ExecRunner runs commands on the host. With Sudo set, the commands in
+// sudoCommands run through sudo, so Rodent needs neither root nor read
+// access to /etc/target itself.
+type ExecRunner struct {
+	Sudo bool
+}
+
+func (r ExecRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	name, args = r.command(name, args)
+	return exec.CommandContext(ctx, name, args...).CombinedOutput()
+}
+
+func (r ExecRunner) RunInput(ctx context.Context, input string, name string, args ...string) ([]byte, error) {
+	name, args = r.command(name, args)
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = bytes.NewBufferString(input)
+	return cmd.CombinedOutput()
+}
+
+// command prefixes privileged commands with sudo
+func (r ExecRunner) command(name string, args []string) (string, []string) {
+	if r.Sudo && sudoCommands[name] {
+		return "sudo", append([]string{name}, args...)
+	}
+	return name, args
+}
+
+// TargetCLI manages LIO targets with targetcli. Each export is a target
+// with a single portal group holding one LUN backed by a block backstore
+// on the zvol. State is read back from the saved configuration, which is
+// written after every change.
+type TargetCLI struct {
+	runner     Runner
+	saveConfig string
+}
+
+func NewTargetCLI(runner Runner, saveConfig string) *TargetCLI {
+	if saveConfig == "" {
+		saveConfig = DefaultSaveConfig
+	}
+	return &TargetCLI{runner: runner, saveConfig: saveConfig}
+}
+
+// savedConfig is the subset of the targetcli saveconfig.json used for
+// exports
+type savedConfig struct {
+	StorageObjects []struct {
+		Name   string `json:"name"`
+		Plugin string `json:"plugin"`
+		Dev    string `json:"dev"`
+	} `json:"storage_objects"`
+	Targets []savedTarget `json:"targets"`
+}
+
+type savedTarget struct {
+	WWN    string `json:"wwn"`
+	Fabric string `json:"fabric"`
+	TPGs   []struct {
+		Tag  int `json:"tag"`
+		LUNs []struct {
+			Index         int    `json:"index"`
+			StorageObject string `json:"storage_object"`
+		} `json:"luns"`
+		NodeACLs []struct {
+			NodeWWN            string `json:"node_wwn"`
+			ChapUserID         string `json:"chap_userid"`
+			ChapPassword       string `json:"chap_password"`
+			ChapMutualUserID   string `json:"chap_mutual_userid"`
+			ChapMutualPassword string `json:"chap_mutual_password"`
+		} `json:"node_acls"`
+		Portals []struct {
+			IPAddress string `json:"ip_address"`
+			Port      int    `json:"port"`
+		} `json:"portals"`
+	} `json:"tpgs"`
+}
+
+func (t *TargetCLI) Available(ctx context.Context) error {
+	if out, err := t.runner.Run(ctx, "which", "targetcli"); err != nil {
+		return errors.Wrap(err, errors.ISCSIBackendUnavailable).
+			WithMetadata("output", string(out))
+	}
+	return nil
+}
+
+func (t *TargetCLI) List(ctx context.Context) ([]Export, error) {
+	cfg, err := t.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return parseSavedConfig(cfg), nil
+}
+
+// load reads the saved configuration. The file is readable by root only,
+// so it is read through the runner rather than opened directly. A missing
+// file means targetcli hasn't saved any configuration yet.
+func (t *TargetCLI) load(ctx context.Context) (*savedConfig, error) {
+	data, err := t.runner.Run(ctx, "cat", t.saveConfig)
+	if err != nil {
+		if strings.Contains(string(data), "No such file or directory") {
+			return &savedConfig{}, nil
+		}
+		return nil, errors.Wrap(err, errors.ISCSIOperation).
+			WithMetadata("output", string(data))
+	}
+	var cfg savedConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, errors.Wrap(err, errors.ISCSIOperation).
+			WithMetadata("file", t.saveConfig)
+	}
+	return &cfg, nil
+}
+
+// parseSavedConfig returns the exports of iSCSI targets whose LUN is
+// backed by a zvol; other targets are not Rodent's to manage
+func parseSavedConfig(cfg *savedConfig) []Export {
+	devices := make(map[string]string)
+	for _, so := range cfg.StorageObjects {
+		if so.Plugin == "block" && strings.HasPrefix(so.Dev, zvolDir) {
+			devices["/backstores/block/"+so.Name] = so.Dev
+		}
+	}
+
+	exports := []Export{}
+	for _, target := range cfg.Targets {
+		if target.Fabric != "iscsi" || len(target.TPGs) != 1 {
+			continue
+		}
+		tpg := target.TPGs[0]
+		if len(tpg.LUNs) != 1 {
+			continue
+		}
+		device, ok := devices[tpg.LUNs[0].StorageObject]
+		if !ok {
+			continue
+		}
+
+		export := Export{
+			Volume:  strings.TrimPrefix(device, zvolDir),
+			IQN:     target.WWN,
+			LUN:     tpg.LUNs[0].Index,
+			Device:  device,
+			Portals: []string{},
+			ACLs:    []ACL{},
+		}
+		for _, p := range tpg.Portals {
+			export.Portals = append(export.Portals,
+				net.JoinHostPort(p.IPAddress, strconv.Itoa(p.Port)))
+		}
+		for _, a := range tpg.NodeACLs {
+			acl := ACL{Initiator: a.NodeWWN}
+			if a.ChapUserID != "" {
+				acl.CHAP = &CHAP{
+					UserID:         a.ChapUserID,
+					Password:       a.ChapPassword,
+					MutualUserID:   a.ChapMutualUserID,
+					MutualPassword: a.ChapMutualPassword,
+				}
+			}
+			export.ACLs = append(export.ACLs, acl)
+		}
+		exports = append(exports, export)
+	}
+	return exports
+}
+
+func (t *TargetCLI) Apply(ctx context.Context, export Export) error {
+	cfg, err := t.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	var current *Export
+	for _, e := range parseSavedConfig(cfg) {
+		if e.IQN == export.IQN {
+			current = &e
+			break
+		}
+	}
+
+	tpg := "/iscsi/" + export.IQN + "/tpg1"
+	if current == nil {
+		if err := t.create(ctx, cfg, export); err != nil {
+			return err
+		}
+		current = &Export{IQN: export.IQN, LUN: export.LUN, Device: export.Device}
+	} else if current.Device != export.Device || current.LUN != export.LUN {
+		return errors.New(errors.ISCSIInvalidConfig,
+			"the volume and LUN of a target can't be changed; delete the export first").
+			WithMetadata("iqn", export.IQN)
+	}
+
+	// Portals
+	want := make(map[string]bool)
+	for _, p := range export.Portals {
+		want[normalizePortal(p)] = true
+	}
+	have := make(map[string]bool)
+	for _, p := range current.Portals {
+		p = normalizePortal(p)
+		have[p] = true
+		if !want[p] {
+			ip, port, _ := splitPortal(p)
+			if err := t.run(ctx, tpg+"/portals", "delete", ip, strconv.Itoa(port)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, p := range export.Portals {
+		if have[normalizePortal(p)] {
+			continue
+		}
+		ip, port, err := splitPortal(p)
+		if err != nil {
+			return err
+		}
+		if err := t.run(ctx, tpg+"/portals", "create", ip, strconv.Itoa(port)); err != nil {
+			return err
+		}
+	}
+
+	// ACLs
+	wantACL := make(map[string]bool)
+	for _, acl := range export.ACLs {
+		wantACL[acl.Initiator] = true
+	}
+	haveACL := make(map[string]bool)
+	for _, acl := range current.ACLs {
+		haveACL[acl.Initiator] = true
+		if !wantACL[acl.Initiator] {
+			if err := t.run(ctx, tpg+"/acls", "delete", acl.Initiator); err != nil {
+				return err
+			}
+		}
+	}
+	auth := "0"
+	for _, acl := range export.ACLs {
+		if !haveACL[acl.Initiator] {
+			if err := t.run(ctx, tpg+"/acls", "create", acl.Initiator, "add_mapped_luns=true"); err != nil {
+				return err
+			}
+		}
+		chap := CHAP{}
+		if acl.CHAP != nil {
+			chap = *acl.CHAP
+			auth = "1"
+		}
+		if err := t.setAuth(ctx, tpg+"/acls/"+acl.Initiator, chap); err != nil {
+			return err
+		}
+	}
+	if err := t.run(ctx, tpg, "set", "attribute",
+		"authentication="+auth, "generate_node_acls=0", "demo_mode_write_protect=1"); err != nil {
+		return err
+	}
+
+	if err := t.save(ctx); err != nil {
+		return err
+	}
+	return t.verifyAuth(ctx, export)
+}
+
+// setAuth sets the CHAP credentials of an ACL. They are fed to an
+// interactive targetcli on stdin, since arguments can be read by any local
+// user from the process list.
+func (t *TargetCLI) setAuth(ctx context.Context, acl string, chap CHAP) error {
+	line := strings.Join([]string{acl, "set", "auth",
+		"userid=" + chap.UserID, "password=" + chap.Password,
+		"mutual_userid=" + chap.MutualUserID, "mutual_password=" + chap.MutualPassword}, " ")
+
+	out, err := t.runner.RunInput(ctx, line+"\nexit\n", "targetcli")
+	if err != nil {
+		output := string(out)
+		for _, secret := range []string{chap.Password, chap.MutualPassword} {
+			if secret != "" {
+				output = strings.ReplaceAll(output, secret, "********")
+			}
+		}
+		return errors.Wrap(err, errors.ISCSIOperation).
+			WithMetadata("command", "targetcli "+acl+" set auth").
+			WithMetadata("output", output)
+	}
+	return nil
+}
+
+// verifyAuth checks the saved credentials against the export. An
+// interactive targetcli reports a failed command in its output but still
+// exits with success, so setAuth alone can't tell.
+func (t *TargetCLI) verifyAuth(ctx context.Context, export Export) error {
+	cfg, err := t.load(ctx)
+	if err != nil {
+		return err
+	}
+	for _, e := range parseSavedConfig(cfg) {
+		if e.IQN != export.IQN {
+			continue
+		}
+		saved := make(map[string]CHAP)
+		for _, acl := range e.ACLs {
+			if acl.CHAP != nil {
+				saved[acl.Initiator] = *acl.CHAP
+			}
+		}
+		for _, acl := range export.ACLs {
+			want := CHAP{}
+			if acl.CHAP != nil {
+				want = *acl.CHAP
+			}
+			if saved[acl.Initiator] != want {
+				return errors.New(errors.ISCSIOperation, "CHAP credentials were not applied").
+					WithMetadata("initiator", acl.Initiator)
+			}
+		}
+		return nil
+	}
+	return errors.New(errors.ISCSIOperation, "target is missing from the saved configuration").
+		WithMetadata("iqn", export.IQN)
+}
+
+// create adds the backstore, target and LUN of a new export, dropping the
+// portal targetcli creates by default
+func (t *TargetCLI) create(ctx context.Context, cfg *savedConfig, export Export) error {
+	name := backstoreName(export.Volume)
+	exists := false
+	for _, so := range cfg.StorageObjects {
+		if so.Name != name {
+			continue
+		}
+		if so.Plugin != "block" || so.Dev != export.Device {
+			return errors.New(errors.ISCSIExists, "backstore name is used by another device").
+				WithMetadata("backstore", name)
+		}
+		exists = true
+	}
+	if !exists {
+		if err := t.run(ctx, "/backstores/block", "create",
+			"name="+name, "dev="+export.Device); err != nil {
+			return err
+		}
+	}
+
+	if err := t.run(ctx, "/iscsi", "create", export.IQN); err != nil {
+		return err
+	}
+	tpg := "/iscsi/" + export.IQN + "/tpg1"
+	if err := t.run(ctx, tpg+"/luns", "create", "/backstores/block/"+name,
+		"lun="+strconv.Itoa(export.LUN), "add_mapped_luns=false"); err != nil {
+		return err
+	}
+
+	// Newer targetcli versions add a portal on all addresses; it is
+	// recreated below when the export lists it
+	if err := t.save(ctx); err != nil {
+		return err
+	}
+	cfg, err := t.load(ctx)
+	if err != nil {
+		return err
+	}
+	for _, target := range cfg.Targets {
+		if target.WWN != export.IQN || len(target.TPGs) == 0 {
+			continue
+		}
+		for _, p := range target.TPGs[0].Portals {
+			if err := t.run(ctx, tpg+"/portals", "delete",
+				p.IPAddress, strconv.Itoa(p.Port)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t *TargetCLI) Delete(ctx context.Context, export Export) error {
+	if err := t.run(ctx, "/iscsi", "delete", export.IQN); err != nil {
+		return err
+	}
+	if err := t.run(ctx, "/backstores/block", "delete", backstoreName(export.Volume)); err != nil {
+		return err
+	}
+	return t.save(ctx)
+}
+
+func (t *TargetCLI) save(ctx context.Context) error {
+	return t.run(ctx, "/", "saveconfig", t.saveConfig)
+}
+
+// run runs a targetcli command at path
+func (t *TargetCLI) run(ctx context.Context, path string, args ...string) error {
+	out, err := t.runner.Run(ctx, "targetcli", append([]string{path}, args...)...)
+	if err != nil {
+		return errors.Wrap(err, errors.ISCSIOperation).
+			WithMetadata("command", strings.Join(append([]string{"targetcli", path}, args[:1]...), " ")).
+			WithMetadata("output", string(out))
+	}
+	return nil
+}
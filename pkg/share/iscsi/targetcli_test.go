@@ -0,0 +1,291 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iscsi
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const savedConfigJSON = `{
+  "fabric_modules": [],
+  "storage_objects": [
+    {
+      "attributes": {"block_size": 512},
+      "dev": "/dev/zvol/tank/vol1",
+      "name": "zvol-tank_vol1",
+      "plugin": "block",
+      "readonly": false,
+      "write_back": false,
+      "wwn": "2d7a0f3c-5b1f-4e0c-9a64-0f4e3f2c1a10"
+    },
+    {
+      "dev": "/dev/sdb",
+      "name": "disk1",
+      "plugin": "block"
+    }
+  ],
+  "targets": [
+    {
+      "fabric": "iscsi",
+      "tpgs": [
+        {
+          "attributes": {"authentication": 0, "generate_node_acls": 0},
+          "enable": true,
+          "luns": [
+            {"index": 0, "storage_object": "/backstores/block/zvol-tank_vol1"}
+          ],
+          "node_acls": [
+            {
+              "attributes": {},
+              "mapped_luns": [{"index": 0, "tpg_lun": 0, "write_protect": false}],
+              "node_wwn": "iqn.1994-05.com.redhat:client1"
+            }
+          ],
+          "portals": [
+            {"ip_address": "0.0.0.0", "iser": false, "offload": false, "port": 3260}
+          ],
+          "tag": 1
+        }
+      ],
+      "wwn": "iqn.2024-01.in.tinkershack.rodent:tank.vol1"
+    },
+    {
+      "fabric": "iscsi",
+      "tpgs": [
+        {
+          "luns": [{"index": 0, "storage_object": "/backstores/block/disk1"}],
+          "node_acls": [],
+          "portals": [],
+          "tag": 1
+        }
+      ],
+      "wwn": "iqn.2003-01.org.linux-iscsi.host:sn.1234"
+    }
+  ]
+}`
+
+// recordingRunner records the targetcli commands it is given and fails
+// none. Input is recorded after a "<". Reads of the saved configuration with
+// cat are served from the file and not recorded.
+type recordingRunner struct {
+	calls []string
+	// saved is written as the saved configuration on saveconfig if set
+	saved string
+}
+
+func (r *recordingRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	if name == "cat" {
+		data, err := os.ReadFile(args[0])
+		if os.IsNotExist(err) {
+			return []byte("cat: " + args[0] + ": No such file or directory\n"), err
+		}
+		return data, err
+	}
+	r.calls = append(r.calls, strings.Join(append([]string{name}, args...), " "))
+	if r.saved != "" && len(args) == 3 && args[1] == "saveconfig" {
+		return nil, os.WriteFile(args[2], []byte(r.saved), 0600)
+	}
+	return nil, nil
+}
+
+func (r *recordingRunner) RunInput(
+	ctx context.Context,
+	input string,
+	name string,
+	args ...string,
+) ([]byte, error) {
+	lines := strings.Split(strings.TrimSpace(input), "\n")
+	r.calls = append(r.calls, strings.Join(append(append([]string{name}, args...), "<"), " ")+
+		" "+strings.Join(lines, "; "))
+	return nil, nil
+}
+
+func newTestTargetCLI(t *testing.T) (*TargetCLI, *recordingRunner) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "saveconfig.json")
+	if err := os.WriteFile(path, []byte(savedConfigJSON), 0600); err != nil {
+		t.Fatal(err)
+	}
+	runner := &recordingRunner{}
+	return NewTargetCLI(runner, path), runner
+}
+
+func TestTargetCLIList(t *testing.T) {
+	backend, _ := newTestTargetCLI(t)
+
+	exports, err := backend.List(context.Background())
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	want := []Export{{
+		Volume:  "tank/vol1",
+		IQN:     "iqn.2024-01.in.tinkershack.rodent:tank.vol1",
+		LUN:     0,
+		Portals: []string{"0.0.0.0:3260"},
+		ACLs:    []ACL{{Initiator: "iqn.1994-05.com.redhat:client1"}},
+		Device:  "/dev/zvol/tank/vol1",
+	}}
+	if !reflect.DeepEqual(exports, want) {
+		t.Errorf("List() = %+v, want %+v", exports, want)
+	}
+}
+
+// updateExport moves the ACL of tank/vol1 to client2 with CHAP
+var updateExport = Export{
+	Volume:  "tank/vol1",
+	IQN:     "iqn.2024-01.in.tinkershack.rodent:tank.vol1",
+	Device:  "/dev/zvol/tank/vol1",
+	Portals: []string{"10.0.0.1:3260"},
+	ACLs: []ACL{{
+		Initiator: "iqn.1994-05.com.redhat:client2",
+		CHAP:      &CHAP{UserID: "client2", Password: "secret-secret"},
+	}},
+}
+
+func TestTargetCLIApplyUpdate(t *testing.T) {
+	backend, runner := newTestTargetCLI(t)
+	runner.saved = strings.Replace(savedConfigJSON,
+		`"node_wwn": "iqn.1994-05.com.redhat:client1"`,
+		`"chap_userid": "client2", "chap_password": "secret-secret", `+
+			`"node_wwn": "iqn.1994-05.com.redhat:client2"`, 1)
+	iqn := updateExport.IQN
+	tpg := "/iscsi/" + iqn + "/tpg1"
+
+	if err := backend.Apply(context.Background(), updateExport); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	want := []string{
+		"targetcli " + tpg + "/portals delete 0.0.0.0 3260",
+		"targetcli " + tpg + "/portals create 10.0.0.1 3260",
+		"targetcli " + tpg + "/acls delete iqn.1994-05.com.redhat:client1",
+		"targetcli " + tpg + "/acls create iqn.1994-05.com.redhat:client2 add_mapped_luns=true",
+		"targetcli < " + tpg + "/acls/iqn.1994-05.com.redhat:client2 set auth " +
+			"userid=client2 password=secret-secret mutual_userid= mutual_password=; exit",
+		"targetcli " + tpg + " set attribute authentication=1 generate_node_acls=0 demo_mode_write_protect=1",
+		fmt.Sprintf("targetcli / saveconfig %s", backend.saveConfig),
+	}
+	if !reflect.DeepEqual(runner.calls, want) {
+		t.Errorf("Apply() ran\n%s\nwant\n%s", strings.Join(runner.calls, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestTargetCLIApplyUnsavedCHAP(t *testing.T) {
+	backend, _ := newTestTargetCLI(t)
+
+	// The saved configuration never picks up client2, as when targetcli
+	// rejects the auth command but exits with success
+	if err := backend.Apply(context.Background(), updateExport); err == nil {
+		t.Fatal("Apply() succeeded without the CHAP credentials being saved")
+	}
+}
+
+func TestTargetCLIApplyChangedLUN(t *testing.T) {
+	backend, runner := newTestTargetCLI(t)
+
+	err := backend.Apply(context.Background(), Export{
+		Volume:  "tank/vol1",
+		IQN:     "iqn.2024-01.in.tinkershack.rodent:tank.vol1",
+		Device:  "/dev/zvol/tank/vol1",
+		LUN:     1,
+		Portals: []string{DefaultPortal},
+	})
+	if err == nil {
+		t.Fatal("Apply() changed the LUN of an existing target")
+	}
+	if len(runner.calls) != 0 {
+		t.Errorf("Apply() ran %v after rejecting the change", runner.calls)
+	}
+}
+
+func TestTargetCLIApplyCreate(t *testing.T) {
+	backend, runner := newTestTargetCLI(t)
+	runner.saved = strings.ReplaceAll(savedConfigJSON, "vol1", "vol2")
+	iqn := "iqn.2024-01.in.tinkershack.rodent:tank.vol2"
+
+	err := backend.Apply(context.Background(), Export{
+		Volume:  "tank/vol2",
+		IQN:     iqn,
+		Device:  "/dev/zvol/tank/vol2",
+		LUN:     0,
+		Portals: []string{DefaultPortal},
+		ACLs:    []ACL{{Initiator: "iqn.1994-05.com.redhat:client1"}},
+	})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	for _, prefix := range []string{
+		"targetcli /backstores/block create name=zvol-tank_vol2 dev=/dev/zvol/tank/vol2",
+		"targetcli /iscsi create " + iqn,
+		"targetcli /iscsi/" + iqn + "/tpg1/luns create /backstores/block/zvol-tank_vol2 lun=0",
+		"targetcli /iscsi/" + iqn + "/tpg1/portals create 0.0.0.0 3260",
+		"targetcli /iscsi/" + iqn + "/tpg1/acls create iqn.1994-05.com.redhat:client1",
+		"targetcli /iscsi/" + iqn + "/tpg1 set attribute authentication=0",
+	} {
+		found := false
+		for _, call := range runner.calls {
+			if strings.HasPrefix(call, prefix) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Apply() didn't run %q; ran\n%s", prefix, strings.Join(runner.calls, "\n"))
+		}
+	}
+}
+
+func TestTargetCLIListWithoutSavedConfig(t *testing.T) {
+	backend := NewTargetCLI(&recordingRunner{}, filepath.Join(t.TempDir(), "saveconfig.json"))
+
+	exports, err := backend.List(context.Background())
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(exports) != 0 {
+		t.Errorf("List() = %+v, want no exports", exports)
+	}
+}
+
+func TestExecRunnerSudo(t *testing.T) {
+	tests := []struct {
+		sudo bool
+		name string
+		args []string
+		want []string
+	}{
+		{true, "targetcli", []string{"/", "saveconfig"}, []string{"sudo", "targetcli", "/", "saveconfig"}},
+		{true, "cat", []string{DefaultSaveConfig}, []string{"sudo", "cat", DefaultSaveConfig}},
+		{true, "which", []string{"targetcli"}, []string{"which", "targetcli"}},
+		{false, "targetcli", []string{"/", "saveconfig"}, []string{"targetcli", "/", "saveconfig"}},
+	}
+
+	for _, tt := range tests {
+		name, args := ExecRunner{Sudo: tt.sudo}.command(tt.name, tt.args)
+		if got := append([]string{name}, args...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("command(%q, %q) with sudo %v = %q, want %q", tt.name, tt.args, tt.sudo, got, tt.want)
+		}
+	}
+}
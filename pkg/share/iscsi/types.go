@@ -0,0 +1,89 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iscsi
+
+import "context"
+
+// DefaultPortal listens on all addresses at the iSCSI port
+const DefaultPortal = "0.0.0.0:3260"
+
+// Export presents a ZFS volume to initiators as a LUN of its own target
+type Export struct {
+	// Volume is the zvol backing the LUN
+	Volume string `json:"volume"`
+	// IQN is the target name. It is derived from the base IQN and the
+	// volume name when empty.
+	IQN string `json:"iqn,omitempty"`
+	// LUN is the logical unit number the volume is mapped to
+	LUN int `json:"lun"`
+	// Portals are the ip:port pairs the target listens on
+	Portals []string `json:"portals,omitempty"`
+	// ACLs are the initiators allowed to log in. There is no anonymous
+	// access; an export without ACLs is unreachable.
+	ACLs []ACL `json:"acls"`
+
+	// Device is the block device of the volume, set by the manager
+	Device string `json:"device,omitempty"`
+}
+
+// ACL grants an initiator access to the LUN of an export
+type ACL struct {
+	// Initiator is the IQN, EUI or NAA name of the initiator
+	Initiator string `json:"initiator"`
+	// CHAP requires the initiator to authenticate
+	CHAP *CHAP `json:"chap,omitempty"`
+}
+
+// CHAP credentials of an initiator. Secrets are never returned by the API.
+type CHAP struct {
+	UserID   string `json:"userid"`
+	Password string `json:"password,omitempty"`
+	// Mutual credentials authenticate the target to the initiator
+	MutualUserID   string `json:"mutual_userid,omitempty"`
+	MutualPassword string `json:"mutual_password,omitempty"`
+}
+
+// Backend manages the kernel target. Every method works on complete
+// exports: Apply makes the target of an export match it.
+type Backend interface {
+	// Available reports an error when the backend can't be used
+	Available(ctx context.Context) error
+	// List returns the exports of zvol backed targets
+	List(ctx context.Context) ([]Export, error)
+	// Apply creates the target of an export or updates its portals and
+	// ACLs
+	Apply(ctx context.Context, export Export) error
+	// Delete removes the target of an export and its backstore
+	Delete(ctx context.Context, export Export) error
+}
+
+// Config of the iSCSI export manager
+type Config struct {
+	// BaseIQN prefixes the names of generated targets
+	BaseIQN string
+	// Portals are used for exports not listing their own
+	Portals []string
+}
+
+// DefaultConfig returns the default export configuration
+func DefaultConfig() Config {
+	return Config{
+		BaseIQN: "iqn.2024-01.in.tinkershack.rodent",
+		Portals: []string{DefaultPortal},
+	}
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iscsi
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+const (
+	zvolDir = "/dev/zvol/"
+
+	maxLUN         = 255
+	maxACLs        = 64
+	maxNameLength  = 223
+	minCHAPSecret  = 12
+	maxCHAPSecret  = 255
+	maxCHAPUserLen = 255
+)
+
+var (
+	iqnRegex = regexp.MustCompile(
+		`^iqn\.[0-9]{4}-(0[1-9]|1[0-2])\.[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*(:[a-z0-9.:-]+)?$`)
+	euiRegex = regexp.MustCompile(`^eui\.[0-9a-fA-F]{16}$`)
+	naaRegex = regexp.MustCompile(`^naa\.([0-9a-fA-F]{16}|[0-9a-fA-F]{32})$`)
+
+	chapUserRegex = regexp.MustCompile(`^[A-Za-z0-9._:@-]+$`)
+	// chapSecretRegex leaves out spaces, quotes, = and \ which targetcli
+	// would split or unescape
+	chapSecretRegex = regexp.MustCompile(`^[A-Za-z0-9._:@!#%+,/^~*?-]+$`)
+)
+
+// ValidateName checks an iSCSI name in iqn., eui. or naa. format
+func ValidateName(name string) error {
+	if len(name) > maxNameLength ||
+		!(iqnRegex.MatchString(name) || euiRegex.MatchString(name) || naaRegex.MatchString(name)) {
+		return errors.New(errors.ISCSIInvalidConfig, "invalid iSCSI name").
+			WithMetadata("name", name)
+	}
+	return nil
+}
+
+// Validate checks an export with its IQN and portals filled in
+func Validate(export Export) error {
+	if export.Volume == "" {
+		return errors.New(errors.ISCSIInvalidConfig, "volume is required")
+	}
+	if err := ValidateName(export.IQN); err != nil {
+		return err
+	}
+	if export.LUN < 0 || export.LUN > maxLUN {
+		return errors.New(errors.ISCSIInvalidConfig,
+			fmt.Sprintf("LUN must be between 0 and %d", maxLUN))
+	}
+
+	if len(export.Portals) == 0 {
+		return errors.New(errors.ISCSIInvalidConfig, "at least one portal is required")
+	}
+	for _, portal := range export.Portals {
+		if _, _, err := splitPortal(portal); err != nil {
+			return err
+		}
+	}
+
+	if len(export.ACLs) > maxACLs {
+		return errors.New(errors.ISCSIInvalidConfig,
+			fmt.Sprintf("at most %d ACLs are supported", maxACLs))
+	}
+	seen := make(map[string]bool)
+	withCHAP := 0
+	for _, acl := range export.ACLs {
+		if err := ValidateName(acl.Initiator); err != nil {
+			return err
+		}
+		if seen[acl.Initiator] {
+			return errors.New(errors.ISCSIInvalidConfig, "duplicate initiator").
+				WithMetadata("initiator", acl.Initiator)
+		}
+		seen[acl.Initiator] = true
+
+		if acl.CHAP != nil {
+			if err := validateCHAP(*acl.CHAP); err != nil {
+				return err
+			}
+			withCHAP++
+		}
+	}
+	// Authentication is enabled for the whole target portal group, so an
+	// initiator without credentials couldn't log in once any has them
+	if withCHAP > 0 && withCHAP != len(export.ACLs) {
+		return errors.New(errors.ISCSIInvalidConfig,
+			"CHAP must be set for all ACLs of an export or for none")
+	}
+	return nil
+}
+
+func validateCHAP(chap CHAP) error {
+	if err := validateCHAPPair("CHAP", chap.UserID, chap.Password); err != nil {
+		return err
+	}
+	if chap.MutualUserID == "" && chap.MutualPassword == "" {
+		return nil
+	}
+	if err := validateCHAPPair("mutual CHAP", chap.MutualUserID, chap.MutualPassword); err != nil {
+		return err
+	}
+	if chap.MutualPassword == chap.Password {
+		return errors.New(errors.ISCSIInvalidConfig,
+			"mutual CHAP password must differ from the CHAP password")
+	}
+	return nil
+}
+
+func validateCHAPPair(kind, user, secret string) error {
+	if user == "" || len(user) > maxCHAPUserLen || !chapUserRegex.MatchString(user) {
+		return errors.New(errors.ISCSIInvalidConfig, "invalid "+kind+" user id")
+	}
+	if len(secret) < minCHAPSecret || len(secret) > maxCHAPSecret {
+		return errors.New(errors.ISCSIInvalidConfig,
+			fmt.Sprintf("%s password must be %d to %d characters", kind, minCHAPSecret, maxCHAPSecret))
+	}
+	if !chapSecretRegex.MatchString(secret) {
+		return errors.New(errors.ISCSIInvalidConfig, kind+" password contains invalid characters")
+	}
+	return nil
+}
+
+// splitPortal splits an ip:port portal, the IPv6 address in brackets
+func splitPortal(portal string) (string, int, error) {
+	host, port, err := net.SplitHostPort(portal)
+	if err != nil {
+		return "", 0, errors.New(errors.ISCSIInvalidConfig, "portal must be ip:port").
+			WithMetadata("portal", portal)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return "", 0, errors.New(errors.ISCSIInvalidConfig, "portal address must be an IP address").
+			WithMetadata("portal", portal)
+	}
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		return "", 0, errors.New(errors.ISCSIInvalidConfig, "invalid portal port").
+			WithMetadata("portal", portal)
+	}
+	return ip.String(), n, nil
+}
+
+// normalizePortal returns the canonical ip:port form of a portal, or the
+// portal as is when it can't be parsed
+func normalizePortal(portal string) string {
+	ip, port, err := splitPortal(portal)
+	if err != nil {
+		return portal
+	}
+	return net.JoinHostPort(ip, strconv.Itoa(port))
+}
+
+// TargetIQN derives the target name of a volume from the base IQN. Volume
+// name characters IQNs don't allow are replaced with dashes and the
+// dataset separator becomes a dot.
+func TargetIQN(base, volume string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '-', r == ':':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		case r == '/':
+			return '.'
+		}
+		return '-'
+	}, volume)
+	return base + ":" + name
+}
+
+// DevicePath returns the block device of a volume
+func DevicePath(volume string) string {
+	return zvolDir + volume
+}
+
+// backstoreName names the block backstore of a volume
+func backstoreName(volume string) string {
+	return "zvol-" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9',
+			r == '_', r == '-', r == '.':
+			return r
+		}
+		return '_'
+	}, volume)
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iscsi
+
+import "testing"
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"iqn.1994-05.com.redhat:client1", false},
+		{"iqn.1991-05.com.microsoft:host.example.com", false},
+		{"iqn.2024-01.in.tinkershack.rodent:tank.vol1", false},
+		{"eui.02004567A425678D", false},
+		{"naa.52004567ba64678d", false},
+		{"iqn.2024-13.com.example:x", true},
+		{"iqn.2024-01.com.Example:x", true},
+		{"iqn.2024-01.com.example:x y", true},
+		{"iqn.2024-01.com.example:x;rm", true},
+		{"eui.1234", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateName(tt.name); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	base := func() Export {
+		return Export{
+			Volume:  "tank/vol1",
+			IQN:     "iqn.2024-01.in.tinkershack.rodent:tank.vol1",
+			Portals: []string{"0.0.0.0:3260", "[fd00::1]:3260"},
+			ACLs: []ACL{{
+				Initiator: "iqn.1994-05.com.redhat:client1",
+				CHAP: &CHAP{
+					UserID: "client1", Password: "secret-secret",
+					MutualUserID: "target", MutualPassword: "other-secret",
+				},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*Export)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(e *Export) {}},
+		{name: "no volume", modify: func(e *Export) { e.Volume = "" }, wantErr: true},
+		{name: "LUN out of range", modify: func(e *Export) { e.LUN = 256 }, wantErr: true},
+		{name: "no portals", modify: func(e *Export) { e.Portals = nil }, wantErr: true},
+		{name: "hostname portal", modify: func(e *Export) { e.Portals = []string{"host:3260"} }, wantErr: true},
+		{name: "portal without port", modify: func(e *Export) { e.Portals = []string{"10.0.0.1"} }, wantErr: true},
+		{
+			name:    "duplicate initiator",
+			modify:  func(e *Export) { e.ACLs = append(e.ACLs, e.ACLs[0]) },
+			wantErr: true,
+		},
+		{
+			name: "mixed CHAP",
+			modify: func(e *Export) {
+				e.ACLs = append(e.ACLs, ACL{Initiator: "iqn.1994-05.com.redhat:client2"})
+			},
+			wantErr: true,
+		},
+		{
+			name:    "short secret",
+			modify:  func(e *Export) { e.ACLs[0].CHAP.Password = "short" },
+			wantErr: true,
+		},
+		{
+			name:    "secret with space",
+			modify:  func(e *Export) { e.ACLs[0].CHAP.Password = "secret secret" },
+			wantErr: true,
+		},
+		{
+			name:    "same mutual secret",
+			modify:  func(e *Export) { e.ACLs[0].CHAP.MutualPassword = "secret-secret" },
+			wantErr: true,
+		},
+		{
+			name:    "mutual without user",
+			modify:  func(e *Export) { e.ACLs[0].CHAP.MutualUserID = "" },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := base()
+			tt.modify(&e)
+			if err := Validate(e); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTargetIQN(t *testing.T) {
+	got := TargetIQN("iqn.2024-01.in.tinkershack.rodent", "Tank/VMs/disk_0 a")
+	want := "iqn.2024-01.in.tinkershack.rodent:tank.vms.disk-0-a"
+	if got != want {
+		t.Errorf("TargetIQN() = %q, want %q", got, want)
+	}
+	if err := ValidateName(got); err != nil {
+		t.Errorf("TargetIQN() produced an invalid name: %v", err)
+	}
+}
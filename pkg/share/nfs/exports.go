@@ -0,0 +1,110 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nfs
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// ExportSource reads the live NFS export table
+type ExportSource interface {
+	Exports() ([]Export, error)
+}
+
+// FileExports reads the export table from the first of Paths that exists
+type FileExports struct {
+	Paths []string
+}
+
+// DefaultExportSource reads /var/lib/nfs/etab, the table exportfs keeps of
+// what the kernel exports, and falls back to /etc/exports.d/zfs.exports,
+// where OpenZFS writes its shares
+func DefaultExportSource() FileExports {
+	return FileExports{Paths: []string{"/var/lib/nfs/etab", "/etc/exports.d/zfs.exports"}}
+}
+
+func (f FileExports) Exports() ([]Export, error) {
+	for _, path := range f.Paths {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return ParseExports(string(data)), nil
+	}
+	return nil, nil
+}
+
+// ParseExports parses exports(5), etab and `exportfs -v` output into one
+// entry per path and client. A path on a line of its own applies to the
+// clients on the following line, as exportfs -v wraps long paths.
+func ParseExports(text string) []Export {
+	var exports []Export
+	pending := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		path, rest := pending, line
+		if strings.HasPrefix(line, "/") || strings.HasPrefix(line, `"`) {
+			path, rest = splitExportPath(line)
+		}
+		pending = ""
+
+		clients := strings.Fields(rest)
+		if len(clients) == 0 {
+			pending = path
+			continue
+		}
+		for _, client := range clients {
+			host, opts, _ := strings.Cut(client, "(")
+			if host == "" {
+				host = "*"
+			}
+			export := Export{Path: path, Host: host}
+			if opts = strings.TrimSuffix(opts, ")"); opts != "" {
+				export.Options = strings.Split(opts, ",")
+			}
+			exports = append(exports, export)
+		}
+	}
+	return exports
+}
+
+// splitExportPath splits the path, which may be quoted or contain \040
+// escapes, from the client list of an exports line
+func splitExportPath(line string) (string, string) {
+	if strings.HasPrefix(line, `"`) {
+		if end := strings.Index(line[1:], `"`); end >= 0 {
+			return line[1 : end+1], line[end+2:]
+		}
+	}
+	path, rest := line, ""
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		path, rest = line[:i], line[i+1:]
+	}
+	return strings.ReplaceAll(path, `\040`, " "), rest
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nfs
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseExports(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Export
+	}{
+		{
+			name: "etab",
+			text: "/tank/data\t10.0.0.0/24(rw,sync,wdelay,hide,no_subtree_check,sec=sys,secure,root_squash)\n" +
+				"/tank/data\t*(ro,sync)\n",
+			want: []Export{
+				{Path: "/tank/data", Host: "10.0.0.0/24", Options: []string{
+					"rw", "sync", "wdelay", "hide", "no_subtree_check", "sec=sys", "secure", "root_squash",
+				}},
+				{Path: "/tank/data", Host: "*", Options: []string{"ro", "sync"}},
+			},
+		},
+		{
+			name: "exportfs -v with wrapped path",
+			text: "/tank/a-very-long-dataset-name\n" +
+				"\t\t10.0.0.5(sync,wdelay,hide,no_subtree_check,sec=sys,rw,secure,no_root_squash)\n" +
+				"/tank/b   \t<world>(sync,ro)\n",
+			want: []Export{
+				{Path: "/tank/a-very-long-dataset-name", Host: "10.0.0.5", Options: []string{
+					"sync", "wdelay", "hide", "no_subtree_check", "sec=sys", "rw", "secure", "no_root_squash",
+				}},
+				{Path: "/tank/b", Host: "<world>", Options: []string{"sync", "ro"}},
+			},
+		},
+		{
+			name: "exports file with quoted path and default client",
+			text: "# generated by ZFS\n" +
+				"\"/tank/with space\" host1(rw) (ro)\n" +
+				"/tank/escaped\\040path host2\n",
+			want: []Export{
+				{Path: "/tank/with space", Host: "host1", Options: []string{"rw"}},
+				{Path: "/tank/with space", Host: "*", Options: []string{"ro"}},
+				{Path: "/tank/escaped path", Host: "host2"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseExports(tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseExports() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileExportsFallback(t *testing.T) {
+	dir := t.TempDir()
+	fallback := filepath.Join(dir, "zfs.exports")
+	if err := os.WriteFile(fallback, []byte("/tank/data host1(rw)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := FileExports{Paths: []string{filepath.Join(dir, "etab"), fallback}}
+	exports, err := src.Exports()
+	if err != nil {
+		t.Fatalf("Exports failed: %v", err)
+	}
+	if len(exports) != 1 || exports[0].Host != "host1" {
+		t.Errorf("unexpected exports: %+v", exports)
+	}
+}
@@ -0,0 +1,223 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nfs
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/zfs/command"
+)
+
+// Manager manages NFS shares through the sharenfs property of filesystems.
+// Every change renders the complete share and sets it with a single zfs
+// set, so the property is never edited piecemeal.
+type Manager struct {
+	executor *command.CommandExecutor
+	exports  ExportSource
+	// mu serializes read-modify-write updates of client lists
+	mu sync.Mutex
+}
+
+func NewManager(executor *command.CommandExecutor, exports ExportSource) *Manager {
+	return &Manager{executor: executor, exports: exports}
+}
+
+// shareProperties is the subset of zfs get -j output used for shares
+type shareProperties struct {
+	Datasets map[string]struct {
+		Properties map[string]struct {
+			Value  string `json:"value"`
+			Source struct {
+				Type string `json:"type"`
+				Data string `json:"data"`
+			} `json:"source"`
+		} `json:"properties"`
+	} `json:"datasets"`
+}
+
+// List returns all NFS shared filesystems with their live export state
+func (m *Manager) List(ctx context.Context) ([]Share, error) {
+	return m.list(ctx, "")
+}
+
+// Get returns the NFS share of a filesystem
+func (m *Manager) Get(ctx context.Context, dataset string) (*Share, error) {
+	shares, err := m.list(ctx, dataset)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range shares {
+		if s.Dataset == dataset {
+			return &s, nil
+		}
+	}
+	return nil, errors.New(errors.ShareNotFound, dataset)
+}
+
+// Set creates or replaces the NFS share of a filesystem. Preserved options
+// are only ever read from sharenfs, never taken from the caller.
+func (m *Manager) Set(ctx context.Context, share Share) (*Share, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	share.Options.Preserved = nil
+
+	if err := m.setProperty(ctx, share); err != nil {
+		return nil, err
+	}
+	return m.Get(ctx, share.Dataset)
+}
+
+// Delete stops sharing a filesystem over NFS
+func (m *Manager) Delete(ctx context.Context, dataset string) error {
+	if dataset == "" {
+		return errors.New(errors.ShareInvalidConfig, "dataset is required")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.zfsSet(ctx, dataset, "off")
+}
+
+// SetClient adds a client to a share or changes its access
+func (m *Manager) SetClient(ctx context.Context, dataset string, client Client) (*Share, error) {
+	return m.update(ctx, dataset, func(s *Share) error {
+		for i := range s.Clients {
+			if s.Clients[i].Host == client.Host {
+				s.Clients[i].Access = client.Access
+				return nil
+			}
+		}
+		s.Clients = append(s.Clients, client)
+		return nil
+	})
+}
+
+// RemoveClient removes a client from a share. The last client cannot be
+// removed; delete the share instead.
+func (m *Manager) RemoveClient(ctx context.Context, dataset, host string) (*Share, error) {
+	return m.update(ctx, dataset, func(s *Share) error {
+		for i := range s.Clients {
+			if s.Clients[i].Host == host {
+				s.Clients = append(s.Clients[:i], s.Clients[i+1:]...)
+				return nil
+			}
+		}
+		return errors.New(errors.ShareNotFound, "client not found").WithMetadata("host", host)
+	})
+}
+
+// update applies fn to the current share and writes the result back
+func (m *Manager) update(ctx context.Context, dataset string, fn func(*Share) error) (*Share, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	share, err := m.Get(ctx, dataset)
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(share); err != nil {
+		return nil, err
+	}
+	if err := m.setProperty(ctx, *share); err != nil {
+		return nil, err
+	}
+	return m.Get(ctx, dataset)
+}
+
+func (m *Manager) setProperty(ctx context.Context, share Share) error {
+	if share.Dataset == "" {
+		return errors.New(errors.ShareInvalidConfig, "dataset is required")
+	}
+	value, err := Render(share)
+	if err != nil {
+		return err
+	}
+	return m.zfsSet(ctx, share.Dataset, value)
+}
+
+func (m *Manager) zfsSet(ctx context.Context, dataset, value string) error {
+	args := []string{"set", "sharenfs=" + value, dataset}
+
+	out, err := m.executor.Execute(ctx, command.CommandOptions{}, "zfs set", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return errors.Wrap(err, errors.ShareOperation).
+				WithMetadata("output", string(out))
+		}
+		return errors.Wrap(err, errors.ShareOperation)
+	}
+	return nil
+}
+
+// list reads sharenfs and mountpoint of one or all filesystems and joins
+// them with the export table
+func (m *Manager) list(ctx context.Context, dataset string) ([]Share, error) {
+	args := []string{"get", "-p", "-t", "filesystem", "sharenfs,mountpoint"}
+	if dataset != "" {
+		args = append(args, dataset)
+	}
+
+	out, err := m.executor.Execute(ctx, command.CommandOptions{Flags: command.FlagJSON}, "zfs get", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return nil, errors.Wrap(err, errors.ShareOperation).
+				WithMetadata("output", string(out))
+		}
+		return nil, errors.Wrap(err, errors.ShareOperation)
+	}
+
+	var props shareProperties
+	if err := json.Unmarshal(out, &props); err != nil {
+		return nil, errors.Wrap(err, errors.CommandOutputParse)
+	}
+
+	var exports []Export
+	if m.exports != nil {
+		if exports, err = m.exports.Exports(); err != nil {
+			return nil, errors.Wrap(err, errors.ShareOperation)
+		}
+	}
+
+	shares := []Share{}
+	for name, ds := range props.Datasets {
+		prop := ds.Properties["sharenfs"]
+		share, ok := Parse(prop.Value)
+		if !ok {
+			continue
+		}
+		share.Dataset = name
+		share.Property = prop.Value
+		share.Inherited = prop.Source.Type == "INHERITED"
+		share.MountPoint = ds.Properties["mountpoint"].Value
+		for _, e := range exports {
+			if e.Path == share.MountPoint {
+				share.Exports = append(share.Exports, e)
+			}
+		}
+		shares = append(shares, share)
+	}
+
+	sort.Slice(shares, func(i, j int) bool { return shares[i].Dataset < shares[j].Dataset })
+	return shares, nil
+}
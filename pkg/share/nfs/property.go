@@ -0,0 +1,370 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nfs
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+// maxClients bounds the access list, sharenfs has to fit in a property
+const maxClients = 64
+
+var (
+	// Hostnames with optional wildcards, e.g. nfs1, *.example.com, web?
+	hostnameRegex = regexp.MustCompile(`^[a-zA-Z0-9*?]([a-zA-Z0-9*?.-]*[a-zA-Z0-9*?])?$`)
+
+	validSec = map[string]bool{"sys": true, "krb5": true, "krb5i": true, "krb5p": true}
+
+	// Options sharenfs accepts on Linux besides ro, rw and sec that are kept
+	// in Options.Extra when they are not modelled
+	passthroughOptions = map[string]bool{
+		"no_subtree_check": true, "subtree_check": true,
+		"sync": true, "wdelay": true, "no_wdelay": true,
+		"hide": true, "nohide": true, "secure": true,
+		"secure_locks": true, "insecure_locks": true,
+		"no_acl": true, "auth_nlm": true, "no_auth_nlm": true,
+		"root_squash": true, "no_all_squash": true,
+		"fsid": true, "mountpoint": true, "mp": true, "refer": true, "replicas": true,
+	}
+
+	// fsid is root, a number or a UUID
+	fsidRegex = regexp.MustCompile(
+		`^(root|[0-9]+|[0-9a-fA-F]{8}(-?[0-9a-fA-F]{4}){3}-?[0-9a-fA-F]{12})$`,
+	)
+)
+
+// Validate checks a share before it is rendered into sharenfs
+func Validate(s Share) error {
+	if len(s.Clients) == 0 {
+		return errors.New(errors.ShareInvalidConfig, "at least one client is required")
+	}
+	if len(s.Clients) > maxClients {
+		return errors.New(errors.ShareInvalidConfig,
+			fmt.Sprintf("at most %d clients are allowed", maxClients))
+	}
+
+	seen := make(map[string]bool)
+	for _, c := range s.Clients {
+		if err := validateHost(c.Host); err != nil {
+			return err
+		}
+		if c.Access != AccessReadOnly && c.Access != AccessReadWrite {
+			return errors.New(errors.ShareInvalidConfig, "client access must be ro or rw").
+				WithMetadata("host", c.Host)
+		}
+		if seen[c.Host] {
+			return errors.New(errors.ShareInvalidConfig, "client listed more than once").
+				WithMetadata("host", c.Host)
+		}
+		seen[c.Host] = true
+	}
+
+	switch s.Options.Squash {
+	case "", SquashRoot, SquashNone, SquashAll:
+	default:
+		return errors.New(errors.ShareInvalidConfig, "squash must be root, none or all")
+	}
+	for _, sec := range s.Options.Sec {
+		if !validSec[sec] {
+			return errors.New(errors.ShareInvalidConfig,
+				"sec must be one of sys, krb5, krb5i, krb5p").WithMetadata("sec", sec)
+		}
+	}
+	for _, id := range []*int{s.Options.AnonUID, s.Options.AnonGID} {
+		if id != nil && *id < 0 {
+			return errors.New(errors.ShareInvalidConfig, "anonymous uid and gid cannot be negative")
+		}
+	}
+	for _, opt := range s.Options.Extra {
+		if err := validateExtra(opt); err != nil {
+			return err.WithMetadata("option", opt)
+		}
+	}
+	// Preserved options come from Parse and are kept as read, but must not
+	// break out of their slot in the option list
+	for _, opt := range s.Options.Preserved {
+		if !validOptionChars(opt) {
+			return errors.New(errors.ShareInvalidConfig, "invalid character in sharenfs option").
+				WithMetadata("option", opt)
+		}
+	}
+	return nil
+}
+
+// validateExtra checks a passthrough option and its value
+func validateExtra(opt string) *errors.RodentError {
+	if !validOptionChars(opt) {
+		return errors.New(errors.ShareInvalidConfig, "invalid character in sharenfs option")
+	}
+
+	key, val, hasVal := strings.Cut(opt, "=")
+	if !passthroughOptions[key] {
+		return errors.New(errors.ShareInvalidConfig, "unsupported sharenfs option")
+	}
+
+	switch key {
+	case "fsid":
+		if !fsidRegex.MatchString(val) {
+			return errors.New(errors.ShareInvalidConfig, "fsid must be root, a number or a UUID")
+		}
+	case "mountpoint", "mp":
+		if hasVal && !strings.HasPrefix(val, "/") {
+			return errors.New(errors.ShareInvalidConfig, "mountpoint must be an absolute path")
+		}
+	case "refer", "replicas":
+		if !validLocations(val) {
+			return errors.New(errors.ShareInvalidConfig,
+				"locations must be path@host[+host] separated by colons")
+		}
+	default:
+		if hasVal {
+			return errors.New(errors.ShareInvalidConfig, "sharenfs option takes no value")
+		}
+	}
+	return nil
+}
+
+// validOptionChars rejects the option separator, whitespace and control
+// characters
+func validOptionChars(opt string) bool {
+	if opt == "" {
+		return false
+	}
+	for _, r := range opt {
+		if r == ',' || unicode.IsSpace(r) || unicode.IsControl(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// validLocations checks the value of refer and replicas:
+// /export@nfs1+nfs2:/backup@10.0.0.5
+func validLocations(val string) bool {
+	if val == "" {
+		return false
+	}
+	for _, loc := range strings.Split(val, ":") {
+		path, hosts, ok := strings.Cut(loc, "@")
+		if !ok || !strings.HasPrefix(path, "/") || hosts == "" {
+			return false
+		}
+		for _, host := range strings.Split(hosts, "+") {
+			if host == "*" || validateHost(host) != nil {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func validateHost(host string) error {
+	if host == "*" {
+		return nil
+	}
+	if addr, mask, ok := strings.Cut(host, "/"); ok {
+		if _, _, err := net.ParseCIDR(host); err != nil || net.ParseIP(addr) == nil || mask == "" {
+			return errors.New(errors.ShareInvalidConfig, "invalid client network").
+				WithMetadata("host", host)
+		}
+		return nil
+	}
+	if net.ParseIP(host) != nil || hostnameRegex.MatchString(host) {
+		return nil
+	}
+	return errors.New(errors.ShareInvalidConfig, "invalid client host").
+		WithMetadata("host", host)
+}
+
+// Render validates a share and renders it into sharenfs property syntax:
+// rw=@10.0.0.0/24:nfs1,ro=*,no_root_squash,sec=krb5p:sys
+func Render(s Share) (string, error) {
+	if err := Validate(s); err != nil {
+		return "", err
+	}
+
+	var rw, ro []string
+	for _, c := range s.Clients {
+		if c.Access == AccessReadWrite {
+			rw = append(rw, renderHost(c.Host))
+		} else {
+			ro = append(ro, renderHost(c.Host))
+		}
+	}
+
+	var opts []string
+	if len(rw) > 0 {
+		opts = append(opts, "rw="+strings.Join(rw, ":"))
+	}
+	if len(ro) > 0 {
+		opts = append(opts, "ro="+strings.Join(ro, ":"))
+	}
+
+	o := s.Options
+	switch o.Squash {
+	case SquashNone:
+		opts = append(opts, "no_root_squash")
+	case SquashAll:
+		opts = append(opts, "all_squash")
+	}
+	if len(o.Sec) > 0 {
+		opts = append(opts, "sec="+strings.Join(o.Sec, ":"))
+	}
+	if o.AnonUID != nil {
+		opts = append(opts, "anonuid="+strconv.Itoa(*o.AnonUID))
+	}
+	if o.AnonGID != nil {
+		opts = append(opts, "anongid="+strconv.Itoa(*o.AnonGID))
+	}
+	if o.Async {
+		opts = append(opts, "async")
+	}
+	if o.Insecure {
+		opts = append(opts, "insecure")
+	}
+	if o.Crossmnt {
+		opts = append(opts, "crossmnt")
+	}
+	opts = append(opts, o.Extra...)
+	opts = append(opts, o.Preserved...)
+
+	return strings.Join(opts, ","), nil
+}
+
+// renderHost writes networks with the @ prefix libshare expects and wraps
+// IPv6 addresses in brackets, since host lists are colon separated
+func renderHost(host string) string {
+	addr, mask, isNet := strings.Cut(host, "/")
+	if strings.Contains(addr, ":") {
+		addr = "[" + addr + "]"
+	}
+	if isNet {
+		return "@" + addr + "/" + mask
+	}
+	return addr
+}
+
+// Parse reads a sharenfs value into the clients and options of a share.
+// "on" shares read-write with everyone; "off" and "" are not shares.
+func Parse(value string) (Share, bool) {
+	var s Share
+	switch value {
+	case "", "off", "-":
+		return s, false
+	case "on":
+		s.Clients = []Client{{Host: "*", Access: AccessReadWrite}}
+		return s, true
+	}
+
+	for _, opt := range splitOptions(value) {
+		key, val, _ := strings.Cut(opt, "=")
+		switch key {
+		case "rw", "ro":
+			if val == "" {
+				// Bare ro/rw applies to everyone
+				s.Clients = append(s.Clients, Client{Host: "*", Access: key})
+				continue
+			}
+			for _, host := range splitHosts(val) {
+				s.Clients = append(s.Clients, Client{Host: parseHost(host), Access: key})
+			}
+		case "no_root_squash":
+			s.Options.Squash = SquashNone
+		case "all_squash":
+			s.Options.Squash = SquashAll
+		case "sec":
+			s.Options.Sec = strings.Split(val, ":")
+		case "anonuid", "anongid":
+			id, err := strconv.Atoi(val)
+			if err != nil {
+				s.Options.Preserved = append(s.Options.Preserved, opt)
+				continue
+			}
+			if key == "anonuid" {
+				s.Options.AnonUID = &id
+			} else {
+				s.Options.AnonGID = &id
+			}
+		case "async":
+			s.Options.Async = true
+		case "insecure":
+			s.Options.Insecure = true
+		case "crossmnt":
+			s.Options.Crossmnt = true
+		default:
+			// Options that can't be sent back through Extra, such as
+			// root=host, are kept as they are
+			if validateExtra(opt) == nil {
+				s.Options.Extra = append(s.Options.Extra, opt)
+			} else {
+				s.Options.Preserved = append(s.Options.Preserved, opt)
+			}
+		}
+	}
+	if len(s.Clients) == 0 {
+		// Options without an access list share read-write with everyone
+		s.Clients = []Client{{Host: "*", Access: AccessReadWrite}}
+	}
+	return s, true
+}
+
+// splitOptions splits on commas outside of IPv6 brackets
+func splitOptions(value string) []string {
+	return splitOutsideBrackets(value, ',')
+}
+
+// splitHosts splits a host list on colons outside of IPv6 brackets
+func splitHosts(value string) []string {
+	return splitOutsideBrackets(value, ':')
+}
+
+func splitOutsideBrackets(value string, sep rune) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range value {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case sep:
+			if depth == 0 {
+				if i > start {
+					parts = append(parts, value[start:i])
+				}
+				start = i + 1
+			}
+		}
+	}
+	if start < len(value) {
+		parts = append(parts, value[start:])
+	}
+	return parts
+}
+
+func parseHost(host string) string {
+	host = strings.TrimPrefix(host, "@")
+	return strings.NewReplacer("[", "", "]", "").Replace(host)
+}
@@ -0,0 +1,245 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nfs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func intPtr(i int) *int { return &i }
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		share   Share
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "networks, hosts and options",
+			share: Share{
+				Clients: []Client{
+					{Host: "10.0.0.0/24", Access: AccessReadWrite},
+					{Host: "backup.example.com", Access: AccessReadWrite},
+					{Host: "*", Access: AccessReadOnly},
+				},
+				Options: Options{
+					Squash:   SquashNone,
+					Sec:      []string{"krb5p", "sys"},
+					AnonUID:  intPtr(65534),
+					Crossmnt: true,
+				},
+			},
+			want: "rw=@10.0.0.0/24:backup.example.com,ro=*,no_root_squash,sec=krb5p:sys,anonuid=65534,crossmnt",
+		},
+		{
+			name: "ipv6",
+			share: Share{Clients: []Client{
+				{Host: "fd00::/64", Access: AccessReadOnly},
+				{Host: "::1", Access: AccessReadOnly},
+			}},
+			want: "ro=@[fd00::]/64:[::1]",
+		},
+		{
+			name:    "no clients",
+			share:   Share{},
+			wantErr: true,
+		},
+		{
+			name:    "bad access",
+			share:   Share{Clients: []Client{{Host: "*", Access: "rx"}}},
+			wantErr: true,
+		},
+		{
+			name:    "bad host",
+			share:   Share{Clients: []Client{{Host: "host,rw", Access: AccessReadOnly}}},
+			wantErr: true,
+		},
+		{
+			name:    "bad network",
+			share:   Share{Clients: []Client{{Host: "10.0.0.0/33", Access: AccessReadOnly}}},
+			wantErr: true,
+		},
+		{
+			name: "duplicate host",
+			share: Share{Clients: []Client{
+				{Host: "nfs1", Access: AccessReadOnly},
+				{Host: "nfs1", Access: AccessReadWrite},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "bad sec",
+			share: Share{
+				Clients: []Client{{Host: "*", Access: AccessReadOnly}},
+				Options: Options{Sec: []string{"none"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported extra option",
+			share: Share{
+				Clients: []Client{{Host: "*", Access: AccessReadOnly}},
+				Options: Options{Extra: []string{"root=host"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid extra values",
+			share: Share{
+				Clients: []Client{{Host: "*", Access: AccessReadOnly}},
+				Options: Options{Extra: []string{
+					"fsid=6f9619ff-8b86-d011-b42d-00c04fc964ff",
+					"mountpoint",
+					"refer=/export@nfs1+10.0.0.5:/backup@nfs2",
+				}},
+			},
+			want: "ro=*,fsid=6f9619ff-8b86-d011-b42d-00c04fc964ff,mountpoint,refer=/export@nfs1+10.0.0.5:/backup@nfs2",
+		},
+		{
+			name: "extra option smuggling a separator",
+			share: Share{
+				Clients: []Client{{Host: "*", Access: AccessReadOnly}},
+				Options: Options{Extra: []string{"sync,rw=*"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "extra option with whitespace",
+			share: Share{
+				Clients: []Client{{Host: "*", Access: AccessReadOnly}},
+				Options: Options{Extra: []string{"mp=/mnt/a b"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad fsid",
+			share: Share{
+				Clients: []Client{{Host: "*", Access: AccessReadOnly}},
+				Options: Options{Extra: []string{"fsid=abc"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad replicas",
+			share: Share{
+				Clients: []Client{{Host: "*", Access: AccessReadOnly}},
+				Options: Options{Extra: []string{"replicas=export@nfs1"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "value on a flag",
+			share: Share{
+				Clients: []Client{{Host: "*", Access: AccessReadOnly}},
+				Options: Options{Extra: []string{"sync=yes"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.share)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	share := Share{
+		Clients: []Client{
+			{Host: "10.0.0.0/24", Access: AccessReadWrite},
+			{Host: "fd00::/64", Access: AccessReadWrite},
+			{Host: "*.example.com", Access: AccessReadOnly},
+		},
+		Options: Options{
+			Squash:  SquashAll,
+			AnonUID: intPtr(1000),
+			AnonGID: intPtr(1000),
+			Async:   true,
+			Extra:   []string{"no_subtree_check"},
+		},
+	}
+	value, err := Render(share)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	parsed, ok := Parse(value)
+	if !ok {
+		t.Fatalf("Parse(%q) reported no share", value)
+	}
+	if !reflect.DeepEqual(parsed.Clients, share.Clients) {
+		t.Errorf("clients = %+v, want %+v", parsed.Clients, share.Clients)
+	}
+	if !reflect.DeepEqual(parsed.Options, share.Options) {
+		t.Errorf("options = %+v, want %+v", parsed.Options, share.Options)
+	}
+}
+
+func TestParsePreservesUnsupportedOptions(t *testing.T) {
+	parsed, ok := Parse("rw=nfs1,root=nfs1,anonuid=nobody,no_subtree_check")
+	if !ok {
+		t.Fatal("Parse reported no share")
+	}
+	if want := []string{"no_subtree_check"}; !reflect.DeepEqual(parsed.Options.Extra, want) {
+		t.Errorf("extra = %v, want %v", parsed.Options.Extra, want)
+	}
+	if want := []string{"root=nfs1", "anonuid=nobody"}; !reflect.DeepEqual(parsed.Options.Preserved, want) {
+		t.Errorf("preserved = %v, want %v", parsed.Options.Preserved, want)
+	}
+
+	// A client update renders the share again and must keep them
+	parsed.Clients = append(parsed.Clients, Client{Host: "nfs2", Access: AccessReadOnly})
+	value, err := Render(parsed)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if want := "rw=nfs1,ro=nfs2,no_subtree_check,root=nfs1,anonuid=nobody"; value != want {
+		t.Errorf("Render() = %q, want %q", value, want)
+	}
+}
+
+func TestParseSpecialValues(t *testing.T) {
+	for _, value := range []string{"", "off", "-"} {
+		if _, ok := Parse(value); ok {
+			t.Errorf("Parse(%q) should not be a share", value)
+		}
+	}
+
+	for _, value := range []string{"on", "no_root_squash"} {
+		s, ok := Parse(value)
+		if !ok || len(s.Clients) != 1 || s.Clients[0].Host != "*" ||
+			s.Clients[0].Access != AccessReadWrite {
+			t.Errorf("Parse(%q) = %+v, want read-write for everyone", value, s)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nfs
+
+// Client access modes
+const (
+	AccessReadOnly  = "ro"
+	AccessReadWrite = "rw"
+)
+
+// Root squash modes
+const (
+	// SquashRoot maps requests from uid/gid 0 to the anonymous user
+	SquashRoot = "root"
+	// SquashNone trusts root on the client
+	SquashNone = "none"
+	// SquashAll maps every user to the anonymous user
+	SquashAll = "all"
+)
+
+// Share is the NFS export of a filesystem, stored in its sharenfs property
+type Share struct {
+	Dataset string `json:"dataset" binding:"required"`
+	// Clients is the access list of the export. A host is a hostname, a
+	// wildcard such as *.example.com, an IP address, a network in CIDR
+	// notation or * for everyone.
+	Clients []Client `json:"clients" binding:"required"`
+	Options Options  `json:"options"`
+
+	// Read-only fields reported by List and Get
+	MountPoint string `json:"mountpoint,omitempty"`
+	// Property is the sharenfs value the share is stored as
+	Property string `json:"sharenfs,omitempty"`
+	// Inherited is set when sharenfs is inherited from a parent dataset
+	Inherited bool `json:"inherited,omitempty"`
+	// Exports is the live state of the export in the kernel export table
+	Exports []Export `json:"exports,omitempty"`
+}
+
+// Client grants a host access to a share
+type Client struct {
+	Host   string `json:"host"   binding:"required"`
+	Access string `json:"access" binding:"required"`
+}
+
+// Options apply to all clients of a share
+type Options struct {
+	// Squash is root (default), none or all
+	Squash string `json:"squash,omitempty"`
+	// Sec lists the security flavors: sys, krb5, krb5i, krb5p
+	Sec     []string `json:"sec,omitempty"`
+	AnonUID *int     `json:"anonuid,omitempty"`
+	AnonGID *int     `json:"anongid,omitempty"`
+	// Async replies before writes reach stable storage
+	Async bool `json:"async,omitempty"`
+	// Insecure allows requests from ports above 1023
+	Insecure bool `json:"insecure,omitempty"`
+	// Crossmnt exports child filesystems mounted below the share
+	Crossmnt bool `json:"crossmnt,omitempty"`
+	// Extra holds sharenfs options Rodent does not model, kept as is
+	Extra []string `json:"extra,omitempty"`
+	// Preserved holds options read from sharenfs that are not accepted in
+	// Extra, such as root=host. Client updates keep them; Set drops them.
+	Preserved []string `json:"preserved,omitempty"`
+}
+
+// Export is an entry of the live export table for one client
+type Export struct {
+	Path    string   `json:"path"`
+	Host    string   `json:"host"`
+	Options []string `json:"options"`
+}
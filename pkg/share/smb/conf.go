@@ -0,0 +1,290 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package smb
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+const (
+	includeHeader = "# Generated by Rodent. Manual changes are overwritten.\n"
+	datasetMarker = "# dataset:"
+
+	maxNameLength    = 80
+	maxCommentLength = 256
+	maxValidUsers    = 64
+)
+
+var (
+	nameRegex = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.$ -]*$`)
+	// userRegex matches a user, an optional DOMAIN\ prefix and the @, + and &
+	// group markers of valid users
+	userRegex = regexp.MustCompile(`^[@+&]{0,2}([A-Za-z0-9._-]+\\)?[A-Za-z0-9._$-]+$`)
+
+	// reservedNames are sections with a meaning of their own in smb.conf
+	reservedNames = map[string]bool{
+		"global":   true,
+		"homes":    true,
+		"printers": true,
+		"print$":   true,
+		"ipc$":     true,
+	}
+)
+
+// ValidateName checks a share name is usable as an smb.conf section
+func ValidateName(name string) error {
+	if name == "" {
+		return errors.New(errors.ShareInvalidConfig, "share name is required")
+	}
+	if len(name) > maxNameLength {
+		return errors.New(errors.ShareInvalidConfig, "share name is too long").
+			WithMetadata("name", name)
+	}
+	if !nameRegex.MatchString(name) || strings.HasSuffix(name, " ") {
+		return errors.New(errors.ShareInvalidConfig, "share name contains invalid characters").
+			WithMetadata("name", name)
+	}
+	if reservedNames[strings.ToLower(name)] {
+		return errors.New(errors.ShareInvalidConfig, "share name is reserved").
+			WithMetadata("name", name)
+	}
+	return nil
+}
+
+// Validate checks a share can be rendered safely into smb.conf
+func Validate(share Share) error {
+	if err := ValidateName(share.Name); err != nil {
+		return err
+	}
+	if share.Dataset == "" {
+		return errors.New(errors.ShareInvalidConfig, "dataset is required")
+	}
+	if err := checkValue("comment", share.Comment); err != nil {
+		return err
+	}
+	if len(share.Comment) > maxCommentLength {
+		return errors.New(errors.ShareInvalidConfig, "comment is too long")
+	}
+
+	if len(share.ValidUsers) > maxValidUsers {
+		return errors.New(errors.ShareInvalidConfig,
+			fmt.Sprintf("at most %d valid users are supported", maxValidUsers))
+	}
+	for _, user := range share.ValidUsers {
+		if !userRegex.MatchString(user) {
+			return errors.New(errors.ShareInvalidConfig, "invalid user or group").
+				WithMetadata("user", user)
+		}
+	}
+	if share.GuestOK && len(share.ValidUsers) > 0 {
+		return errors.New(errors.ShareInvalidConfig,
+			"guest access can't be combined with valid users")
+	}
+
+	if share.ShadowCopy != nil && share.ShadowCopy.Format != "" {
+		if err := checkValue("shadow copy format", share.ShadowCopy.Format); err != nil {
+			return err
+		}
+		if !strings.Contains(share.ShadowCopy.Format, "%") {
+			return errors.New(errors.ShareInvalidConfig,
+				"shadow copy format must contain strftime conversions").
+				WithMetadata("format", share.ShadowCopy.Format)
+		}
+	}
+
+	if share.Path != "" {
+		if err := checkValue("path", share.Path); err != nil {
+			return err
+		}
+		if !filepath.IsAbs(share.Path) {
+			return errors.New(errors.ShareInvalidConfig, "path must be absolute").
+				WithMetadata("path", share.Path)
+		}
+	}
+	return nil
+}
+
+// checkValue rejects values that would break out of their smb.conf line:
+// control characters start new lines and a trailing backslash continues
+// the line
+func checkValue(field, value string) error {
+	for _, r := range value {
+		if unicode.IsControl(r) {
+			return errors.New(errors.ShareInvalidConfig,
+				field+" contains control characters")
+		}
+	}
+	if strings.HasSuffix(value, "\\") {
+		return errors.New(errors.ShareInvalidConfig,
+			field+" can't end with a backslash")
+	}
+	return nil
+}
+
+// Render generates the include file for the shares, ordered by name.
+// defaultFormat is the shadow copy format of shares not setting one.
+func Render(shares []Share, defaultFormat string) ([]byte, error) {
+	sorted := make([]Share, len(shares))
+	copy(sorted, shares)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var b bytes.Buffer
+	b.WriteString(includeHeader)
+
+	seen := make(map[string]bool)
+	for _, share := range sorted {
+		if err := Validate(share); err != nil {
+			return nil, err
+		}
+		if share.Path == "" {
+			return nil, errors.New(errors.ShareInvalidConfig, "share has no path").
+				WithMetadata("name", share.Name)
+		}
+		key := strings.ToLower(share.Name)
+		if seen[key] {
+			return nil, errors.New(errors.ShareExists, share.Name)
+		}
+		seen[key] = true
+
+		fmt.Fprintf(&b, "\n[%s]\n", share.Name)
+		fmt.Fprintf(&b, "\t%s %s\n", datasetMarker, share.Dataset)
+		writeParam(&b, "path", share.Path)
+		if share.Comment != "" {
+			writeParam(&b, "comment", share.Comment)
+		}
+		if len(share.ValidUsers) > 0 {
+			writeParam(&b, "valid users", strings.Join(share.ValidUsers, " "))
+		}
+		writeParam(&b, "read only", yesNo(share.ReadOnly))
+		if share.Hidden {
+			writeParam(&b, "browseable", "no")
+		}
+		if share.GuestOK {
+			writeParam(&b, "guest ok", "yes")
+		}
+		if share.ShadowCopy != nil {
+			format := share.ShadowCopy.Format
+			if format == "" {
+				format = defaultFormat
+			}
+			writeParam(&b, "vfs objects", "shadow_copy2")
+			writeParam(&b, "shadow:snapdir", ".zfs/snapshot")
+			writeParam(&b, "shadow:sort", "desc")
+			writeParam(&b, "shadow:format", format)
+			if share.ShadowCopy.Localtime {
+				writeParam(&b, "shadow:localtime", "yes")
+			}
+		}
+	}
+	return b.Bytes(), nil
+}
+
+func writeParam(b *bytes.Buffer, key, value string) {
+	fmt.Fprintf(b, "\t%s = %s\n", key, value)
+}
+
+func yesNo(v bool) string {
+	if v {
+		return "yes"
+	}
+	return "no"
+}
+
+// Parse reads the shares back from an include file generated by Render
+func Parse(data []byte) ([]Share, error) {
+	shares := []Share{}
+	var current *Share
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			shares = append(shares, Share{Name: line[1 : len(line)-1]})
+			current = &shares[len(shares)-1]
+			continue
+		case current == nil:
+			continue
+		case strings.HasPrefix(line, datasetMarker):
+			current.Dataset = strings.TrimSpace(strings.TrimPrefix(line, datasetMarker))
+			continue
+		case strings.HasPrefix(line, "#"), strings.HasPrefix(line, ";"):
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, errors.New(errors.ShareOperation, "malformed line in share configuration").
+				WithMetadata("line", line)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "path":
+			current.Path = value
+		case "comment":
+			current.Comment = value
+		case "valid users":
+			current.ValidUsers = strings.Fields(value)
+		case "read only":
+			current.ReadOnly = isYes(value)
+		case "browseable":
+			current.Hidden = !isYes(value)
+		case "guest ok":
+			current.GuestOK = isYes(value)
+		case "vfs objects":
+			for _, obj := range strings.Fields(value) {
+				if obj == "shadow_copy2" && current.ShadowCopy == nil {
+					current.ShadowCopy = &ShadowCopy{}
+				}
+			}
+		case "shadow:format":
+			if current.ShadowCopy != nil {
+				current.ShadowCopy.Format = value
+			}
+		case "shadow:localtime":
+			if current.ShadowCopy != nil {
+				current.ShadowCopy.Localtime = isYes(value)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ShareOperation)
+	}
+	return shares, nil
+}
+
+func isYes(value string) bool {
+	switch strings.ToLower(value) {
+	case "yes", "true", "1", "on":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package smb
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		share   Share
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			share: Share{
+				Name:       "Team Data",
+				Dataset:    "tank/data",
+				ValidUsers: []string{"alice", "@staff", `CORP\bob`},
+				ShadowCopy: &ShadowCopy{Format: "auto-%Y%m%d"},
+			},
+		},
+		{name: "no name", share: Share{Dataset: "tank/data"}, wantErr: true},
+		{name: "reserved name", share: Share{Name: "Global", Dataset: "tank/data"}, wantErr: true},
+		{name: "section injection", share: Share{Name: "a]\n[b", Dataset: "tank/data"}, wantErr: true},
+		{name: "trailing space", share: Share{Name: "data ", Dataset: "tank/data"}, wantErr: true},
+		{name: "no dataset", share: Share{Name: "data"}, wantErr: true},
+		{
+			name:    "comment with newline",
+			share:   Share{Name: "data", Dataset: "tank/data", Comment: "x\npath = /"},
+			wantErr: true,
+		},
+		{
+			name:    "comment continuing the line",
+			share:   Share{Name: "data", Dataset: "tank/data", Comment: `x\`},
+			wantErr: true,
+		},
+		{
+			name:    "user with space",
+			share:   Share{Name: "data", Dataset: "tank/data", ValidUsers: []string{"alice bob"}},
+			wantErr: true,
+		},
+		{
+			name: "guest with users",
+			share: Share{
+				Name: "data", Dataset: "tank/data", GuestOK: true, ValidUsers: []string{"alice"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "format without conversions",
+			share: Share{
+				Name: "data", Dataset: "tank/data", ShadowCopy: &ShadowCopy{Format: "daily"},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "relative path",
+			share:   Share{Name: "data", Dataset: "tank/data", Path: "tank/data"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.share)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderParse(t *testing.T) {
+	shares := []Share{
+		{
+			Name:       "media",
+			Dataset:    "tank/media",
+			Path:       "/tank/media",
+			ReadOnly:   true,
+			Hidden:     true,
+			GuestOK:    true,
+			ShadowCopy: &ShadowCopy{},
+		},
+		{
+			Name:       "data",
+			Dataset:    "tank/data",
+			Path:       "/mnt/data dir",
+			Comment:    "Team data",
+			ValidUsers: []string{"alice", "@staff"},
+			ShadowCopy: &ShadowCopy{Format: "auto-%Y-%m-%d_%H.%M", Localtime: true},
+		},
+	}
+
+	data, err := Render(shares, DefaultShadowFormat)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	want := includeHeader + `
+[data]
+	# dataset: tank/data
+	path = /mnt/data dir
+	comment = Team data
+	valid users = alice @staff
+	read only = no
+	vfs objects = shadow_copy2
+	shadow:snapdir = .zfs/snapshot
+	shadow:sort = desc
+	shadow:format = auto-%Y-%m-%d_%H.%M
+	shadow:localtime = yes
+
+[media]
+	# dataset: tank/media
+	path = /tank/media
+	read only = yes
+	browseable = no
+	guest ok = yes
+	vfs objects = shadow_copy2
+	shadow:snapdir = .zfs/snapshot
+	shadow:sort = desc
+	shadow:format = @GMT-%Y.%m.%d-%H.%M.%S
+`
+	if string(data) != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", data, want)
+	}
+
+	got, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	wantShares := []Share{shares[1], shares[0]}
+	wantShares[1].ShadowCopy = &ShadowCopy{Format: DefaultShadowFormat}
+	if !reflect.DeepEqual(got, wantShares) {
+		t.Errorf("Parse() = %+v, want %+v", got, wantShares)
+	}
+}
+
+func TestRenderDuplicate(t *testing.T) {
+	shares := []Share{
+		{Name: "data", Dataset: "tank/a", Path: "/tank/a"},
+		{Name: "DATA", Dataset: "tank/b", Path: "/tank/b"},
+	}
+	if _, err := Render(shares, DefaultShadowFormat); err == nil ||
+		!strings.Contains(err.Error(), "exists") {
+		t.Errorf("Render() error = %v, want share exists", err)
+	}
+}
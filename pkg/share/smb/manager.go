@@ -0,0 +1,282 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package smb
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/zfs/command"
+)
+
+// Manager manages Samba shares of filesystems. Shares are kept as stanzas
+// of an include file which is rewritten as a whole, checked with testparm
+// when available, and put in place with a rename before smbd is reloaded.
+// Writing the include file and reloading smbd go through the runner, which
+// runs them with sudo in the server.
+//
+// The sharesmb property is not used: its shares can't be given users or
+// VFS modules without editing smb.conf by hand anyway.
+type Manager struct {
+	executor *command.CommandExecutor
+	runner   Runner
+	cfg      Config
+	// mu serializes rewrites of the include file
+	mu sync.Mutex
+
+	// mountpoint resolves the mountpoint of a dataset
+	mountpoint func(ctx context.Context, dataset string) (string, error)
+}
+
+func NewManager(executor *command.CommandExecutor, runner Runner, cfg Config) *Manager {
+	defaults := DefaultConfig()
+	if cfg.IncludeFile == "" {
+		cfg.IncludeFile = defaults.IncludeFile
+	}
+	if cfg.MainConfig == "" {
+		cfg.MainConfig = defaults.MainConfig
+	}
+	if cfg.ShadowFormat == "" {
+		cfg.ShadowFormat = defaults.ShadowFormat
+	}
+	m := &Manager{executor: executor, runner: runner, cfg: cfg}
+	m.mountpoint = m.zfsMountpoint
+	return m
+}
+
+// Status reports whether Samba is installed and serving the shares
+func (m *Manager) Status(ctx context.Context) Status {
+	return detect(ctx, m.runner, m.cfg)
+}
+
+// List returns all SMB shares
+func (m *Manager) List(ctx context.Context) ([]Share, error) {
+	return m.load()
+}
+
+// Get returns an SMB share by name
+func (m *Manager) Get(ctx context.Context, name string) (*Share, error) {
+	shares, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	if i := find(shares, name); i >= 0 {
+		return &shares[i], nil
+	}
+	return nil, errors.New(errors.ShareNotFound, name)
+}
+
+// Create adds an SMB share
+func (m *Manager) Create(ctx context.Context, share Share) (*Share, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	shares, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	if find(shares, share.Name) >= 0 {
+		return nil, errors.New(errors.ShareExists, share.Name)
+	}
+	if err := m.resolve(ctx, &share); err != nil {
+		return nil, err
+	}
+
+	if err := m.apply(ctx, append(shares, share)); err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// Update replaces the SMB share called name. The share may be renamed by
+// giving it a different name.
+func (m *Manager) Update(ctx context.Context, name string, share Share) (*Share, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	shares, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	i := find(shares, name)
+	if i < 0 {
+		return nil, errors.New(errors.ShareNotFound, name)
+	}
+	if share.Name == "" {
+		share.Name = shares[i].Name
+	}
+	if j := find(shares, share.Name); j >= 0 && j != i {
+		return nil, errors.New(errors.ShareExists, share.Name)
+	}
+	if err := m.resolve(ctx, &share); err != nil {
+		return nil, err
+	}
+
+	shares[i] = share
+	if err := m.apply(ctx, shares); err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// Delete removes an SMB share
+func (m *Manager) Delete(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	shares, err := m.load()
+	if err != nil {
+		return err
+	}
+	i := find(shares, name)
+	if i < 0 {
+		return errors.New(errors.ShareNotFound, name)
+	}
+	return m.apply(ctx, append(shares[:i], shares[i+1:]...))
+}
+
+// find returns the index of the share called name, -1 if there's none.
+// Samba share names are case insensitive.
+func find(shares []Share, name string) int {
+	for i := range shares {
+		if strings.EqualFold(shares[i].Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// resolve validates a share and sets its path to the dataset mountpoint
+func (m *Manager) resolve(ctx context.Context, share *Share) error {
+	share.Path = ""
+	if err := Validate(*share); err != nil {
+		return err
+	}
+	path, err := m.mountpoint(ctx, share.Dataset)
+	if err != nil {
+		return err
+	}
+	share.Path = path
+	return nil
+}
+
+func (m *Manager) load() ([]Share, error) {
+	data, err := os.ReadFile(m.cfg.IncludeFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Share{}, nil
+		}
+		return nil, errors.Wrap(err, errors.ShareOperation)
+	}
+	return Parse(data)
+}
+
+// apply renders shares into a temporary file and validates it, then
+// installs the shares next to the include file from stdin, renames them
+// into place and reloads smbd
+func (m *Manager) apply(ctx context.Context, shares []Share) error {
+	data, err := Render(shares, m.cfg.ShadowFormat)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp("", filepath.Base(m.cfg.IncludeFile)+".*")
+	if err != nil {
+		return errors.Wrap(err, errors.ShareOperation)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, errors.ShareOperation)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, errors.ShareOperation)
+	}
+
+	status := detect(ctx, m.runner, m.cfg)
+	if status.Testparm {
+		out, err := m.runner.Run(ctx, "testparm", "-s", "--suppress-prompt", tmp.Name())
+		if err != nil {
+			return errors.Wrap(err, errors.ShareInvalidConfig).
+				WithMetadata("output", string(out))
+		}
+	}
+
+	// The copy is renamed over the include file so that smbd never reads
+	// a partial file
+	staged := filepath.Join(filepath.Dir(m.cfg.IncludeFile),
+		"."+filepath.Base(m.cfg.IncludeFile)+".new")
+	out, err := m.runner.RunInput(ctx, string(data), "install", "-m", "0644", "/dev/stdin", staged)
+	if err != nil {
+		return errors.Wrap(err, errors.ShareOperation).
+			WithMetadata("output", string(out))
+	}
+	if out, err := m.runner.Run(ctx, "mv", "-f", staged, m.cfg.IncludeFile); err != nil {
+		return errors.Wrap(err, errors.ShareOperation).
+			WithMetadata("output", string(out))
+	}
+
+	if status.Active {
+		out, err := m.runner.Run(ctx, "systemctl", "reload", "smbd")
+		if err != nil {
+			return errors.Wrap(err, errors.ShareOperation).
+				WithMetadata("output", string(out))
+		}
+	}
+	return nil
+}
+
+// zfsMountpoint returns the mountpoint of a mounted-by-ZFS filesystem
+func (m *Manager) zfsMountpoint(ctx context.Context, dataset string) (string, error) {
+	args := []string{"get", "-p", "-t", "filesystem", "mountpoint", dataset}
+
+	out, err := m.executor.Execute(ctx, command.CommandOptions{Flags: command.FlagJSON}, "zfs get", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return "", errors.Wrap(err, errors.ShareOperation).
+				WithMetadata("output", string(out))
+		}
+		return "", errors.Wrap(err, errors.ShareOperation)
+	}
+
+	var props struct {
+		Datasets map[string]struct {
+			Properties map[string]struct {
+				Value string `json:"value"`
+			} `json:"properties"`
+		} `json:"datasets"`
+	}
+	if err := json.Unmarshal(out, &props); err != nil {
+		return "", errors.Wrap(err, errors.CommandOutputParse)
+	}
+
+	path := props.Datasets[dataset].Properties["mountpoint"].Value
+	if !filepath.IsAbs(path) {
+		return "", errors.New(errors.ShareInvalidConfig,
+			"filesystem has no ZFS managed mountpoint").
+			WithMetadata("dataset", dataset).
+			WithMetadata("mountpoint", path)
+	}
+	return path, nil
+}
@@ -0,0 +1,223 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package smb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+// fakeRunner records commands and fails those listed in fail. install and
+// mv are carried out; install takes its input from stdin.
+type fakeRunner struct {
+	calls []string
+	fail  map[string]bool
+}
+
+func (r *fakeRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	call := strings.Join(append([]string{name}, args...), " ")
+	r.calls = append(r.calls, call)
+	for prefix := range r.fail {
+		if strings.HasPrefix(call, prefix) {
+			return []byte("failed"), fmt.Errorf("exit status 1")
+		}
+	}
+
+	switch name {
+	case "mv":
+		return nil, os.Rename(args[len(args)-2], args[len(args)-1])
+	}
+	return nil, nil
+}
+
+func (r *fakeRunner) RunInput(
+	ctx context.Context,
+	input string,
+	name string,
+	args ...string,
+) ([]byte, error) {
+	if out, err := r.Run(ctx, name, args...); err != nil {
+		return out, err
+	}
+	if name == "install" && args[len(args)-2] == "/dev/stdin" {
+		return nil, os.WriteFile(args[len(args)-1], []byte(input), 0644)
+	}
+	return nil, nil
+}
+
+func (r *fakeRunner) ran(prefix string) bool {
+	for _, call := range r.calls {
+		if strings.HasPrefix(call, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func newTestManager(t *testing.T, runner *fakeRunner) *Manager {
+	t.Helper()
+	dir := t.TempDir()
+	include := filepath.Join(dir, "rodent-shares.conf")
+	main := filepath.Join(dir, "smb.conf")
+	if err := os.WriteFile(main, []byte("[global]\n\tinclude = "+include+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewManager(nil, runner, Config{IncludeFile: include, MainConfig: main})
+	m.mountpoint = func(ctx context.Context, dataset string) (string, error) {
+		return "/" + dataset, nil
+	}
+	return m
+}
+
+func TestManagerLifecycle(t *testing.T) {
+	runner := &fakeRunner{}
+	m := newTestManager(t, runner)
+	ctx := context.Background()
+
+	status := m.Status(ctx)
+	if !status.Installed || !status.Active || !status.Testparm || !status.Included {
+		t.Fatalf("Status() = %+v, want everything available", status)
+	}
+
+	share, err := m.Create(ctx, Share{Name: "data", Dataset: "tank/data"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if share.Path != "/tank/data" {
+		t.Errorf("Create() path = %q, want /tank/data", share.Path)
+	}
+	if !runner.ran("testparm -s --suppress-prompt") || !runner.ran("systemctl reload smbd") {
+		t.Errorf("Create() ran %v, want testparm and reload", runner.calls)
+	}
+
+	// Privileged commands take fixed arguments so that sudoers can pin them
+	dir := filepath.Dir(m.cfg.IncludeFile)
+	staged := filepath.Join(dir, ".rodent-shares.conf.new")
+	for _, call := range []string{
+		"install -m 0644 /dev/stdin " + staged,
+		"mv -f " + staged + " " + m.cfg.IncludeFile,
+	} {
+		if !runner.ran(call) {
+			t.Errorf("Create() ran %v, want %q", runner.calls, call)
+		}
+	}
+
+	if _, err := m.Create(ctx, Share{Name: "DATA", Dataset: "tank/other"}); !isCode(err, errors.ShareExists) {
+		t.Errorf("Create() duplicate error = %v, want ShareExists", err)
+	}
+
+	if _, err := m.Update(ctx, "data", Share{Name: "team", Dataset: "tank/data", ReadOnly: true}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if _, err := m.Get(ctx, "data"); !isCode(err, errors.ShareNotFound) {
+		t.Errorf("Get() renamed share error = %v, want ShareNotFound", err)
+	}
+	got, err := m.Get(ctx, "team")
+	if err != nil || !got.ReadOnly || got.Dataset != "tank/data" {
+		t.Errorf("Get() = %+v, %v", got, err)
+	}
+
+	if err := m.Delete(ctx, "team"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	shares, err := m.List(ctx)
+	if err != nil || len(shares) != 0 {
+		t.Errorf("List() = %+v, %v, want no shares", shares, err)
+	}
+}
+
+func TestManagerTestparmFailure(t *testing.T) {
+	runner := &fakeRunner{}
+	m := newTestManager(t, runner)
+	ctx := context.Background()
+
+	if _, err := m.Create(ctx, Share{Name: "data", Dataset: "tank/data"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	before, err := os.ReadFile(m.cfg.IncludeFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runner.fail = map[string]bool{"testparm": true}
+	runner.calls = nil
+	if _, err := m.Create(ctx, Share{Name: "media", Dataset: "tank/media"}); !isCode(err, errors.ShareInvalidConfig) {
+		t.Fatalf("Create() error = %v, want ShareInvalidConfig", err)
+	}
+	after, err := os.ReadFile(m.cfg.IncludeFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("include file changed after failed validation:\n%s", after)
+	}
+	if runner.ran("systemctl reload") {
+		t.Error("smbd reloaded after failed validation")
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(m.cfg.IncludeFile))
+	if len(entries) != 2 {
+		t.Errorf("temporary file left behind: %v", entries)
+	}
+}
+
+func TestManagerWithoutSamba(t *testing.T) {
+	runner := &fakeRunner{fail: map[string]bool{"which": true}}
+	m := newTestManager(t, runner)
+
+	if _, err := m.Create(context.Background(), Share{Name: "data", Dataset: "tank/data"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if runner.ran("testparm -s") || runner.ran("systemctl") {
+		t.Errorf("Create() ran %v, want only detection", runner.calls)
+	}
+}
+
+func TestExecRunnerSudo(t *testing.T) {
+	tests := []struct {
+		sudo bool
+		name string
+		args []string
+		want string
+	}{
+		{true, "systemctl", []string{"reload", "smbd"}, "sudo systemctl reload smbd"},
+		{true, "systemctl", []string{"is-active", "smbd"}, "systemctl is-active smbd"},
+		{true, "install", []string{"-m", "0644", "a", "b"}, "sudo install -m 0644 a b"},
+		{true, "testparm", []string{"-s"}, "testparm -s"},
+		{false, "systemctl", []string{"reload", "smbd"}, "systemctl reload smbd"},
+	}
+
+	for _, tt := range tests {
+		name, args := ExecRunner{Sudo: tt.sudo}.command(tt.name, tt.args)
+		if got := strings.Join(append([]string{name}, args...), " "); got != tt.want {
+			t.Errorf("command(%q, %v) = %q, want %q", tt.name, tt.args, got, tt.want)
+		}
+	}
+}
+
+func isCode(err error, code errors.ErrorCode) bool {
+	re, ok := err.(*errors.RodentError)
+	return ok && re.Code == code
+}
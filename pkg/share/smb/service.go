@@ -0,0 +1,116 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package smb
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Runner runs the Samba tools. It is satisfied by ExecRunner and replaced
+// in tests.
+type Runner interface {
+	Run(ctx context.Context, name string, args ...string) ([]byte, error)
+	// RunInput runs a command with input on stdin. The include file is
+	// installed from stdin so that every privileged command has fixed
+	// arguments that sudoers can pin.
+	RunInput(ctx context.Context, input string, name string, args ...string) ([]byte, error)
+}
+
+// sudoCommands change system state and run through sudo when
+// ExecRunner.Sudo is set, like the zfs commands of the executor. Their
+// arguments depend only on the configured include file, so sudoers can
+// allow exactly the calls Rodent makes; see notes/security-implications.md.
+var sudoCommands = map[string]bool{
+	"install":          true,
+	"mv":               true,
+	"systemctl reload": true,
+}
+
+// ExecRunner runs commands on the host. With Sudo set, the commands in
+// sudoCommands run through sudo, so Rodent needs neither write access to
+// /etc/samba nor the right to manage smbd itself.
+type ExecRunner struct {
+	Sudo bool
+}
+
+func (r ExecRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	name, args = r.command(name, args)
+	return exec.CommandContext(ctx, name, args...).CombinedOutput()
+}
+
+func (r ExecRunner) RunInput(ctx context.Context, input string, name string, args ...string) ([]byte, error) {
+	name, args = r.command(name, args)
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = strings.NewReader(input)
+	return cmd.CombinedOutput()
+}
+
+// command prefixes privileged commands with sudo
+func (r ExecRunner) command(name string, args []string) (string, []string) {
+	if !r.Sudo {
+		return name, args
+	}
+	if sudoCommands[name] || (len(args) > 0 && sudoCommands[name+" "+args[0]]) {
+		return "sudo", append([]string{name}, args...)
+	}
+	return name, args
+}
+
+// detect checks for smbd and testparm the same way the sharing tests do:
+// smbd must be installed and its service active
+func detect(ctx context.Context, runner Runner, cfg Config) Status {
+	status := Status{IncludeFile: cfg.IncludeFile}
+
+	if _, err := runner.Run(ctx, "which", "smbd"); err == nil {
+		status.Installed = true
+		if _, err := runner.Run(ctx, "systemctl", "is-active", "smbd"); err == nil {
+			status.Active = true
+		}
+	}
+	if _, err := runner.Run(ctx, "which", "testparm"); err == nil {
+		status.Testparm = true
+	}
+	status.Included = includes(cfg.MainConfig, cfg.IncludeFile)
+	return status
+}
+
+// includes reports whether the smb.conf at path has an include directive
+// for file
+func includes(path, file string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok || strings.ToLower(strings.TrimSpace(key)) != "include" {
+			continue
+		}
+		if strings.TrimSpace(value) == file {
+			return true
+		}
+	}
+	return false
+}
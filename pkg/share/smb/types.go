@@ -0,0 +1,88 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package smb
+
+// DefaultShadowFormat is the snapshot name format shadow_copy2 expects
+// unless configured otherwise, e.g. @GMT-2025.01.31-14.00.00
+const DefaultShadowFormat = "@GMT-%Y.%m.%d-%H.%M.%S"
+
+// Share is a Samba share of a filesystem, rendered as a stanza of the
+// include file
+type Share struct {
+	// Name is the share name clients connect to
+	Name    string `json:"name"    binding:"required"`
+	Dataset string `json:"dataset" binding:"required"`
+	Comment string `json:"comment,omitempty"`
+	// ValidUsers restricts access to users and @groups. Empty allows all
+	// authenticated users.
+	ValidUsers []string `json:"valid_users,omitempty"`
+	ReadOnly   bool     `json:"read_only"`
+	// Hidden leaves the share out of browse lists
+	Hidden  bool `json:"hidden,omitempty"`
+	GuestOK bool `json:"guest_ok,omitempty"`
+	// ShadowCopy exposes ZFS snapshots as Previous Versions
+	ShadowCopy *ShadowCopy `json:"shadow_copy,omitempty"`
+
+	// Path is the mountpoint of the dataset, resolved when the share is
+	// saved
+	Path string `json:"path,omitempty"`
+}
+
+// ShadowCopy maps ZFS snapshots to Windows Previous Versions through the
+// shadow_copy2 VFS module
+type ShadowCopy struct {
+	// Format is the strftime format of snapshot names. Snapshots not
+	// matching it are not shown.
+	Format string `json:"format,omitempty"`
+	// Localtime interprets snapshot timestamps as local time instead of UTC
+	Localtime bool `json:"localtime,omitempty"`
+}
+
+// Config locates the Samba configuration and tools
+type Config struct {
+	// IncludeFile holds the generated share stanzas. smb.conf must include
+	// it for the shares to be served.
+	IncludeFile string
+	// MainConfig is the smb.conf checked for the include directive
+	MainConfig string
+	// ShadowFormat is the snapshot name format used when a share doesn't
+	// set one
+	ShadowFormat string
+}
+
+// DefaultConfig returns the configuration of a stock Samba installation
+func DefaultConfig() Config {
+	return Config{
+		IncludeFile:  "/etc/samba/rodent-shares.conf",
+		MainConfig:   "/etc/samba/smb.conf",
+		ShadowFormat: DefaultShadowFormat,
+	}
+}
+
+// Status describes the Samba installation
+type Status struct {
+	// Installed is set when smbd is found
+	Installed bool `json:"installed"`
+	// Active is set when the smbd service is running
+	Active bool `json:"active"`
+	// Testparm is set when testparm is available to validate the shares
+	Testparm    bool   `json:"testparm"`
+	IncludeFile string `json:"include_file"`
+	// Included is set when smb.conf includes the generated file
+	Included bool `json:"included"`
+}
@@ -130,6 +130,43 @@ func (h *DatasetHandler) createVolume(c *gin.Context) {
 	c.Status(http.StatusCreated)
 }
 
+func (h *DatasetHandler) resizeVolume(c *gin.Context) {
+	var cfg dataset.VolumeResizeConfig
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+		return
+	}
+
+	result, err := h.manager.ResizeVolume(c.Request.Context(), cfg)
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *DatasetHandler) cloneVolume(c *gin.Context) {
+	var cfg dataset.VolumeCloneConfig
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+		return
+	}
+
+	if cfg.SnapName != "" && !snapshotNameRegex.MatchString(cfg.SnapName) {
+		APIError(c, errors.New(errors.ZFSDatasetInvalidName, "Invalid snapshot name format"))
+		return
+	}
+
+	result, err := h.manager.CloneVolume(c.Request.Context(), cfg)
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
 func (h *DatasetHandler) destroyDataset(c *gin.Context) {
 	var req dataset.DestroyConfig
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -234,6 +271,15 @@ func (h *DatasetHandler) listSnapshots(c *gin.Context) {
 
 	req.Type = "snapshot"
 
+	if wantsNDJSON(c) {
+		streamNDJSON(c, func(emit func(v interface{}) error) error {
+			return h.manager.ListStream(c.Request.Context(), req, func(ds dataset.Dataset) error {
+				return emit(ds)
+			})
+		})
+		return
+	}
+
 	result, err := h.manager.List(c.Request.Context(), req)
 	if err != nil {
 		APIError(c, err)
@@ -419,6 +465,15 @@ func (h *DatasetHandler) diffDataset(c *gin.Context) {
 		return
 	}
 
+	if wantsNDJSON(c) {
+		streamNDJSON(c, func(emit func(v interface{}) error) error {
+			return h.manager.DiffStream(c.Request.Context(), req, func(entry dataset.DiffEntry) error {
+				return emit(entry)
+			})
+		})
+		return
+	}
+
 	result, err := h.manager.Diff(c.Request.Context(), req)
 	if err != nil {
 		APIError(c, err)
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stratastor/rodent/pkg/disk"
+)
+
+func NewDiskHandler(inventory *disk.Inventory) *DiskHandler {
+	return &DiskHandler{inventory: inventory}
+}
+
+func (h *DiskHandler) listDisks(c *gin.Context) {
+	disks, err := h.inventory.List(c.Request.Context())
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+
+	if c.Query("available") == "true" {
+		available := []disk.Disk{}
+		for _, d := range disks {
+			if !d.InUse && !d.Root {
+				available = append(available, d)
+			}
+		}
+		disks = available
+	}
+	c.JSON(http.StatusOK, gin.H{"disks": disks})
+}
+
+func (h *DiskHandler) getDisk(c *gin.Context) {
+	d, err := h.inventory.Get(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+func NewErrorCatalogHandler() *ErrorCatalogHandler {
+	return &ErrorCatalogHandler{}
+}
+
+func (h *ErrorCatalogHandler) listErrors(c *gin.Context) {
+	catalog := errors.Catalog()
+	if domain := c.Query("domain"); domain != "" {
+		filtered := make([]errors.CatalogEntry, 0, len(catalog))
+		for _, e := range catalog {
+			// Case-insensitive, like rodent errors --domain
+			if strings.EqualFold(string(e.Domain), domain) {
+				filtered = append(filtered, e)
+			}
+		}
+		catalog = filtered
+	}
+	c.JSON(http.StatusOK, gin.H{"errors": catalog})
+}
+
+func (h *ErrorCatalogHandler) getError(c *gin.Context) {
+	code, err := strconv.Atoi(c.Param("code"))
+	if err != nil {
+		APIError(c, errors.New(errors.ServerRequestValidation, "error code must be a number"))
+		return
+	}
+	e, ok := errors.Lookup(errors.ErrorCode(code))
+	if !ok {
+		APIError(c, errors.New(errors.ServerNotFound, "unknown error code "+c.Param("code")))
+		return
+	}
+	c.JSON(http.StatusOK, e)
+}
+
+func (h *ErrorCatalogHandler) getOpenAPIComponents(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"components": errors.OpenAPIComponents()})
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+func TestErrorCatalogAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	NewErrorCatalogHandler().RegisterRoutes(router.Group("/api/v1"))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/api/v1/errors?domain=cmd")
+	var list struct {
+		Errors []errors.CatalogEntry `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || len(list.Errors) == 0 {
+		t.Fatalf("list: %d %s", w.Code, w.Body.String())
+	}
+	for _, e := range list.Errors {
+		if e.Domain != errors.DomainCommand {
+			t.Errorf("domain filter let %+v through", e)
+		}
+	}
+
+	w = get("/api/v1/errors/1311")
+	var entry errors.CatalogEntry
+	if err := json.Unmarshal(w.Body.Bytes(), &entry); err != nil || entry.Name != "CommandQueueFull" ||
+		entry.HTTPStatus != http.StatusServiceUnavailable {
+		t.Errorf("lookup: %d %s", w.Code, w.Body.String())
+	}
+
+	if w = get("/api/v1/errors/9999"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown code: got %d", w.Code)
+	}
+	if w = get("/api/v1/errors/abc"); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid code: got %d", w.Code)
+	}
+	if w = get("/api/v1/errors/openapi"); w.Code != http.StatusOK {
+		t.Errorf("openapi: got %d", w.Code)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/share/iscsi"
+	"github.com/stratastor/rodent/pkg/zfs/common"
+)
+
+func NewISCSIHandler(manager *iscsi.Manager) *ISCSIHandler {
+	return &ISCSIHandler{manager: manager}
+}
+
+func (h *ISCSIHandler) listExports(c *gin.Context) {
+	exports, err := h.manager.List(c.Request.Context())
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"exports": exports})
+}
+
+func (h *ISCSIHandler) getExport(c *gin.Context) {
+	export, err := h.manager.Get(c.Request.Context(), volumeParam(c))
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, export)
+}
+
+func (h *ISCSIHandler) setExport(c *gin.Context) {
+	var export iscsi.Export
+	if err := c.ShouldBindJSON(&export); err != nil {
+		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+		return
+	}
+	export.Volume = volumeParam(c)
+
+	result, err := h.manager.Set(c.Request.Context(), export)
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *ISCSIHandler) deleteExport(c *gin.Context) {
+	if err := h.manager.Delete(c.Request.Context(), volumeParam(c)); err != nil {
+		APIError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// volumeParam returns the volume name of /volumes/*path routes, whose path
+// is the volume name followed by /iscsi
+func volumeParam(c *gin.Context) string {
+	return strings.TrimSuffix(strings.TrimPrefix(c.Param("path"), "/"), "/iscsi")
+}
+
+// ValidateVolumeName checks that a /volumes/*path route ends in /iscsi and
+// validates the volume name before it
+func ValidateVolumeName() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.HasSuffix(strings.TrimPrefix(c.Param("path"), "/"), "/iscsi") {
+			APIError(c, errors.New(errors.ServerNotFound, "Unknown volume route").
+				WithMetadata("path", c.Request.URL.Path))
+			return
+		}
+		if err := common.ValidateZFSName(volumeParam(c), common.TypeVolume); err != nil {
+			APIError(c, err)
+			return
+		}
+		c.Next()
+	}
+}
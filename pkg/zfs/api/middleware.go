@@ -26,6 +26,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/stratastor/rodent/pkg/disk"
 	"github.com/stratastor/rodent/pkg/errors"
 	"github.com/stratastor/rodent/pkg/zfs/common"
 	"github.com/stratastor/rodent/pkg/zfs/dataset"
@@ -38,11 +39,19 @@ var (
 		`^[a-zA-Z0-9][a-zA-Z0-9_.-]*(/[a-zA-Z0-9][a-zA-Z0-9_.-]*)*$`,
 	)
 	snapshotNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
-	volumeSizeRegex   = regexp.MustCompile(`^\d+[KMGTP]?$`)
+	blockSizeRegex    = regexp.MustCompile(`^\d+[KMGTP]?$`)
 	bookmarkNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
 
-	poolNameRegex   = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.-]*$`)
-	devicePathRegex = regexp.MustCompile(`^/dev/[a-zA-Z0-9/]+$`)
+	poolNameRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.-]*$`)
+	// Kernel names (/dev/sdb, /dev/nvme0n1p1) and stable links such as
+	// /dev/disk/by-id/ata-WDC_WD40EFRX_WD-WCC4E1234567 or
+	// /dev/disk/by-path/pci-0000:00:1f.2-ata-2
+	devicePathRegex = regexp.MustCompile(
+		`^/dev/(disk/by-(id|path|partuuid|uuid)/[a-zA-Z0-9_.:+-]+|mapper/[a-zA-Z0-9_.+-]+|[a-zA-Z0-9/]+)$`,
+	)
+	// Vdev names as zpool status shows them: mirror-1, sdb, a guid or a
+	// by-id link name
+	vdevNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.:+-]*$`)
 
 	// TODO: Validate property names? Track ZFS property list? Or just let ZFS handle it?
 	// propertyValueRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.:/@+-]*$`)
@@ -63,12 +72,6 @@ var (
 		"/proc": true,
 		"/sys":  true,
 	}
-
-	restrictedDevices = map[string]bool{
-		"/dev/sda":  true, // system disk
-		"/dev/hda":  true,
-		"/dev/xvda": true,
-	}
 )
 
 // ErrorHandler adds structured error handling
@@ -88,6 +91,10 @@ func ErrorHandler() gin.HandlerFunc {
 				if re.HTTPStatus != 0 {
 					status = re.HTTPStatus
 				}
+				// Tell clients of a full command queue when to come back
+				if retry, ok := re.Metadata["retry_after"]; ok {
+					c.Header("Retry-After", retry)
+				}
 
 				// Return structured error response
 				c.JSON(status, re)
@@ -180,13 +187,19 @@ func ValidateVolumeSize() gin.HandlerFunc {
 			Size string `json:"size"`
 		}
 		if err := c.ShouldBindJSON(&req); err != nil {
+			APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
 			return
 		}
 		// Reset the body so it can be re-read by `ShouldBindJSON` and subsequent handlers
 		ResetBody(c, body)
 
-		if !volumeSizeRegex.MatchString(req.Size) {
-			APIError(c, errors.New(errors.ZFSInvalidSize, "Invalid volume size format"))
+		size, err := dataset.ParseSize(req.Size)
+		if err != nil {
+			APIError(c, err)
+			return
+		}
+		if size == 0 {
+			APIError(c, errors.New(errors.ZFSInvalidSize, "Volume size must be greater than zero"))
 			return
 		}
 		c.Next()
@@ -277,6 +290,32 @@ func ValidateZFSEntityName(dtype common.DatasetType) gin.HandlerFunc {
 	}
 }
 
+// ValidateShareDataset validates the filesystem name of share requests
+func ValidateShareDataset() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := ReadResetBody(c)
+		if err != nil {
+			APIError(c, errors.New(errors.ServerRequestValidation, "Failed to read request body"))
+			return
+		}
+
+		var req struct {
+			Dataset string `json:"dataset" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+			return
+		}
+		ResetBody(c, body)
+
+		if err := common.ValidateZFSName(req.Dataset, common.TypeFilesystem); err != nil {
+			APIError(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
 // isValidDatasetProperty maintains a list of valid ZFS properties
 func isValidDatasetProperty(property string) bool {
 	return common.IsValidDatasetProperty(property)
@@ -419,7 +458,7 @@ func ValidatePropertyValue() gin.HandlerFunc {
 }
 
 // EnhancedValidateDevicePaths adds additional device safety checks
-func EnhancedValidateDevicePaths() gin.HandlerFunc {
+func EnhancedValidateDevicePaths(disks *disk.Inventory) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Read and store the raw body
 		body, err := ReadResetBody(c)
@@ -436,27 +475,240 @@ func EnhancedValidateDevicePaths() gin.HandlerFunc {
 		// Reset the body so it can be re-read by `ShouldBindJSON` and subsequent handlers
 		ResetBody(c, body)
 
-		// Check total number of devices
-		totalDevices := 0
-		for _, spec := range cfg.VDevSpec {
-			totalDevices += len(spec.Devices)
-			if totalDevices > maxDevicePaths {
-				APIError(c, errors.New(errors.ZFSPoolTooManyDevices, "Too many devices specified"))
+		if err := validateVDevDevices(c, disks, cfg.VDevSpec, cfg.Force); err != nil {
+			APIError(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// ValidateAddVDevs validates the device paths of a zpool add request
+func ValidateAddVDevs(disks *disk.Inventory) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := ReadResetBody(c)
+		if err != nil {
+			APIError(c, errors.New(errors.ServerRequestValidation, "Failed to read request body"))
+			return
+		}
+
+		var cfg pool.AddConfig
+		if err := c.ShouldBindJSON(&cfg); err != nil {
+			APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+			return
+		}
+		ResetBody(c, body)
+
+		if err := validateVDevDevices(c, disks, cfg.VDevSpec, cfg.Force); err != nil {
+			APIError(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// ValidatePlanDisks validates the disk paths of a pool creation plan request
+func ValidatePlanDisks(disks *disk.Inventory) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := ReadResetBody(c)
+		if err != nil {
+			APIError(c, errors.New(errors.ServerRequestValidation, "Failed to read request body"))
+			return
+		}
+
+		var cfg pool.PlanConfig
+		if err := c.ShouldBindJSON(&cfg); err != nil {
+			APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+			return
+		}
+		ResetBody(c, body)
+
+		if !poolNameRegex.MatchString(cfg.Name) {
+			APIError(c, errors.New(errors.ZFSPoolInvalidName, "Invalid pool name format"))
+			return
+		}
+
+		if err := validateVDevDevices(c, disks, []pool.VDevSpec{{Devices: cfg.Disks}}, cfg.Force); err != nil {
+			APIError(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// ValidateMaintenanceDevices validates the device paths of add_vdevs
+// maintenance sessions the same way as a direct zpool add
+func ValidateMaintenanceDevices(disks *disk.Inventory) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := ReadResetBody(c)
+		if err != nil {
+			APIError(c, errors.New(errors.ServerRequestValidation, "Failed to read request body"))
+			return
+		}
+
+		var change pool.MaintenanceChange
+		if err := c.ShouldBindJSON(&change); err != nil {
+			APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+			return
+		}
+		ResetBody(c, body)
+
+		if change.Type == pool.ChangeAddVDevs {
+			if err := validateVDevDevices(c, disks, change.VDevSpec, change.Force); err != nil {
+				APIError(c, err)
 				return
 			}
+		}
+		c.Next()
+	}
+}
 
-			for _, device := range spec.Devices {
-				// Basic path validation
-				if !devicePathRegex.MatchString(device) {
-					APIError(c, errors.New(errors.ZFSPoolInvalidDevice, "Invalid device path"))
-					return
-				}
+// ValidateRemoveDevices validates the vdevs of a zpool remove request. They
+// are members of the pool, so only their format is checked, not whether
+// they are in use.
+func ValidateRemoveDevices() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := ReadResetBody(c)
+		if err != nil {
+			APIError(c, errors.New(errors.ServerRequestValidation, "Failed to read request body"))
+			return
+		}
 
-				// Check restricted devices
-				if restrictedDevices[device] {
-					APIError(c, errors.New(errors.ZFSPoolRestrictedDevice, "Device not allowed"))
-					return
-				}
+		var cfg pool.RemoveConfig
+		if err := c.ShouldBindJSON(&cfg); err != nil {
+			APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+			return
+		}
+		ResetBody(c, body)
+
+		if len(cfg.Devices) > maxDevicePaths {
+			APIError(c, errors.New(errors.ZFSPoolTooManyDevices, "Too many devices specified"))
+			return
+		}
+		for _, device := range cfg.Devices {
+			if !validMemberDevice(device) {
+				APIError(c, errors.New(errors.ZFSPoolInvalidDevice, "Invalid device path").
+					WithMetadata("device", device))
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// ValidateMemberDevices validates the pool members named by device
+// operations: device, old_device and devices. Like the vdevs of a remove
+// request, only their format is checked. An empty body is left to the
+// handler.
+func ValidateMemberDevices() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := ReadResetBody(c)
+		if err != nil {
+			APIError(c, errors.New(errors.ServerRequestValidation, "Failed to read request body"))
+			return
+		}
+		if len(body) == 0 {
+			c.Next()
+			return
+		}
+
+		var req struct {
+			Device    string   `json:"device"`
+			OldDevice string   `json:"old_device"`
+			Devices   []string `json:"devices"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+			return
+		}
+		ResetBody(c, body)
+
+		if len(req.Devices) > maxDevicePaths {
+			APIError(c, errors.New(errors.ZFSPoolTooManyDevices, "Too many devices specified"))
+			return
+		}
+		for _, device := range append([]string{req.Device, req.OldDevice}, req.Devices...) {
+			if device != "" && !validMemberDevice(device) {
+				APIError(c, errors.New(errors.ZFSPoolInvalidDevice, "Invalid device path").
+					WithMetadata("device", device))
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// validMemberDevice checks a device or vdev name that names a pool member.
+// Names starting with - would be parsed as options by zpool.
+func validMemberDevice(device string) bool {
+	if strings.HasPrefix(device, "-") {
+		return false
+	}
+	return devicePathRegex.MatchString(device) || vdevNameRegex.MatchString(device)
+}
+
+// validateVDevDevices checks the device paths of vdev specs, including nested
+// children, and that every device is unused and not on the root disk. Force
+// admits devices that only carry a ZFS label or filesystem signature.
+func validateVDevDevices(c *gin.Context, disks *disk.Inventory, specs []pool.VDevSpec, force bool) error {
+	var devices []string
+	if err := collectVDevDevices(specs, &devices); err != nil {
+		return err
+	}
+	if disks == nil {
+		// Fail closed rather than hand zpool a disk that may be in use
+		return errors.New(errors.ServerMiddleware, "disk inventory is not configured")
+	}
+	return disks.CheckAvailable(c.Request.Context(), force, devices...)
+}
+
+// collectVDevDevices validates the format of device paths and gathers them
+func collectVDevDevices(specs []pool.VDevSpec, devices *[]string) error {
+	for _, spec := range specs {
+		// Check total number of devices
+		if len(*devices)+len(spec.Devices) > maxDevicePaths {
+			return errors.New(errors.ZFSPoolTooManyDevices, "Too many devices specified")
+		}
+
+		for _, device := range spec.Devices {
+			// Basic path validation
+			if !devicePathRegex.MatchString(device) {
+				return errors.New(errors.ZFSPoolInvalidDevice, "Invalid device path")
+			}
+			*devices = append(*devices, device)
+		}
+
+		if err := collectVDevDevices(spec.Children, devices); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateNewDevice validates the new_device of attach and replace requests
+func ValidateNewDevice(disks *disk.Inventory) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := ReadResetBody(c)
+		if err != nil {
+			APIError(c, errors.New(errors.ServerRequestValidation, "Failed to read request body"))
+			return
+		}
+
+		var req struct {
+			NewDevice string `json:"new_device"`
+			Force     bool   `json:"force"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+			return
+		}
+		ResetBody(c, body)
+
+		if req.NewDevice != "" {
+			spec := []pool.VDevSpec{{Devices: []string{req.NewDevice}}}
+			if err := validateVDevDevices(c, disks, spec, req.Force); err != nil {
+				APIError(c, err)
+				return
 			}
 		}
 		c.Next()
@@ -595,7 +847,7 @@ func ValidateBlockSize() gin.HandlerFunc {
 		// Reset the body so it can be re-read by `ShouldBindJSON` and subsequent handlers
 		ResetBody(c, body)
 
-		if req.BlockSize != "" && !volumeSizeRegex.MatchString(req.BlockSize) {
+		if req.BlockSize != "" && !blockSizeRegex.MatchString(req.BlockSize) {
 			APIError(c, errors.New(errors.ZFSInvalidSize, "Invalid block size format"))
 			return
 		}
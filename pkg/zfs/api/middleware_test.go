@@ -0,0 +1,295 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stratastor/rodent/pkg/disk"
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/zfs/pool"
+)
+
+func TestDevicePathRegex(t *testing.T) {
+	tests := []struct {
+		path  string
+		valid bool
+	}{
+		{"/dev/sdb", true},
+		{"/dev/nvme0n1p1", true},
+		{"/dev/disk/by-id/ata-WDC_WD40EFRX-68N32N0_WD-WCC7K1234567", true},
+		{"/dev/disk/by-id/wwn-0x5000c500a1b2c3d4-part1", true},
+		{"/dev/disk/by-id/nvme-eui.0025388b81b2c3d4", true},
+		{"/dev/disk/by-path/pci-0000:00:1f.2-ata-2", true},
+		{"/dev/disk/by-path/virtio-pci-0000:00:05.0", true},
+		{"/dev/mapper/crypt-sdb", true},
+		{"/dev/sdb;rm", false},
+		{"/dev/disk/by-id/ata disk", false},
+		{"/tmp/disk.img", false},
+		{"/dev/sd-b", false},
+	}
+
+	for _, tt := range tests {
+		if got := devicePathRegex.MatchString(tt.path); got != tt.valid {
+			t.Errorf("devicePathRegex(%q) = %v, want %v", tt.path, got, tt.valid)
+		}
+	}
+}
+
+func TestErrorHandlerRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/busy", func(c *gin.Context) {
+		APIError(c, errors.New(errors.CommandQueueFull, "queue is full").
+			WithMetadata("retry_after", "3"))
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/busy", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "3" {
+		t.Errorf("got %d, Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+}
+
+// fakeInventory builds a disk inventory over a fake sysfs tree: sda holds
+// the root filesystem, sdb is mounted on /data, sdc is unused and sdd holds an
+// unmounted ext4 filesystem
+func fakeInventory(t *testing.T) *disk.Inventory {
+	t.Helper()
+	root := t.TempDir()
+	cfg := disk.Config{
+		SysBlockDir: filepath.Join(root, "sys", "block"),
+		DevDir:      filepath.Join(root, "dev"),
+		MountsFile:  filepath.Join(root, "proc", "mounts"),
+		UdevDataDir: filepath.Join(root, "run", "udev", "data"),
+	}
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(filepath.Join(cfg.SysBlockDir, "sda", "size"), "1000000")
+	write(filepath.Join(cfg.SysBlockDir, "sda", "sda1", "partition"), "1")
+	write(filepath.Join(cfg.SysBlockDir, "sda", "sda1", "size"), "900000")
+	write(filepath.Join(cfg.SysBlockDir, "sdb", "size"), "4000000")
+	write(filepath.Join(cfg.SysBlockDir, "sdc", "size"), "4000000")
+	write(filepath.Join(cfg.SysBlockDir, "sdd", "size"), "4000000")
+	write(filepath.Join(cfg.SysBlockDir, "sdd", "dev"), "8:48")
+	write(filepath.Join(cfg.UdevDataDir, "b8:48"), "E:ID_FS_TYPE=ext4")
+	for _, name := range []string{"sda", "sda1", "sdb", "sdc", "sdd"} {
+		write(filepath.Join(cfg.DevDir, name), "")
+	}
+	write(cfg.MountsFile, "/dev/sda1 / ext4 rw 0 0\n/dev/sdb /data ext4 rw 0 0\n")
+
+	return disk.NewInventory(nil, cfg)
+}
+
+func TestPoolRoutesRejectUnavailableDisks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	NewPoolHandler(nil, fakeInventory(t)).RegisterRoutes(router.Group("/api/v1"))
+
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{"create on root disk", "/api/v1/pools",
+			`{"Name":"tank","VDevSpec":[{"type":"mirror","devices":["/dev/sda","/dev/sdc"]}]}`},
+		{"create on mounted disk", "/api/v1/pools",
+			`{"Name":"tank","VDevSpec":[{"devices":["/dev/sdb"]}]}`},
+		{"create on formatted disk", "/api/v1/pools",
+			`{"Name":"tank","VDevSpec":[{"devices":["/dev/sdd"]}]}`},
+		{"forced create on mounted disk", "/api/v1/pools",
+			`{"Name":"tank","VDevSpec":[{"devices":["/dev/sdb"]}],"Force":true}`},
+		{"plan with mounted disk", "/api/v1/pools/plan",
+			`{"name":"tank","disks":["/dev/sdb","/dev/sdc"],"layout":{"type":"mirror"}}`},
+		{"add nested root partition", "/api/v1/pools/tank/vdevs",
+			`{"vdev_spec":[{"type":"log","children":[{"devices":["/dev/sda1"]}]}]}`},
+		{"attach mounted disk", "/api/v1/pools/tank/devices/attach",
+			`{"device":"/dev/sdc","new_device":"/dev/sdb"}`},
+		{"maintenance add of mounted disk", "/api/v1/pools/tank/maintenance",
+			`{"type":"add_vdevs","vdev_spec":[{"devices":["/dev/sdb"]}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusConflict {
+				t.Errorf("got %d, want %d: %s", w.Code, http.StatusConflict, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestValidateRemoveDevices(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.POST("/remove", ValidateRemoveDevices(), func(c *gin.Context) {
+		c.Status(http.StatusAccepted)
+	})
+
+	tests := []struct {
+		body string
+		code int
+	}{
+		{`{"devices":["mirror-1"]}`, http.StatusAccepted},
+		{`{"devices":["sdb","/dev/disk/by-id/wwn-0x5000c500a1b2c3d4"]}`, http.StatusAccepted},
+		{`{"devices":["11508240424387306290"]}`, http.StatusAccepted},
+		{`{"devices":["-s"]}`, http.StatusBadRequest},
+		{`{"devices":["/dev/sdb;rm"]}`, http.StatusBadRequest},
+		{`{"devices":["mirror 1"]}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/remove", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("%s: got %d, want %d", tt.body, w.Code, tt.code)
+		}
+	}
+}
+
+func TestValidateMemberDevices(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.POST("/devices", ValidateMemberDevices(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		body string
+		code int
+	}{
+		{``, http.StatusOK},
+		{`{"device":"sdb"}`, http.StatusOK},
+		{`{"device":"/dev/disk/by-id/ata-DISK_B","new_device":"/dev/sdc"}`, http.StatusOK},
+		{`{"old_device":"mirror-0"}`, http.StatusOK},
+		{`{"devices":["sdb","/dev/sdc"],"expand":true}`, http.StatusOK},
+		{`{"device":"-f"}`, http.StatusBadRequest},
+		{`{"device":"-t","temporary":true}`, http.StatusBadRequest},
+		{`{"old_device":"-e"}`, http.StatusBadRequest},
+		{`{"devices":["sdb","-e"]}`, http.StatusBadRequest},
+		{`{"device":"sdb; reboot"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/devices", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("%s: got %d, want %d", tt.body, w.Code, tt.code)
+		}
+	}
+}
+
+func TestValidateVDevDevices(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+
+	inv := fakeInventory(t)
+	spec := []pool.VDevSpec{{Devices: []string{"/dev/sdc"}}}
+	if err := validateVDevDevices(c, inv, spec, false); err != nil {
+		t.Errorf("unused disk rejected: %v", err)
+	}
+	if err := validateVDevDevices(c, nil, spec, false); err == nil {
+		t.Error("validation passed without a disk inventory")
+	}
+
+	formatted := []pool.VDevSpec{{Devices: []string{"/dev/sdd"}}}
+	if err := validateVDevDevices(c, inv, formatted, false); err == nil {
+		t.Error("disk with a filesystem signature accepted without force")
+	}
+	if err := validateVDevDevices(c, inv, formatted, true); err != nil {
+		t.Errorf("forced disk with a filesystem signature rejected: %v", err)
+	}
+}
+
+func TestVolumeNameWildcard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/volumes/*path", ValidateVolumeName(), func(c *gin.Context) {
+		c.String(http.StatusOK, volumeParam(c))
+	})
+
+	tests := []struct {
+		path string
+		code int
+		want string
+	}{
+		{"/volumes/tank/vol1/iscsi", http.StatusOK, "tank/vol1"},
+		{"/volumes/tank%2Fvol1/iscsi", http.StatusOK, "tank/vol1"},
+		{"/volumes/tank/vms/vm1-disk0/iscsi", http.StatusOK, "tank/vms/vm1-disk0"},
+		{"/volumes/tank/vol1", http.StatusNotFound, ""},
+		{"/volumes/tank/vol1/nfs", http.StatusNotFound, ""},
+		{"/volumes/iscsi", http.StatusNotFound, ""},
+		{"/volumes/tank@snap/iscsi", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.code || (tt.want != "" && w.Body.String() != tt.want) {
+			t.Errorf("%s: got %d %q, want %d %q", tt.path, w.Code, w.Body.String(), tt.code, tt.want)
+		}
+	}
+}
+
+func TestSMBRoutesValidateDataset(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	NewSMBHandler(nil).RegisterRoutes(router.Group("/api/v1"))
+
+	for _, req := range []struct{ method, path string }{
+		{http.MethodPost, "/api/v1/shares/smb"},
+		{http.MethodPut, "/api/v1/shares/smb/data"},
+	} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(req.method, req.path,
+			strings.NewReader(`{"name":"data","dataset":"tank/data@snap"}`))
+		r.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s %s: got %d, want %d", req.method, req.path, w.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+// MIMENDJSON is the content type of newline delimited JSON streams
+const MIMENDJSON = "application/x-ndjson"
+
+// ndjsonFlushEvery is how many records are written between flushes
+const ndjsonFlushEvery = 256
+
+// wantsNDJSON reports whether the client asked for a newline delimited JSON
+// stream instead of a single JSON document
+func wantsNDJSON(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), MIMENDJSON)
+}
+
+// streamNDJSON writes every record run emits as one JSON line. Errors
+// before the first record are reported like any other API error. Once the
+// response has started, an error is written as a final {"error": ...} line.
+func streamNDJSON(c *gin.Context, run func(emit func(v interface{}) error) error) {
+	started := false
+	count := 0
+	enc := json.NewEncoder(c.Writer)
+
+	emit := func(v interface{}) error {
+		if !started {
+			c.Header("Content-Type", MIMENDJSON)
+			c.Status(http.StatusOK)
+			started = true
+		}
+		if err := enc.Encode(v); err != nil {
+			// The client went away
+			return errors.Wrap(err, errors.ServerResponseError)
+		}
+		if count++; count%ndjsonFlushEvery == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	}
+
+	err := run(emit)
+	if !started {
+		if err != nil {
+			APIError(c, err)
+			return
+		}
+		// Nothing to stream; still answer with an empty stream
+		c.Header("Content-Type", MIMENDJSON)
+		c.Status(http.StatusOK)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	if err != nil {
+		enc.Encode(gin.H{"error": err})
+	}
+	c.Writer.Flush()
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+func TestStreamNDJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	failAfter := -1
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/stream", func(c *gin.Context) {
+		if !wantsNDJSON(c) {
+			c.JSON(http.StatusOK, gin.H{"result": "document"})
+			return
+		}
+		streamNDJSON(c, func(emit func(v interface{}) error) error {
+			for i := 0; i < 3; i++ {
+				if i == failAfter {
+					return errors.New(errors.ZFSDatasetList, "zfs list failed")
+				}
+				if err := emit(gin.H{"n": i}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+
+	get := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("application/json")
+	if w.Body.String() != `{"result":"document"}` {
+		t.Errorf("without NDJSON accept got %q", w.Body.String())
+	}
+
+	w = get(MIMENDJSON)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != MIMENDJSON {
+		t.Fatalf("got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if want := "{\"n\":0}\n{\"n\":1}\n{\"n\":2}\n"; w.Body.String() != want {
+		t.Errorf("got %q, want %q", w.Body.String(), want)
+	}
+
+	// Errors before the first record are regular API errors
+	failAfter = 0
+	w = get(MIMENDJSON)
+	if w.Code == http.StatusOK || w.Header().Get("Content-Type") == MIMENDJSON ||
+		!strings.Contains(w.Body.String(), `"code":2032`) {
+		t.Errorf("early error: got %d %q", w.Code, w.Body.String())
+	}
+
+	// Later errors end the stream with an error line
+	failAfter = 2
+	w = get(MIMENDJSON)
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if w.Code != http.StatusOK || len(lines) != 3 || !strings.HasPrefix(lines[2], `{"error":`) {
+		t.Errorf("late error: got %d %q", w.Code, w.Body.String())
+	}
+}
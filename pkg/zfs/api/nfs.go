@@ -0,0 +1,116 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/share/nfs"
+	"github.com/stratastor/rodent/pkg/zfs/common"
+)
+
+func NewNFSHandler(manager *nfs.Manager) *NFSHandler {
+	return &NFSHandler{manager: manager}
+}
+
+func (h *NFSHandler) listShares(c *gin.Context) {
+	if dataset := c.Query("dataset"); dataset != "" {
+		if err := common.ValidateZFSName(dataset, common.TypeFilesystem); err != nil {
+			APIError(c, err)
+			return
+		}
+		share, err := h.manager.Get(c.Request.Context(), dataset)
+		if err != nil {
+			APIError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, share)
+		return
+	}
+
+	shares, err := h.manager.List(c.Request.Context())
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"shares": shares})
+}
+
+func (h *NFSHandler) setShare(c *gin.Context) {
+	var share nfs.Share
+	if err := c.ShouldBindJSON(&share); err != nil {
+		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+		return
+	}
+
+	result, err := h.manager.Set(c.Request.Context(), share)
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *NFSHandler) deleteShare(c *gin.Context) {
+	var req nfsDatasetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+		return
+	}
+
+	if err := h.manager.Delete(c.Request.Context(), req.Dataset); err != nil {
+		APIError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *NFSHandler) setClient(c *gin.Context) {
+	var req nfsClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+		return
+	}
+
+	share, err := h.manager.SetClient(c.Request.Context(), req.Dataset, nfs.Client{
+		Host:   req.Host,
+		Access: req.Access,
+	})
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, share)
+}
+
+func (h *NFSHandler) removeClient(c *gin.Context) {
+	var req nfsClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+		return
+	}
+
+	share, err := h.manager.RemoveClient(c.Request.Context(), req.Dataset, req.Host)
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, share)
+}
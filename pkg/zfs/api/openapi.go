@@ -0,0 +1,533 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	_ "embed"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stratastor/rodent/pkg/disk"
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/openapi"
+	"github.com/stratastor/rodent/pkg/share/iscsi"
+	"github.com/stratastor/rodent/pkg/share/nfs"
+	"github.com/stratastor/rodent/pkg/share/smb"
+	"github.com/stratastor/rodent/pkg/zfs/command"
+	"github.com/stratastor/rodent/pkg/zfs/dataset"
+	"github.com/stratastor/rodent/pkg/zfs/pool"
+	"github.com/stratastor/rodent/pkg/zfs/program"
+)
+
+// APIVersion is the version of the HTTP API in the OpenAPI document
+const APIVersion = "v1"
+
+//go:embed openapi.html
+var docsViewer []byte
+
+// volumeISCSIPath describes the path of the /volumes/*path routes
+const volumeISCSIPath = "path is the volume name followed by /iscsi, " +
+	"e.g. tank/vol1/iscsi; the slashes of the name may be escaped as %2F"
+
+// routeDocs documents every route, keyed by method and path as gin
+// registers them. TestOpenAPIRoutes fails when a route is registered
+// without an entry here or an entry has no route.
+var routeDocs = map[string]openapi.Operation{
+	// Datasets
+	"POST /api/v1/dataset/list": {
+		Summary: "List datasets", Tag: "datasets",
+		Request: dataset.ListConfig{}, Response: openapi.Fields{"result": dataset.ListResult{}},
+	},
+	"DELETE /api/v1/dataset": {
+		Summary: "Destroy a dataset", Tag: "datasets",
+		Request: dataset.DestroyConfig{}, Status: http.StatusNoContent,
+	},
+	"POST /api/v1/dataset/rename": {
+		Summary: "Rename a dataset", Tag: "datasets",
+		Request: dataset.RenameConfig{},
+	},
+	"POST /api/v1/dataset/diff": {
+		Summary: "List the differences between snapshots, or a snapshot and its dataset", Tag: "datasets",
+		Request: dataset.DiffConfig{}, Response: openapi.Fields{"result": dataset.DiffResult{}},
+		Stream: dataset.DiffEntry{},
+	},
+	"POST /api/v1/dataset/properties/list": {
+		Summary: "List the properties of a dataset", Tag: "datasets",
+		Request: dataset.NameConfig{}, Response: openapi.Fields{"result": dataset.ListResult{}},
+	},
+	"POST /api/v1/dataset/property/fetch": {
+		Summary: "Get a property of a dataset", Tag: "datasets",
+		Request: dataset.PropertyConfig{}, Response: openapi.Fields{"result": dataset.ListResult{}},
+	},
+	"PUT /api/v1/dataset/property": {
+		Summary: "Set a property of a dataset", Tag: "datasets",
+		Request: dataset.SetPropertyConfig{}, Status: http.StatusCreated,
+	},
+	"PUT /api/v1/dataset/property/inherit": {
+		Summary: "Inherit a property from the parent dataset", Tag: "datasets",
+		Request: dataset.InheritConfig{}, Status: http.StatusCreated,
+	},
+	"POST /api/v1/dataset/filesystems/list": {
+		Summary: "List filesystems", Tag: "datasets",
+		Request: dataset.ListConfig{}, Response: openapi.Fields{"result": dataset.ListResult{}},
+	},
+	"POST /api/v1/dataset/filesystem": {
+		Summary: "Create a filesystem", Tag: "datasets",
+		Request: dataset.FilesystemConfig{}, Status: http.StatusCreated,
+	},
+	"POST /api/v1/dataset/filesystem/mount": {
+		Summary: "Mount a filesystem", Tag: "datasets",
+		Request: dataset.MountConfig{},
+	},
+	"POST /api/v1/dataset/filesystem/unmount": {
+		Summary: "Unmount a filesystem", Tag: "datasets",
+		Request: dataset.UnmountConfig{}, Status: http.StatusNoContent,
+	},
+	"POST /api/v1/dataset/volumes/list": {
+		Summary: "List volumes", Tag: "datasets",
+		Request: dataset.ListConfig{}, Response: openapi.Fields{"result": dataset.ListResult{}},
+	},
+	"POST /api/v1/dataset/volume": {
+		Summary: "Create a volume", Tag: "datasets",
+		Request: dataset.VolumeConfig{}, Status: http.StatusCreated,
+	},
+	"POST /api/v1/dataset/volume/resize": {
+		Summary: "Resize a volume", Tag: "datasets",
+		Request: dataset.VolumeResizeConfig{}, Response: dataset.VolumeResizeResult{},
+	},
+	"POST /api/v1/dataset/volume/clone": {
+		Summary: "Clone a volume into a new, promoted volume", Tag: "datasets",
+		Request: dataset.VolumeCloneConfig{}, Response: dataset.VolumeCloneResult{},
+		Status: http.StatusCreated,
+	},
+	"POST /api/v1/dataset/snapshots/list": {
+		Summary: "List snapshots", Tag: "datasets",
+		Request: dataset.ListConfig{}, Response: openapi.Fields{"result": dataset.ListResult{}},
+		Stream: dataset.Dataset{},
+	},
+	"POST /api/v1/dataset/snapshot": {
+		Summary: "Create a snapshot", Tag: "datasets",
+		Request: dataset.SnapshotConfig{}, Status: http.StatusCreated,
+	},
+	"POST /api/v1/dataset/snapshot/rollback": {
+		Summary: "Roll back to a snapshot", Tag: "datasets",
+		Request: dataset.RollbackConfig{},
+	},
+	"POST /api/v1/dataset/clone": {
+		Summary: "Create a clone of a snapshot", Tag: "datasets",
+		Request: dataset.CloneConfig{}, Status: http.StatusCreated,
+	},
+	"POST /api/v1/dataset/clone/promote": {
+		Summary: "Promote a clone", Tag: "datasets",
+		Request: dataset.NameConfig{},
+	},
+	"POST /api/v1/dataset/bookmarks/list": {
+		Summary: "List bookmarks", Tag: "datasets",
+		Request: dataset.ListConfig{}, Response: openapi.Fields{"result": dataset.ListResult{}},
+	},
+	"POST /api/v1/dataset/bookmark": {
+		Summary: "Create a bookmark", Tag: "datasets",
+		Request: dataset.BookmarkConfig{}, Status: http.StatusCreated,
+	},
+	"POST /api/v1/dataset/permissions/list": {
+		Summary: "List delegated permissions", Tag: "datasets",
+		Request: dataset.NameConfig{}, Response: openapi.Fields{"result": dataset.AllowResult{}},
+	},
+	"POST /api/v1/dataset/permissions": {
+		Summary: "Delegate permissions", Tag: "datasets",
+		Request: dataset.AllowConfig{}, Status: http.StatusCreated,
+	},
+	"DELETE /api/v1/dataset/permissions": {
+		Summary: "Remove delegated permissions", Tag: "datasets",
+		Request: dataset.UnallowConfig{}, Status: http.StatusNoContent,
+	},
+	"POST /api/v1/dataset/share": {
+		Summary: "Share a dataset", Tag: "datasets",
+		Request: dataset.ShareConfig{},
+	},
+	"DELETE /api/v1/dataset/share": {
+		Summary: "Unshare a dataset", Tag: "datasets",
+		Request: dataset.UnshareConfig{},
+	},
+	"POST /api/v1/dataset/transfer/send": {
+		Summary: "Send a snapshot to a local or remote dataset", Tag: "datasets",
+		Request: dataset.TransferConfig{},
+	},
+	"POST /api/v1/dataset/transfer/resume-token/fetch": {
+		Summary: "Get the resume token of an interrupted receive", Tag: "datasets",
+		Request: dataset.NameConfig{}, Response: openapi.Fields{"result": ""},
+	},
+
+	// Pools
+	"POST /api/v1/pools": {
+		Summary: "Create a pool", Tag: "pools",
+		Request: pool.CreateConfig{}, Status: http.StatusCreated,
+	},
+	"POST /api/v1/pools/plan": {
+		Summary: "Validate a pool layout and preview it with zpool create -n", Tag: "pools",
+		Request: pool.PlanConfig{}, Response: pool.CreatePlan{},
+	},
+	"GET /api/v1/pools": {
+		Summary: "List pools", Tag: "pools",
+		Response: pool.ListResult{},
+	},
+	"DELETE /api/v1/pools/:name": {
+		Summary: "Destroy a pool", Tag: "pools",
+		Query:  []openapi.Param{{Name: "force", Type: "boolean", Description: "Unmount busy datasets"}},
+		Status: http.StatusNoContent,
+	},
+	"GET /api/v1/pools/importable": {
+		Summary: "List pools available for import", Tag: "pools",
+		Query: []openapi.Param{
+			{Name: "dir", Description: "Device directory to search; repeatable"},
+			{Name: "destroyed", Type: "boolean", Description: "List destroyed pools"},
+		},
+		Response: openapi.Fields{"pools": []pool.ImportablePool{}},
+	},
+	"POST /api/v1/pools/import": {
+		Summary: "Import a pool", Tag: "pools",
+		Request: pool.ImportConfig{},
+	},
+	"POST /api/v1/pools/:name/export": {
+		Summary: "Export a pool", Tag: "pools",
+		Query: []openapi.Param{{Name: "force", Type: "boolean", Description: "Unmount busy datasets"}},
+	},
+	"GET /api/v1/pools/:name/status": {
+		Summary: "Get the status of a pool", Tag: "pools",
+		Query:    []openapi.Param{{Name: "progress", Type: "boolean", Description: "Include scan, initialize and trim progress"}},
+		Response: pool.PoolStatus{},
+	},
+	"GET /api/v1/pools/:name/properties": {
+		Summary: "List the properties of a pool", Tag: "pools",
+		Response: pool.ListResult{},
+	},
+	"GET /api/v1/pools/:name/history": {
+		Summary: "Get the command history of a pool", Tag: "pools",
+		Query: []openapi.Param{
+			{Name: "internal", Type: "boolean", Description: "Include internal events"},
+			{Name: "long", Type: "boolean", Description: "Include user, host and zone"},
+			{Name: "dataset", Description: "Only entries about a dataset"},
+			{Name: "since", Description: "RFC 3339 time of the earliest entry"},
+			{Name: "until", Description: "RFC 3339 time of the latest entry"},
+		},
+		Response: openapi.Fields{"history": []pool.HistoryEntry{}},
+	},
+	"GET /api/v1/pools/:name/properties/:property": {
+		Summary: "Get a property of a pool", Tag: "pools",
+		Response: pool.ListResult{},
+	},
+	"PUT /api/v1/pools/:name/properties/:property": {
+		Summary: "Set a property of a pool", Tag: "pools",
+		Request: setPoolPropertyRequest{},
+	},
+	"POST /api/v1/pools/:name/scrub": {
+		Summary: "Start, pause or stop a scrub", Tag: "pools",
+		Query:   []openapi.Param{{Name: "stop", Type: "boolean", Description: "Stop the scrub"}},
+		Request: scrubRequest{},
+	},
+	"GET /api/v1/pools/:name/scrub/history": {
+		Summary: "List completed scrubs", Tag: "pools",
+		Query:    []openapi.Param{{Name: "limit", Type: "integer", Description: "Most recent records to return"}},
+		Response: openapi.Fields{"history": []pool.ScrubRecord{}},
+	},
+	"POST /api/v1/pools/:name/resilver": {
+		Summary: "Restart resilvering", Tag: "pools",
+	},
+	"POST /api/v1/pools/:name/initialize": {
+		Summary: "Start, cancel or suspend initializing devices", Tag: "pools",
+		Request: pool.InitializeConfig{},
+	},
+	"POST /api/v1/pools/:name/trim": {
+		Summary: "Start, cancel or suspend trimming devices", Tag: "pools",
+		Request: pool.TrimConfig{},
+	},
+	"POST /api/v1/pools/:name/checkpoint": {
+		Summary: "Checkpoint a pool", Tag: "pools",
+		Status: http.StatusCreated,
+	},
+	"GET /api/v1/pools/:name/checkpoint": {
+		Summary: "Get the checkpoint of a pool", Tag: "pools",
+		Response: pool.CheckpointInfo{},
+	},
+	"DELETE /api/v1/pools/:name/checkpoint": {
+		Summary: "Discard the checkpoint of a pool", Tag: "pools",
+		Query:  []openapi.Param{{Name: "wait", Type: "boolean", Description: "Wait until the checkpoint is discarded"}},
+		Status: http.StatusNoContent,
+	},
+	"POST /api/v1/pools/:name/maintenance": {
+		Summary: "Checkpoint a pool and apply a change", Tag: "pools",
+		Request: pool.MaintenanceChange{}, Response: pool.MaintenanceSession{},
+		Status: http.StatusCreated,
+	},
+	"GET /api/v1/pools/:name/maintenance": {
+		Summary: "List the maintenance sessions of a pool", Tag: "pools",
+		Response: openapi.Fields{"sessions": []pool.MaintenanceSession{}},
+	},
+	"GET /api/v1/pools/:name/maintenance/:id": {
+		Summary: "Get a maintenance session", Tag: "pools",
+		Response: pool.MaintenanceSession{},
+	},
+	"POST /api/v1/pools/:name/maintenance/:id/confirm": {
+		Summary: "Confirm a change and discard its checkpoint", Tag: "pools",
+		Response: pool.MaintenanceSession{},
+	},
+	"POST /api/v1/pools/:name/maintenance/:id/rollback": {
+		Summary: "Rewind a pool to the checkpoint of a session", Tag: "pools",
+		Query:    []openapi.Param{{Name: "force", Type: "boolean", Description: "Force the export before rewinding"}},
+		Response: pool.MaintenanceSession{},
+	},
+	"POST /api/v1/pools/:name/maintenance/:id/abandon": {
+		Summary: "Discard the checkpoint of a session without confirming", Tag: "pools",
+		Response: pool.MaintenanceSession{},
+	},
+	"POST /api/v1/pools/:name/devices/attach": {
+		Summary: "Attach a device to a mirror or disk", Tag: "pools",
+		Request: attachDeviceRequest{},
+	},
+	"POST /api/v1/pools/:name/devices/detach": {
+		Summary: "Detach a device from a mirror", Tag: "pools",
+		Request: detachDeviceRequest{},
+	},
+	"POST /api/v1/pools/:name/devices/replace": {
+		Summary: "Replace a device", Tag: "pools",
+		Request: replaceDeviceRequest{},
+	},
+	"POST /api/v1/pools/:name/devices/online": {
+		Summary: "Bring devices online", Tag: "pools",
+		Request: onlineDeviceRequest{},
+	},
+	"POST /api/v1/pools/:name/devices/offline": {
+		Summary: "Take a device offline", Tag: "pools",
+		Request: offlineDeviceRequest{},
+	},
+	"POST /api/v1/pools/:name/devices/clear": {
+		Summary: "Clear device errors; all devices with an empty body", Tag: "pools",
+		Request: clearDeviceRequest{},
+	},
+	"POST /api/v1/pools/:name/reopen": {
+		Summary: "Reopen the devices of a pool", Tag: "pools",
+		Query: []openapi.Param{{Name: "no_restart", Type: "boolean", Description: "Don't restart an in-progress scrub"}},
+	},
+	"GET /api/v1/pools/:name/spares/actions": {
+		Summary: "List automatic hot spare replacements", Tag: "pools",
+		Response: openapi.Fields{"actions": []pool.SpareAction{}},
+	},
+	"POST /api/v1/pools/:name/vdevs": {
+		Summary: "Add vdevs; previews the layout with dry_run", Tag: "pools",
+		Request: pool.AddConfig{}, Response: pool.LayoutPreview{},
+		Status: http.StatusCreated,
+	},
+	"POST /api/v1/pools/:name/vdevs/remove": {
+		Summary: "Remove vdevs; previews the layout with dry_run", Tag: "pools",
+		Request: pool.RemoveConfig{}, Response: pool.LayoutPreview{},
+		Status: http.StatusAccepted,
+	},
+	"DELETE /api/v1/pools/:name/vdevs/remove": {
+		Summary: "Cancel an in-progress vdev removal", Tag: "pools",
+	},
+
+	// Channel programs
+	"GET /api/v1/programs": {
+		Summary: "List the vetted channel program library", Tag: "programs",
+		Response: openapi.Fields{"programs": []program.Script{}, "allow_custom": false},
+	},
+	"POST /api/v1/programs/custom": {
+		Summary: "Run a custom Lua script", Tag: "programs",
+		Request: program.CustomConfig{}, Response: program.Result{},
+	},
+	"GET /api/v1/programs/:program": {
+		Summary: "Get a library program with its source", Tag: "programs",
+		Response: program.Script{},
+	},
+	"POST /api/v1/programs/:program/run": {
+		Summary: "Run a library program with named arguments", Tag: "programs",
+		Request: program.RunConfig{}, Response: program.Result{},
+	},
+
+	// Disks
+	"GET /api/v1/disks": {
+		Summary: "List block devices", Tag: "disks",
+		Query:    []openapi.Param{{Name: "available", Type: "boolean", Description: "Only disks available for pools"}},
+		Response: openapi.Fields{"disks": []disk.Disk{}},
+	},
+	"GET /api/v1/disks/:name": {
+		Summary: "Get a block device", Tag: "disks",
+		Response: disk.Disk{},
+	},
+
+	// Shares
+	"GET /api/v1/shares/nfs": {
+		Summary: "List NFS shares, or get the share of a dataset", Tag: "nfs",
+		Query:    []openapi.Param{{Name: "dataset", Description: "Only the share of this dataset"}},
+		Response: openapi.Fields{"shares": []nfs.Share{}},
+	},
+	"PUT /api/v1/shares/nfs": {
+		Summary: "Create or replace the NFS share of a dataset", Tag: "nfs",
+		Request: nfs.Share{}, Response: nfs.Share{},
+	},
+	"DELETE /api/v1/shares/nfs": {
+		Summary: "Stop sharing a dataset over NFS", Tag: "nfs",
+		Request: nfsDatasetRequest{}, Status: http.StatusNoContent,
+	},
+	"PUT /api/v1/shares/nfs/clients": {
+		Summary: "Add or update a client of an NFS share", Tag: "nfs",
+		Request: nfsClientRequest{}, Response: nfs.Share{},
+	},
+	"DELETE /api/v1/shares/nfs/clients": {
+		Summary: "Remove a client of an NFS share", Tag: "nfs",
+		Request: nfsClientRequest{}, Response: nfs.Share{},
+	},
+	"GET /api/v1/shares/smb/status": {
+		Summary: "Get the Samba configuration status", Tag: "smb",
+		Response: smb.Status{},
+	},
+	"GET /api/v1/shares/smb": {
+		Summary: "List SMB shares", Tag: "smb",
+		Response: openapi.Fields{"shares": []smb.Share{}},
+	},
+	"POST /api/v1/shares/smb": {
+		Summary: "Create an SMB share", Tag: "smb",
+		Request: smb.Share{}, Response: smb.Share{}, Status: http.StatusCreated,
+	},
+	"GET /api/v1/shares/smb/:name": {
+		Summary: "Get an SMB share", Tag: "smb",
+		Response: smb.Share{},
+	},
+	"PUT /api/v1/shares/smb/:name": {
+		Summary: "Update an SMB share", Tag: "smb",
+		Request: smb.Share{}, Response: smb.Share{},
+	},
+	"DELETE /api/v1/shares/smb/:name": {
+		Summary: "Delete an SMB share", Tag: "smb",
+		Status: http.StatusNoContent,
+	},
+	"GET /api/v1/iscsi/exports": {
+		Summary: "List iSCSI exports", Tag: "iscsi",
+		Response: openapi.Fields{"exports": []iscsi.Export{}},
+	},
+	"GET /api/v1/volumes/*path": {
+		Summary: "Get the iSCSI export of a volume", Tag: "iscsi",
+		Description: volumeISCSIPath,
+		Response:    iscsi.Export{},
+	},
+	"PUT /api/v1/volumes/*path": {
+		Summary: "Export a volume over iSCSI", Tag: "iscsi",
+		Description: volumeISCSIPath,
+		Request:     iscsi.Export{}, Response: iscsi.Export{},
+	},
+	"DELETE /api/v1/volumes/*path": {
+		Summary: "Remove the iSCSI export of a volume", Tag: "iscsi",
+		Description: volumeISCSIPath,
+		Status:      http.StatusNoContent,
+	},
+
+	// System
+	"GET /api/v1/capabilities": {
+		Summary: "Get the installed OpenZFS version, features and subcommands", Tag: "system",
+		Query:    []openapi.Param{{Name: "refresh", Type: "boolean", Description: "Probe again"}},
+		Response: command.Capabilities{},
+	},
+	"GET /api/v1/executor/queue": {
+		Summary: "Get the command queue metrics", Tag: "system",
+		Response: command.QueueStats{},
+	},
+	"GET /api/v1/errors": {
+		Summary: "List the error catalog", Tag: "system",
+		Query:    []openapi.Param{{Name: "domain", Description: "Only codes of a domain, e.g. ZFS"}},
+		Response: openapi.Fields{"errors": []errors.CatalogEntry{}},
+	},
+	"GET /api/v1/errors/openapi": {
+		Summary: "Get the error catalog as an OpenAPI components section", Tag: "system",
+		Response: openapi.Fields{"components": map[string]interface{}{}},
+	},
+	"GET /api/v1/errors/:code": {
+		Summary: "Look up an error code", Tag: "system",
+		Response: errors.CatalogEntry{},
+	},
+	"GET /api/v1/openapi.json": {
+		Summary: "Get this OpenAPI document", Tag: "system",
+		Response: map[string]interface{}{},
+	},
+	"GET /api/v1/docs": {
+		Summary: "Browse this OpenAPI document", Tag: "system",
+	},
+}
+
+// BuildOpenAPI returns the OpenAPI document of the registered /api/v1
+// routes, and the routes missing from routeDocs. Those are included
+// without request and response schemas.
+func BuildOpenAPI(routes gin.RoutesInfo) (*openapi.Document, []string) {
+	var (
+		ops          []openapi.Route
+		undocumented []string
+	)
+	for _, r := range routes {
+		if !strings.HasPrefix(r.Path, "/api/"+APIVersion+"/") {
+			continue
+		}
+		key := r.Method + " " + r.Path
+		op, ok := routeDocs[key]
+		if !ok {
+			undocumented = append(undocumented, key)
+			op = openapi.Operation{Summary: key}
+		}
+		ops = append(ops, openapi.Route{Method: r.Method, Path: r.Path, Operation: op})
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].Path != ops[j].Path {
+			return ops[i].Path < ops[j].Path
+		}
+		return ops[i].Method < ops[j].Method
+	})
+	sort.Strings(undocumented)
+
+	components := errors.OpenAPIComponents()
+	doc := openapi.Build(
+		openapi.Info{
+			Title:   "Rodent API",
+			Version: APIVersion,
+			Description: "ZFS pool, dataset, share and transfer management of a StrataSTOR node. " +
+				"Errors carry a code from the error catalog, see GET /api/v1/errors.",
+		},
+		ops,
+		openapi.Components{
+			Schemas:   components["schemas"].(map[string]interface{}),
+			Responses: components["responses"].(map[string]interface{}),
+		},
+		&openapi.Schema{Ref: "#/components/schemas/RodentError"},
+	)
+	return doc, undocumented
+}
+
+func NewOpenAPIHandler(routes func() gin.RoutesInfo) *OpenAPIHandler {
+	return &OpenAPIHandler{routes: routes}
+}
+
+func (h *OpenAPIHandler) getSpec(c *gin.Context) {
+	// Routes are all registered by the first request
+	h.once.Do(func() {
+		h.doc, _ = BuildOpenAPI(h.routes())
+	})
+	c.JSON(http.StatusOK, h.doc)
+}
+
+func (h *OpenAPIHandler) getDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsViewer)
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newRoutesEngine registers every handler the server registers. Handlers
+// aren't called, so they don't need managers.
+func newRoutesEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(ErrorHandler())
+	v1 := engine.Group("/api/v1")
+	NewDatasetHandler(nil).RegisterRoutes(v1)
+	NewPoolHandler(nil, nil).RegisterRoutes(v1)
+	NewProgramHandler(nil).RegisterRoutes(v1)
+	NewDiskHandler(nil).RegisterRoutes(v1)
+	NewNFSHandler(nil).RegisterRoutes(v1)
+	NewSMBHandler(nil).RegisterRoutes(v1)
+	NewISCSIHandler(nil).RegisterRoutes(v1)
+	NewCapabilitiesHandler(nil).RegisterRoutes(v1)
+	NewExecutorHandler(nil).RegisterRoutes(v1)
+	NewErrorCatalogHandler().RegisterRoutes(v1)
+	NewOpenAPIHandler(engine.Routes).RegisterRoutes(v1)
+	return engine
+}
+
+// TestOpenAPIRoutes fails when routes and the document drift apart: a
+// route registered without an entry in routeDocs, or an entry without a
+// route.
+func TestOpenAPIRoutes(t *testing.T) {
+	engine := newRoutesEngine()
+
+	doc, undocumented := BuildOpenAPI(engine.Routes())
+	for _, r := range undocumented {
+		t.Errorf("route %s is not documented in routeDocs", r)
+	}
+
+	registered := map[string]bool{}
+	for _, r := range engine.Routes() {
+		registered[r.Method+" "+r.Path] = true
+	}
+	for key := range routeDocs {
+		if !registered[key] {
+			t.Errorf("routeDocs documents %s, which is not registered", key)
+		}
+	}
+
+	var ops int
+	for _, methods := range doc.Paths {
+		ops += len(methods)
+	}
+	if ops != len(registered) {
+		t.Errorf("document has %d operations, want %d", ops, len(registered))
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	engine := newRoutesEngine()
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("openapi.json: got %d", w.Code)
+	}
+
+	var doc struct {
+		OpenAPI    string                                       `json:"openapi"`
+		Paths      map[string]map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Errorf("openapi version %q", doc.OpenAPI)
+	}
+
+	for _, name := range []string{
+		"dataset.SnapshotConfig", "dataset.TransferConfig", "pool.CreateConfig",
+		"pool.PoolStatus", "RodentError", "ErrorCode",
+	} {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("schema %s missing", name)
+		}
+	}
+
+	// Every $ref resolves to a component
+	for _, ref := range refs(w.Body.String()) {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		if strings.HasPrefix(ref, "#/components/schemas/") {
+			if _, ok := doc.Components.Schemas[name]; !ok {
+				t.Errorf("dangling $ref %s", ref)
+			}
+		}
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &raw); err != nil {
+		t.Fatal(err)
+	}
+	if lookup(raw, "paths", "/api/v1/pools/{name}/status", "get", "responses", "default") == nil {
+		t.Error("GET /api/v1/pools/{name}/status has no error response")
+	}
+	if lookup(raw, "paths", "/api/v1/dataset/snapshots/list", "post", "responses", "200", "content", MIMENDJSON) == nil {
+		t.Error("POST /api/v1/dataset/snapshots/list has no NDJSON response")
+	}
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/docs", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "openapi.json") {
+		t.Errorf("docs: got %d", w.Code)
+	}
+}
+
+// refs returns the $ref values of a JSON document
+func refs(doc string) []string {
+	var out []string
+	for _, part := range strings.Split(doc, `"$ref":"`)[1:] {
+		out = append(out, part[:strings.Index(part, `"`)])
+	}
+	return out
+}
+
+// lookup follows keys through nested JSON objects
+func lookup(v interface{}, keys ...string) interface{} {
+	for _, k := range keys {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[k]
+	}
+	return v
+}
@@ -19,14 +19,19 @@ package api
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/stratastor/rodent/pkg/disk"
 	"github.com/stratastor/rodent/pkg/errors"
 	"github.com/stratastor/rodent/pkg/zfs/pool"
 )
 
-func NewPoolHandler(manager *pool.Manager) *PoolHandler {
-	return &PoolHandler{manager: manager}
+// NewPoolHandler creates a pool handler. disks is required: requests that
+// name devices are refused without an inventory to check them against.
+func NewPoolHandler(manager *pool.Manager, disks *disk.Inventory) *PoolHandler {
+	return &PoolHandler{manager: manager, disks: disks}
 }
 
 func (h *PoolHandler) listPools(c *gin.Context) {
@@ -63,6 +68,20 @@ func (h *PoolHandler) importPool(c *gin.Context) {
 	c.Status(http.StatusOK)
 }
 
+func (h *PoolHandler) listImportable(c *gin.Context) {
+	cfg := pool.ImportableConfig{
+		Paths:     c.QueryArray("dir"),
+		Destroyed: c.Query("destroyed") == "true",
+	}
+
+	pools, err := h.manager.ListImportable(c.Request.Context(), cfg)
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"pools": pools})
+}
+
 func (h *PoolHandler) exportPool(c *gin.Context) {
 	name := c.Param("name")
 	force := c.Query("force") == "true"
@@ -77,7 +96,13 @@ func (h *PoolHandler) exportPool(c *gin.Context) {
 func (h *PoolHandler) getPoolStatus(c *gin.Context) {
 	name := c.Param("name")
 
-	status, err := h.manager.Status(c.Request.Context(), name)
+	var status pool.PoolStatus
+	var err error
+	if c.Query("progress") == "true" {
+		status, err = h.manager.StatusWithProgress(c.Request.Context(), name)
+	} else {
+		status, err = h.manager.Status(c.Request.Context(), name)
+	}
 	if err != nil {
 		APIError(c, err)
 		return
@@ -112,9 +137,7 @@ func (h *PoolHandler) setProperty(c *gin.Context) {
 	name := c.Param("name")
 	property := c.Param("property")
 
-	var req struct {
-		Value string `json:"value" binding:"required"`
-	}
+	var req setPoolPropertyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
 		return
@@ -129,9 +152,22 @@ func (h *PoolHandler) setProperty(c *gin.Context) {
 
 func (h *PoolHandler) scrubPool(c *gin.Context) {
 	name := c.Param("name")
-	stop := c.Query("stop") == "true"
 
-	if err := h.manager.Scrub(c.Request.Context(), name, stop); err != nil {
+	var req scrubRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+			return
+		}
+	}
+
+	cfg := pool.ScrubConfig{Action: req.Action, ErrorScrub: req.ErrorScrub}
+	// stop is kept for older clients
+	if req.Stop || c.Query("stop") == "true" {
+		cfg.Action = pool.ScrubStop
+	}
+
+	if err := h.manager.Scrub(c.Request.Context(), name, cfg); err != nil {
 		APIError(c, err)
 		return
 	}
@@ -163,18 +199,30 @@ func (h *PoolHandler) createPool(c *gin.Context) {
 	c.Status(http.StatusCreated)
 }
 
+func (h *PoolHandler) planPool(c *gin.Context) {
+	var cfg pool.PlanConfig
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+		return
+	}
+
+	plan, err := h.manager.Plan(c.Request.Context(), cfg)
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
+
 func (h *PoolHandler) attachDevice(c *gin.Context) {
 	pool := c.Param("name")
-	var req struct {
-		Device    string `json:"device" binding:"required"`
-		NewDevice string `json:"new_device" binding:"required"`
-	}
+	var req attachDeviceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
 		return
 	}
 
-	if err := h.manager.AttachDevice(c.Request.Context(), pool, req.Device, req.NewDevice); err != nil {
+	if err := h.manager.AttachDevice(c.Request.Context(), pool, req.Device, req.NewDevice, req.Force); err != nil {
 		APIError(c, err)
 		return
 	}
@@ -183,9 +231,7 @@ func (h *PoolHandler) attachDevice(c *gin.Context) {
 
 func (h *PoolHandler) detachDevice(c *gin.Context) {
 	pool := c.Param("name")
-	var req struct {
-		Device string `json:"device" binding:"required"`
-	}
+	var req detachDeviceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
 		return
@@ -200,16 +246,13 @@ func (h *PoolHandler) detachDevice(c *gin.Context) {
 
 func (h *PoolHandler) replaceDevice(c *gin.Context) {
 	pool := c.Param("name")
-	var req struct {
-		OldDevice string `json:"old_device" binding:"required"`
-		NewDevice string `json:"new_device" binding:"required"`
-	}
+	var req replaceDeviceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
 		return
 	}
 
-	if err := h.manager.ReplaceDevice(c.Request.Context(), pool, req.OldDevice, req.NewDevice); err != nil {
+	if err := h.manager.ReplaceDevice(c.Request.Context(), pool, req.OldDevice, req.NewDevice, req.Force); err != nil {
 		APIError(c, err)
 		return
 	}
@@ -217,12 +260,15 @@ func (h *PoolHandler) replaceDevice(c *gin.Context) {
 }
 
 type scrubRequest struct {
-	Stop bool `json:"stop"`
+	Stop       bool   `json:"stop"`
+	Action     string `json:"action"`
+	ErrorScrub bool   `json:"error_scrub"`
 }
 
 type attachDeviceRequest struct {
 	Device    string `json:"device"     binding:"required"`
 	NewDevice string `json:"new_device" binding:"required"`
+	Force     bool   `json:"force"`
 }
 
 type detachDeviceRequest struct {
@@ -232,8 +278,335 @@ type detachDeviceRequest struct {
 type replaceDeviceRequest struct {
 	OldDevice string `json:"old_device" binding:"required"`
 	NewDevice string `json:"new_device" binding:"required"`
+	Force     bool   `json:"force"`
+}
+
+type setPoolPropertyRequest struct {
+	Value string `json:"value" binding:"required"`
+}
+
+type onlineDeviceRequest struct {
+	Devices []string `json:"devices" binding:"required"`
+	Expand  bool     `json:"expand"`
+}
+
+type offlineDeviceRequest struct {
+	Device    string `json:"device"    binding:"required"`
+	Temporary bool   `json:"temporary"`
+	Force     bool   `json:"force"`
+}
+
+type clearDeviceRequest struct {
+	Device string `json:"device"`
 }
 
 type setPropertyRequest struct {
 	Value string `json:"value" binding:"required"`
 }
+
+func (h *PoolHandler) createCheckpoint(c *gin.Context) {
+	name := c.Param("name")
+
+	if err := h.manager.Checkpoint(c.Request.Context(), name); err != nil {
+		APIError(c, err)
+		return
+	}
+	c.Status(http.StatusCreated)
+}
+
+func (h *PoolHandler) getCheckpoint(c *gin.Context) {
+	name := c.Param("name")
+
+	info, err := h.manager.CheckpointInfo(c.Request.Context(), name)
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, info)
+}
+
+func (h *PoolHandler) discardCheckpoint(c *gin.Context) {
+	name := c.Param("name")
+	wait := c.Query("wait") == "true"
+
+	if err := h.manager.DiscardCheckpoint(c.Request.Context(), name, wait); err != nil {
+		APIError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *PoolHandler) startMaintenance(c *gin.Context) {
+	name := c.Param("name")
+
+	var change pool.MaintenanceChange
+	if err := c.ShouldBindJSON(&change); err != nil {
+		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+		return
+	}
+
+	session, err := h.manager.StartMaintenance(c.Request.Context(), name, change)
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, session)
+}
+
+func (h *PoolHandler) listMaintenance(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"sessions": h.manager.ListMaintenance(c.Param("name"))})
+}
+
+// maintenanceSession looks up the session named in the URL and checks that it
+// belongs to the pool in the URL
+func (h *PoolHandler) maintenanceSession(c *gin.Context) (*pool.MaintenanceSession, bool) {
+	session, err := h.manager.GetMaintenance(c.Param("id"))
+	if err == nil && session.Pool != c.Param("name") {
+		err = errors.New(errors.ZFSPoolMaintenanceNotFound, c.Param("id"))
+	}
+	if err != nil {
+		APIError(c, err)
+		return nil, false
+	}
+	return session, true
+}
+
+func (h *PoolHandler) getMaintenance(c *gin.Context) {
+	session, ok := h.maintenanceSession(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
+
+func (h *PoolHandler) confirmMaintenance(c *gin.Context) {
+	if _, ok := h.maintenanceSession(c); !ok {
+		return
+	}
+
+	session, err := h.manager.ConfirmMaintenance(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
+
+func (h *PoolHandler) rollbackMaintenance(c *gin.Context) {
+	if _, ok := h.maintenanceSession(c); !ok {
+		return
+	}
+	force := c.Query("force") == "true"
+
+	session, err := h.manager.RollbackMaintenance(c.Request.Context(), c.Param("id"), force)
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
+
+func (h *PoolHandler) abandonMaintenance(c *gin.Context) {
+	if _, ok := h.maintenanceSession(c); !ok {
+		return
+	}
+
+	session, err := h.manager.AbandonMaintenance(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
+
+func (h *PoolHandler) addVDevs(c *gin.Context) {
+	var cfg pool.AddConfig
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+		return
+	}
+	cfg.Name = c.Param("name")
+
+	preview, err := h.manager.AddVDevs(c.Request.Context(), cfg)
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	if preview != nil {
+		c.JSON(http.StatusOK, preview)
+		return
+	}
+	c.Status(http.StatusCreated)
+}
+
+func (h *PoolHandler) removeVDevs(c *gin.Context) {
+	var cfg pool.RemoveConfig
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+		return
+	}
+	cfg.Name = c.Param("name")
+
+	preview, err := h.manager.RemoveDevice(c.Request.Context(), cfg)
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	if preview != nil {
+		c.JSON(http.StatusOK, preview)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+func (h *PoolHandler) cancelRemoval(c *gin.Context) {
+	name := c.Param("name")
+
+	if err := h.manager.CancelRemoval(c.Request.Context(), name); err != nil {
+		APIError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (h *PoolHandler) onlineDevice(c *gin.Context) {
+	pool := c.Param("name")
+	var req onlineDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+		return
+	}
+
+	if err := h.manager.OnlineDevice(c.Request.Context(), pool, req.Devices, req.Expand); err != nil {
+		APIError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (h *PoolHandler) offlineDevice(c *gin.Context) {
+	pool := c.Param("name")
+	var req offlineDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+		return
+	}
+
+	if err := h.manager.OfflineDevice(c.Request.Context(), pool, req.Device, req.Temporary, req.Force); err != nil {
+		APIError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (h *PoolHandler) clearDevice(c *gin.Context) {
+	pool := c.Param("name")
+	var req clearDeviceRequest
+	// An empty body clears errors on all devices
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+			return
+		}
+	}
+
+	if err := h.manager.ClearErrors(c.Request.Context(), pool, req.Device); err != nil {
+		APIError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (h *PoolHandler) reopenPool(c *gin.Context) {
+	name := c.Param("name")
+	noRestart := c.Query("no_restart") == "true"
+
+	if err := h.manager.Reopen(c.Request.Context(), name, noRestart); err != nil {
+		APIError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (h *PoolHandler) listSpareActions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"actions": h.manager.SpareActions(c.Param("name"))})
+}
+
+func (h *PoolHandler) initializePool(c *gin.Context) {
+	name := c.Param("name")
+
+	var cfg pool.InitializeConfig
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+		return
+	}
+
+	if err := h.manager.Initialize(c.Request.Context(), name, cfg); err != nil {
+		APIError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (h *PoolHandler) trimPool(c *gin.Context) {
+	name := c.Param("name")
+
+	var cfg pool.TrimConfig
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+		return
+	}
+
+	if err := h.manager.Trim(c.Request.Context(), name, cfg); err != nil {
+		APIError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (h *PoolHandler) scrubHistory(c *gin.Context) {
+	name := c.Param("name")
+
+	limit := 0
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 0 {
+			APIError(c, errors.New(errors.ServerRequestValidation, "invalid limit"))
+			return
+		}
+		limit = n
+	}
+
+	c.JSON(http.StatusOK, gin.H{"history": h.manager.ScrubHistory(name, limit)})
+}
+
+func (h *PoolHandler) getHistory(c *gin.Context) {
+	name := c.Param("name")
+
+	cfg := pool.HistoryConfig{
+		Internal: c.Query("internal") == "true",
+		Long:     c.Query("long") == "true",
+		Dataset:  c.Query("dataset"),
+	}
+
+	var err error
+	if since := c.Query("since"); since != "" {
+		if cfg.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			APIError(c, errors.New(errors.ServerRequestValidation, "since must be an RFC 3339 time"))
+			return
+		}
+	}
+	if until := c.Query("until"); until != "" {
+		if cfg.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			APIError(c, errors.New(errors.ServerRequestValidation, "until must be an RFC 3339 time"))
+			return
+		}
+	}
+
+	entries, err := h.manager.History(c.Request.Context(), name, cfg)
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"history": entries})
+}
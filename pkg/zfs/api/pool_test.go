@@ -29,6 +29,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stratastor/logger"
+	"github.com/stratastor/rodent/pkg/disk"
 	"github.com/stratastor/rodent/pkg/zfs/command"
 	"github.com/stratastor/rodent/pkg/zfs/pool"
 	"github.com/stratastor/rodent/pkg/zfs/testutil"
@@ -44,7 +45,7 @@ func setupPoolTestRouter(t *testing.T) (*gin.Engine, *pool.Manager, *testutil.Te
 	router.Use(ErrorHandler())
 	router.Use(gin.Recovery())
 
-	handler := NewPoolHandler(poolMgr)
+	handler := NewPoolHandler(poolMgr, disk.NewInventory(poolMgr, disk.DefaultConfig()))
 	handler.RegisterRoutes(router.Group("/api/v1"))
 
 	cleanup := func() {
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/zfs/program"
+)
+
+func NewProgramHandler(manager *program.Manager) *ProgramHandler {
+	return &ProgramHandler{manager: manager}
+}
+
+func (h *ProgramHandler) listPrograms(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"programs":     program.Scripts(),
+		"allow_custom": h.manager.AllowCustom(),
+	})
+}
+
+func (h *ProgramHandler) getProgram(c *gin.Context) {
+	script, ok := program.Lookup(c.Param("program"))
+	if !ok {
+		APIError(c, errors.New(errors.ZFSProgramNotFound, c.Param("program")))
+		return
+	}
+	c.JSON(http.StatusOK, script)
+}
+
+func (h *ProgramHandler) runProgram(c *gin.Context) {
+	var cfg program.RunConfig
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+		return
+	}
+	cfg.Name = c.Param("program")
+
+	result, err := h.manager.Run(c.Request.Context(), cfg)
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// runCustomProgram runs a caller supplied script. The caller must hold the
+// admin role, i.e. send the configured admin token as a bearer token; the
+// role is checked on every request.
+func (h *ProgramHandler) runCustomProgram(c *gin.Context) {
+	if !h.manager.AllowCustom() {
+		APIError(c, errors.New(errors.ZFSProgramCustomDenied,
+			"custom channel programs are disabled"))
+		return
+	}
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !h.manager.Admin(token) {
+		APIError(c, errors.New(errors.ZFSProgramCustomDenied,
+			"custom channel programs require the admin role"))
+		return
+	}
+
+	var cfg program.CustomConfig
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+		return
+	}
+
+	result, err := h.manager.RunCustom(c.Request.Context(), token, cfg)
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -29,7 +29,10 @@ import (
 //
 // Dataset Operations:
 //
-//	GET    /dataset              List datasets
+//	POST   /dataset/list         List datasets
+//	  Request:  {"type": "filesystem", "recursive": false}
+//	  Response: {"result": {"datasets": {...}}}
+//
 //	DELETE /dataset              Destroy dataset
 //	  Request:  {"name": "tank/ds1", "recursive_destroy_dependents": false}
 //	  Response: 204 No Content
@@ -40,11 +43,11 @@ import (
 //
 // Property Operations:
 //
-//	GET    /dataset/properties   List all properties
+//	POST   /dataset/properties/list  List all properties
 //	  Request:  {"name": "tank/ds1"}
 //	  Response: {"result": {"tank/ds1": {"properties": {...}}}}
 //
-//	GET    /dataset/property     Get specific property
+//	POST   /dataset/property/fetch   Get specific property
 //	  Request:  {"name": "tank/ds1", "property": "compression"}
 //	  Response: {"result": {"tank/ds1": {"properties": {"compression": {...}}}}}
 //
@@ -54,7 +57,7 @@ import (
 //
 // Filesystem Operations:
 //
-//	GET    /dataset/filesystems  List filesystems
+//	POST   /dataset/filesystems/list List filesystems
 //	  Request:  {"recursive": false}
 //	  Response: {"result": {"datasets": [...]}}
 //
@@ -64,7 +67,7 @@ import (
 //
 // Volume Operations:
 //
-//	GET    /dataset/volumes      List volumes
+//	POST   /dataset/volumes/list List volumes
 //	  Request:  {"recursive": false}
 //	  Response: {"result": {"datasets": [...]}}
 //
@@ -72,9 +75,20 @@ import (
 //	  Request:  {"name": "tank/vol1", "size": "10G", "properties": {...}}
 //	  Response: 201 Created
 //
+//	POST   /dataset/volume/resize  Resize volume
+//	  Request:  {"name": "tank/vol1", "size": "20G", "force": false}
+//	  Response: {"name": "tank/vol1", "old_size": 10737418240, "new_size": 21474836480,
+//	             "sparse": false, "refreservation": 21824962560,
+//	             "device": "/dev/zvol/tank/vol1", "device_size": 21474836480}
+//
+//	POST   /dataset/volume/clone   Clone volume into a new volume
+//	  Request:  {"name": "tank/vol1", "clone_name": "tank/vol2", "snap_name": "base"}
+//	  Response: 201 Created
+//	            {"name": "tank/vol2", "source": "tank/vol1", "snapshot": "tank/vol2@base"}
+//
 // Snapshot Operations:
 //
-//	GET    /dataset/snapshots    List snapshots
+//	POST   /dataset/snapshots/list List snapshots
 //	  Request:  {"name": "tank/fs1"}
 //	  Response: {"result": {"datasets": [...]}}
 //
@@ -98,7 +112,7 @@ import (
 //
 // Bookmark Operations:
 //
-//	GET    /dataset/bookmarks    List bookmarks
+//	POST   /dataset/bookmarks/list List bookmarks
 //	  Request:  {"name": "tank/fs1"}
 //	  Response: {"result": {"datasets": [...]}}
 //
@@ -108,11 +122,11 @@ import (
 //
 // Mount Operations:
 //
-//	POST   /dataset/mount        Mount dataset
+//	POST   /dataset/filesystem/mount   Mount filesystem
 //	  Request:  {"name": "tank/fs1", "force": true}
 //	  Response: 200 OK
 //
-//	POST   /dataset/unmount      Unmount dataset
+//	POST   /dataset/filesystem/unmount Unmount filesystem
 //	  Request:  {"name": "tank/fs1", "force": true}
 //	  Response: 204 No Content
 //
@@ -121,7 +135,7 @@ import (
 //	POST   /dataset/transfer/send Send dataset
 //	  Response: 200 OK
 //
-//	GET    /dataset/transfer/resume-token Get resume token
+//	POST   /dataset/transfer/resume-token/fetch Get resume token
 //	  Request:  {"name": "tank/backup"}
 //	  Response: {"result": "token-string"}
 func (h *DatasetHandler) RegisterRoutes(router *gin.RouterGroup) {
@@ -203,6 +217,17 @@ func (h *DatasetHandler) RegisterRoutes(router *gin.RouterGroup) {
 				ValidateBlockSize(),
 				ValidateZFSProperties(),
 				h.createVolume)
+
+			volume.POST("/resize",
+				ValidateZFSEntityName(common.TypeVolume),
+				ValidateVolumeSize(),
+				h.resizeVolume)
+
+			volume.POST("/clone",
+				ValidateZFSEntityName(common.TypeVolume),
+				ValidateCloneConfig(),
+				ValidateZFSProperties(),
+				h.cloneVolume)
 		}
 
 		// Snapshot operations
@@ -288,8 +313,17 @@ func (h *DatasetHandler) RegisterRoutes(router *gin.RouterGroup) {
 //
 // Pool Operations:
 //
+//	POST   /api/v1/pools/plan
+//	  Request:  {"name": "mypool", "disks": ["/dev/sdb", ..., "/dev/sdm"],
+//	             "layout": {"type": "raidz2", "width": 6}, "mountpoint": "/srv/mypool"}
+//	  Response: {"config": {...}, "ashift": 12, "raw_size": ..., "estimated_usable": ...,
+//	            "warnings": [...], "preview": "would create 'mypool' with the following layout: ..."}
+//	  Layout types: stripe, mirror, raidz1-3 (width), draid1-3 (draid_data, draid_spares).
+//	  The returned config can be submitted to POST /api/v1/pools as is.
+//
 //	POST   /api/v1/pools
-//	  Request:  {"name": "mypool", "vdev_spec": [{"type": "mirror", "devices": ["/dev/sda", "/dev/sdb"]}]}
+//	  Request:  {"name": "mypool", "vdev_spec": [{"type": "mirror", "devices": ["/dev/sda", "/dev/sdb"]}],
+//	             "features": {"encryption": true}, "mountpoint": "/srv/mypool", "altroot": "/mnt"}
 //	  Response: 201 Created
 //
 //	GET    /api/v1/pools
@@ -300,8 +334,15 @@ func (h *DatasetHandler) RegisterRoutes(router *gin.RouterGroup) {
 //
 // Import/Export:
 //
+//	GET    /api/v1/pools/importable?dir=/dev/disk/by-id&destroyed=true
+//	  Response: {"pools": [{"name": "mypool", "guid": "1535...", "state": "ONLINE",
+//	            "destroyed": false, "action": "...", "vdevs": [{"name": "mirror-0", ...}]}]}
+//
 //	POST   /api/v1/pools/import
 //	  Request:  {"name": "mypool", "force": false}
+//	  Request:  {"guid": "15351207397411524035", "new_name": "restored", "readonly": true,
+//	             "missing_log": true, "altroot": "/mnt", "cachefile": "none",
+//	             "paths": ["/dev/disk/by-id"]}
 //	  Response: 200 OK
 //
 //	POST   /api/v1/pools/:name/export
@@ -309,8 +350,11 @@ func (h *DatasetHandler) RegisterRoutes(router *gin.RouterGroup) {
 //
 // Status and Properties:
 //
-//	GET    /api/v1/pools/:name/status
+//	GET    /api/v1/pools/:name/status[?progress=true]
 //	  Response: {"name": "mypool", "state": "ONLINE", "vdevs": [...]}
+//	  With progress=true, leaf vdevs carry "initialize" and "trim" progress,
+//	  including those of log, cache, special and dedup vdevs.
+//	  Leaf vdevs carry "stable_path" (by-id/by-path link) and "kernel_name" (e.g. sdb1).
 //
 //	GET    /api/v1/pools/:name/properties/:property
 //	  Response: {"value": "on", "source": {"type": "local"}}
@@ -319,15 +363,80 @@ func (h *DatasetHandler) RegisterRoutes(router *gin.RouterGroup) {
 //	  Request:  {"value": "off"}
 //	  Response: 200 OK
 //
+// History:
+//
+//	GET    /api/v1/pools/:name/history?internal=true&long=true&since=2025-01-01T00:00:00Z&until=...&dataset=mypool/data
+//	  Response: {"history": [{"time": "...", "command": "zfs create mypool/data", "user": "root", "host": "...", "txg": 12}]}
+//
 // Maintenance:
 //
 //	POST   /api/v1/pools/:name/scrub
-//	  Request:  {"stop": false}
+//	  Request:  {"action": "start|stop|pause", "error_scrub": false}
 //	  Response: 200 OK
+//	  An empty body starts (or resumes) a scrub; ?stop=true is still accepted.
+//
+//	GET    /api/v1/pools/:name/scrub/history?limit=10
+//	  Response: {"history": [{"start_time": "...", "end_time": "...", "duration": 3600, "repaired": "0", "errors": "0"}]}
 //
 //	POST   /api/v1/pools/:name/resilver
 //	  Response: 200 OK
 //
+//	POST   /api/v1/pools/:name/initialize
+//	  Request:  {"action": "start|suspend|cancel|uninit", "devices": ["sda"], "wait": false}
+//	  Response: 200 OK
+//
+//	POST   /api/v1/pools/:name/trim
+//	  Request:  {"action": "start|suspend|cancel", "devices": ["sda"], "rate": "100M", "secure": false}
+//	  Response: 200 OK
+//	  Automatic TRIM is the "autotrim" pool property.
+//
+// VDev Operations:
+//
+//	POST   /api/v1/pools/:name/vdevs
+//	  Request:  {"vdev_spec": [{"type": "log", "children": [{"type": "mirror", "devices": ["/dev/sdc", "/dev/sdd"]}]}], "dry_run": true}
+//	  Response: 201 Created, or 200 {"pool": "mypool", "output": "would update 'mypool' to ..."} for dry runs
+//	  Refused with 409 when the redundancy doesn't match the pool, unless "force" is set.
+//
+//	POST   /api/v1/pools/:name/vdevs/remove
+//	  Request:  {"devices": ["mirror-1"], "dry_run": false, "wait": false}
+//	  Response: 202 Accepted; evacuation progress is in removal_stats of the pool status
+//
+//	DELETE /api/v1/pools/:name/vdevs/remove
+//	  Response: 200 OK (cancels an in-progress removal)
+//
+// Checkpoints:
+//
+//	POST   /api/v1/pools/:name/checkpoint
+//	  Response: 201 Created
+//
+//	GET    /api/v1/pools/:name/checkpoint
+//	  Response: {"pool": "mypool", "exists": true, "created_at": "...", "space": "1.2M"}
+//
+//	DELETE /api/v1/pools/:name/checkpoint?wait=true
+//	  Response: 204 No Content
+//
+// Maintenance Sessions:
+//
+//	POST   /api/v1/pools/:name/maintenance
+//	  Request:  {"type": "enable_feature", "feature": "draid"}
+//	  Response: 201 Created {"id": "...", "state": "applied", ...}
+//	  Takes a checkpoint and applies the change; the checkpoint is kept
+//	  until the session is confirmed or rolled back.
+//
+//	GET    /api/v1/pools/:name/maintenance
+//	GET    /api/v1/pools/:name/maintenance/:id
+//
+//	POST   /api/v1/pools/:name/maintenance/:id/confirm
+//	  Response: {"id": "...", "state": "committed", ...}
+//
+//	POST   /api/v1/pools/:name/maintenance/:id/rollback?force=true
+//	  Response: {"id": "...", "state": "rolled_back", ...}
+//	  Exports the pool and imports it with --rewind-to-checkpoint.
+//
+//	POST   /api/v1/pools/:name/maintenance/:id/abandon
+//	  Response: {"id": "...", "state": "abandoned", ...}
+//	  Discards the checkpoint without confirming, e.g. after a failed change.
+//
 // Device Operations:
 //
 //	POST   /api/v1/pools/:name/devices/attach
@@ -342,6 +451,27 @@ func (h *DatasetHandler) RegisterRoutes(router *gin.RouterGroup) {
 //	  Request:  {"old_device": "/dev/sdc", "new_device": "/dev/sdd"}
 //	  Response: 200 OK
 //
+//	POST   /api/v1/pools/:name/devices/online
+//	  Request:  {"devices": ["/dev/sdc"], "expand": false}
+//	  Response: 200 OK
+//
+//	POST   /api/v1/pools/:name/devices/offline
+//	  Request:  {"device": "/dev/sdc", "temporary": true, "force": false}
+//	  Response: 200 OK
+//
+//	POST   /api/v1/pools/:name/devices/clear
+//	  Request:  {"device": "/dev/sdc"} (empty body clears all devices)
+//	  Response: 200 OK
+//
+//	POST   /api/v1/pools/:name/reopen?no_restart=true
+//	  Response: 200 OK
+//
+// Hot Spares:
+//
+//	GET    /api/v1/pools/:name/spares/actions
+//	  Response: {"actions": [{"time": "...", "device": "/dev/sdc", "spare": "/dev/sdf", "success": true}]}
+//	  Actions taken by the auto-replace monitor (zfs.autoReplace.enabled).
+//
 // Error Responses:
 //
 //	400 Bad Request:      Invalid input (name, device path, property)
@@ -355,13 +485,18 @@ func (h *PoolHandler) RegisterRoutes(router *gin.RouterGroup) {
 		pools.POST("",
 			ValidatePoolName(),
 			ValidateNameLength(),
-			EnhancedValidateDevicePaths(),
+			EnhancedValidateDevicePaths(h.disks),
 			ValidatePoolProperties(common.CreatePoolPropContext),
 			h.createPool)
+		pools.POST("/plan",
+			ValidatePlanDisks(h.disks),
+			ValidatePoolProperties(common.CreatePoolPropContext),
+			h.planPool)
 		pools.GET("", h.listPools)
 		pools.DELETE("/:name", ValidatePoolName(), h.destroyPool)
 
 		// Import/Export
+		pools.GET("/importable", h.listImportable)
 		pools.POST("/import",
 			ValidatePoolProperties(common.ImportPoolPropContext),
 			h.importPool)
@@ -372,6 +507,7 @@ func (h *PoolHandler) RegisterRoutes(router *gin.RouterGroup) {
 		pools.GET("/:name/properties",
 			ValidatePoolName(),
 			h.getProperties)
+		pools.GET("/:name/history", ValidatePoolName(), h.getHistory)
 		pools.GET("/:name/properties/:property",
 			ValidatePoolName(),
 			ValidatePoolProperty(common.ValidPoolGetPropContext),
@@ -384,16 +520,293 @@ func (h *PoolHandler) RegisterRoutes(router *gin.RouterGroup) {
 
 		// Maintenance
 		pools.POST("/:name/scrub", ValidatePoolName(), h.scrubPool)
+		pools.GET("/:name/scrub/history", ValidatePoolName(), h.scrubHistory)
 		pools.POST("/:name/resilver", ValidatePoolName(), h.resilverPool)
+		pools.POST("/:name/initialize", ValidatePoolName(), h.initializePool)
+		pools.POST("/:name/trim", ValidatePoolName(), h.trimPool)
+
+		// Checkpoints
+		checkpoint := pools.Group("/:name/checkpoint", ValidatePoolName())
+		{
+			checkpoint.POST("", h.createCheckpoint)
+			checkpoint.GET("", h.getCheckpoint)
+			checkpoint.DELETE("", h.discardCheckpoint)
+		}
+
+		// Checkpoint guarded maintenance sessions
+		maintenance := pools.Group("/:name/maintenance", ValidatePoolName())
+		{
+			maintenance.POST("", ValidateMaintenanceDevices(h.disks), h.startMaintenance)
+			maintenance.GET("", h.listMaintenance)
+			maintenance.GET("/:id", h.getMaintenance)
+			maintenance.POST("/:id/confirm", h.confirmMaintenance)
+			maintenance.POST("/:id/rollback", h.rollbackMaintenance)
+			maintenance.POST("/:id/abandon", h.abandonMaintenance)
+		}
 
 		// Device operations
 		devices := pools.Group("/:name/devices", ValidatePoolName())
 		{
-			// TODO: Validate device paths
-			devices.POST("/attach", h.attachDevice)
-			devices.POST("/detach", h.detachDevice)
+			devices.POST("/attach",
+				ValidateMemberDevices(),
+				ValidateNewDevice(h.disks),
+				h.attachDevice)
+			devices.POST("/detach", ValidateMemberDevices(), h.detachDevice)
 			devices.POST("/replace",
+				ValidateMemberDevices(),
+				ValidateNewDevice(h.disks),
 				h.replaceDevice)
+			devices.POST("/online", ValidateMemberDevices(), h.onlineDevice)
+			devices.POST("/offline", ValidateMemberDevices(), h.offlineDevice)
+			devices.POST("/clear", ValidateMemberDevices(), h.clearDevice)
+		}
+		pools.POST("/:name/reopen", ValidatePoolName(), h.reopenPool)
+		pools.GET("/:name/spares/actions", ValidatePoolName(), h.listSpareActions)
+
+		// VDev operations
+		vdevs := pools.Group("/:name/vdevs", ValidatePoolName())
+		{
+			vdevs.POST("", ValidateAddVDevs(h.disks), h.addVDevs)
+			vdevs.POST("/remove", ValidateRemoveDevices(), h.removeVDevs)
+			vdevs.DELETE("/remove", h.cancelRemoval)
 		}
 	}
 }
+
+// API Routes
+//
+// Channel Programs:
+//
+//	GET    /api/v1/programs
+//	  Response: {"programs": [{"name": "snapshot_recursive", "params": [...]}], "allow_custom": false}
+//
+//	GET    /api/v1/programs/:program
+//	  Response: {"name": "snapshot_recursive", "params": [...], "source": "..."}
+//
+//	POST   /api/v1/programs/:program/run
+//	  Request:  {"pool": "tank", "args": {"dataset": "tank/data", "snapname": "daily"}}
+//	  Response: {"program": "snapshot_recursive", "pool": "tank", "return": {...}}
+//
+//	POST   /api/v1/programs/custom
+//	  Request:  {"pool": "tank", "source": "return {}", "args": [], "read_only": true}
+//	  Response: {"program": "custom", "pool": "tank", "return": {...}}
+//	  Only available when zfs.channelPrograms.allowCustom and adminToken are set,
+//	  and only to callers with the admin role: each request must send the admin
+//	  token as "Authorization: Bearer <token>". Rodent has no user accounts, so
+//	  the token stands in for an admin role. Refused with 403 otherwise.
+func (h *ProgramHandler) RegisterRoutes(router *gin.RouterGroup) {
+	programs := router.Group("/programs")
+	{
+		programs.GET("", h.listPrograms)
+		programs.POST("/custom", h.runCustomProgram)
+		programs.GET("/:program", h.getProgram)
+		programs.POST("/:program/run", h.runProgram)
+	}
+}
+
+// API Routes
+//
+// Disks:
+//
+//	GET    /api/v1/disks[?available=true]
+//	  Response: {"disks": [{"name": "sdb", "path": "/dev/sdb", "by_id": ["/dev/disk/by-id/ata-..."],
+//	            "size": 4000787030016, "rotational": true, "model": "...", "serial": "...",
+//	            "partitions": [...], "mounts": [], "pool": "tank", "zfs_label": true,
+//	            "fs_type": "zfs_member", "root": false, "in_use": true}]}
+//	  With available=true, only disks that are unused and not the root disk.
+//	  Active swap is listed in mounts as "[SWAP]". Disks with a zfs_label or
+//	  fs_type are still listed but need force to be used for a pool.
+//
+//	GET    /api/v1/disks/:name
+//	  Response: {"name": "sdb", ...}
+//	  name is a kernel name such as sdb or nvme0n1.
+func (h *DiskHandler) RegisterRoutes(router *gin.RouterGroup) {
+	disks := router.Group("/disks")
+	{
+		disks.GET("", h.listDisks)
+		disks.GET("/:name", h.getDisk)
+	}
+}
+
+// API Routes
+//
+// NFS Shares:
+//
+//	GET    /api/v1/shares/nfs[?dataset=tank/data]
+//	  Response: {"shares": [{"dataset": "tank/data", "clients": [...], "options": {...},
+//	            "mountpoint": "/tank/data", "sharenfs": "rw=@10.0.0.0/24,ro=*",
+//	            "exports": [{"path": "/tank/data", "host": "10.0.0.0/24", "options": ["rw", ...]}]}]}
+//	  With dataset, the share of that filesystem alone.
+//
+//	PUT    /api/v1/shares/nfs
+//	  Request:  {"dataset": "tank/data",
+//	             "clients": [{"host": "10.0.0.0/24", "access": "rw"}, {"host": "*", "access": "ro"}],
+//	             "options": {"squash": "root", "sec": ["krb5p"], "anonuid": 65534, "crossmnt": true}}
+//	  Response: the share as stored
+//	  Creates or replaces the share with a single zfs set sharenfs.
+//
+//	DELETE /api/v1/shares/nfs
+//	  Request:  {"dataset": "tank/data"}
+//	  Response: 204 No Content
+//
+//	PUT    /api/v1/shares/nfs/clients
+//	  Request:  {"dataset": "tank/data", "host": "backup.example.com", "access": "rw"}
+//	  Response: the updated share
+//
+//	DELETE /api/v1/shares/nfs/clients
+//	  Request:  {"dataset": "tank/data", "host": "backup.example.com"}
+//	  Response: the updated share
+func (h *NFSHandler) RegisterRoutes(router *gin.RouterGroup) {
+	shares := router.Group("/shares/nfs")
+	{
+		shares.GET("", h.listShares)
+		shares.PUT("", ValidateShareDataset(), h.setShare)
+		shares.DELETE("", ValidateShareDataset(), h.deleteShare)
+		shares.PUT("/clients", ValidateShareDataset(), h.setClient)
+		shares.DELETE("/clients", ValidateShareDataset(), h.removeClient)
+	}
+}
+
+// API Routes
+//
+// SMB Shares:
+//
+//	GET    /api/v1/shares/smb/status
+//	  Response: {"installed": true, "active": true, "testparm": true,
+//	             "include_file": "/etc/samba/rodent-shares.conf", "included": true}
+//
+//	GET    /api/v1/shares/smb
+//	  Response: {"shares": [{"name": "data", "dataset": "tank/data", "path": "/tank/data", ...}]}
+//
+//	GET    /api/v1/shares/smb/:name
+//	  Response: the share
+//
+//	POST   /api/v1/shares/smb
+//	  Request:  {"name": "data", "dataset": "tank/data", "comment": "Team data",
+//	             "valid_users": ["alice", "@staff"], "read_only": false,
+//	             "shadow_copy": {"format": "@GMT-%Y.%m.%d-%H.%M.%S"}}
+//	  Response: 201 Created with the share
+//
+//	PUT    /api/v1/shares/smb/:name
+//	  Request:  the share; a different name renames it
+//	  Response: the share
+//
+//	DELETE /api/v1/shares/smb/:name
+//	  Response: 204 No Content
+func (h *SMBHandler) RegisterRoutes(router *gin.RouterGroup) {
+	shares := router.Group("/shares/smb")
+	{
+		shares.GET("/status", h.getStatus)
+		shares.GET("", h.listShares)
+		shares.POST("", ValidateShareDataset(), h.createShare)
+		shares.GET("/:name", h.getShare)
+		shares.PUT("/:name", ValidateShareDataset(), h.updateShare)
+		shares.DELETE("/:name", h.deleteShare)
+	}
+}
+
+// API Routes
+//
+// iSCSI Exports:
+//
+// Volume names carry slashes. They may be given as they are or escaped as
+// %2F: /api/v1/volumes/tank/vol1/iscsi or /api/v1/volumes/tank%2Fvol1/iscsi.
+// The routes are registered as /volumes/*path, which must end in /iscsi.
+//
+//	GET    /api/v1/iscsi/exports
+//	  Response: {"exports": [{"volume": "tank/vol1", "iqn": "iqn.2024-01.in.tinkershack.rodent:tank.vol1",
+//	             "lun": 0, "portals": ["0.0.0.0:3260"], "device": "/dev/zvol/tank/vol1",
+//	             "acls": [{"initiator": "iqn.1994-05.com.redhat:client1", "chap": {"userid": "client1"}}]}]}
+//
+//	GET    /api/v1/volumes/:name/iscsi
+//	  Response: the export of the volume; CHAP secrets are never returned
+//
+//	PUT    /api/v1/volumes/:name/iscsi
+//	  Request:  {"lun": 0, "portals": ["10.0.0.1:3260"],
+//	             "acls": [{"initiator": "iqn.1994-05.com.redhat:client1",
+//	                       "chap": {"userid": "client1", "password": "secret-secret"}}]}
+//	  Response: the export
+//	  Creates the target or updates its portals and ACLs. The IQN defaults to the
+//	  base IQN followed by the volume name.
+//
+//	DELETE /api/v1/volumes/:name/iscsi
+//	  Response: 204 No Content
+func (h *ISCSIHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/iscsi/exports", h.listExports)
+
+	volumes := router.Group("/volumes", ValidateVolumeName())
+	{
+		volumes.GET("/*path", h.getExport)
+		volumes.PUT("/*path", h.setExport)
+		volumes.DELETE("/*path", h.deleteExport)
+	}
+}
+
+// API Routes
+//
+// Capabilities:
+//
+//	GET    /api/v1/capabilities[?refresh=true]
+//	  Response: {"version": "2.2.2", "kmod_version": "2.2.2",
+//	             "features": {"json": false, "raw_send": true, "saved_send": true, "draid": true},
+//	             "commands": {"zfs": ["create", "destroy", ...], "zpool": ["add", "attach", ...]},
+//	             "pool_features": {"tank": {"async_destroy": "enabled", "draid": "disabled", ...}},
+//	             "probed_at": "2025-01-01T00:00:00Z"}
+//	  Versions and subcommands are probed at startup, or again with refresh=true.
+//	  Options the release lacks are rejected with CMD error 1310 (501).
+func (h *CapabilitiesHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/capabilities", h.getCapabilities)
+}
+
+// API Routes
+//
+// Executor:
+//
+//	GET    /api/v1/executor/queue
+//	  Response: {"max_concurrent": 16, "queue_depth": 64, "running": 3,
+//	             "classes": [{"class": "interactive-mutating", "limit": 12, "running": 1, "queued": 0,
+//	                          "admitted": 120, "rejected": 0, "avg_wait_ms": 0.4, "max_wait_ms": 35,
+//	                          "avg_run_ms": 210}, ...]}
+//	  Commands beyond a class's queue depth fail with CMD error 1311 (503)
+//	  and a Retry-After header.
+func (h *ExecutorHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/executor/queue", h.getQueueStats)
+}
+
+// API Routes
+//
+// Errors:
+//
+//	GET    /api/v1/errors[?domain=ZFS]
+//	  Response: {"errors": [{"code": 2092, "name": "ZFSNotFound", "domain": "ZFS",
+//	                         "message": "ZFS dataset, pool or device not found", "http_status": 404}, ...]}
+//
+//	GET    /api/v1/errors/:code
+//	  Response: {"code": 2092, "name": "ZFSNotFound", ...}
+//
+//	GET    /api/v1/errors/openapi
+//	  Response: {"components": {"schemas": {"ErrorCode": ..., "ErrorDomain": ..., "RodentError": ...},
+//	                            "responses": {"Error404": ..., ...}}}
+func (h *ErrorCatalogHandler) RegisterRoutes(router *gin.RouterGroup) {
+	errs := router.Group("/errors")
+	{
+		errs.GET("", h.listErrors)
+		errs.GET("/openapi", h.getOpenAPIComponents)
+		errs.GET("/:code", h.getError)
+	}
+}
+
+// API Routes
+//
+// OpenAPI:
+//
+//	GET    /api/v1/openapi.json
+//	  Response: OpenAPI 3 document of every /api/v1 route, with the request
+//	            and response schemas and the error catalog components
+//
+//	GET    /api/v1/docs
+//	  Response: HTML viewer of the document
+func (h *OpenAPIHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/openapi.json", h.getSpec)
+	router.GET("/docs", h.getDocs)
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/share/smb"
+	"github.com/stratastor/rodent/pkg/zfs/common"
+)
+
+func NewSMBHandler(manager *smb.Manager) *SMBHandler {
+	return &SMBHandler{manager: manager}
+}
+
+func (h *SMBHandler) getStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.manager.Status(c.Request.Context()))
+}
+
+func (h *SMBHandler) listShares(c *gin.Context) {
+	shares, err := h.manager.List(c.Request.Context())
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"shares": shares})
+}
+
+func (h *SMBHandler) getShare(c *gin.Context) {
+	share, err := h.manager.Get(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, share)
+}
+
+func (h *SMBHandler) createShare(c *gin.Context) {
+	share, ok := bindSMBShare(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.manager.Create(c.Request.Context(), share)
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+func (h *SMBHandler) updateShare(c *gin.Context) {
+	share, ok := bindSMBShare(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.manager.Update(c.Request.Context(), c.Param("name"), share)
+	if err != nil {
+		APIError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *SMBHandler) deleteShare(c *gin.Context) {
+	if err := h.manager.Delete(c.Request.Context(), c.Param("name")); err != nil {
+		APIError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// bindSMBShare binds a share from the request body and validates its
+// dataset name
+func bindSMBShare(c *gin.Context) (smb.Share, bool) {
+	var share smb.Share
+	if err := c.ShouldBindJSON(&share); err != nil {
+		APIError(c, errors.New(errors.ServerRequestValidation, err.Error()))
+		return share, false
+	}
+	if err := common.ValidateZFSName(share.Dataset, common.TypeFilesystem); err != nil {
+		APIError(c, err)
+		return share, false
+	}
+	return share, true
+}
@@ -18,8 +18,18 @@
 package api
 
 import (
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stratastor/rodent/pkg/disk"
+	"github.com/stratastor/rodent/pkg/openapi"
+	"github.com/stratastor/rodent/pkg/share/iscsi"
+	"github.com/stratastor/rodent/pkg/share/nfs"
+	"github.com/stratastor/rodent/pkg/share/smb"
+	"github.com/stratastor/rodent/pkg/zfs/command"
 	"github.com/stratastor/rodent/pkg/zfs/dataset"
 	"github.com/stratastor/rodent/pkg/zfs/pool"
+	"github.com/stratastor/rodent/pkg/zfs/program"
 )
 
 // DatasetHandler provides HTTP endpoints for ZFS dataset operations.
@@ -43,9 +53,91 @@ type DatasetHandler struct {
 //   - Device management (attach/detach/replace)
 //   - Maintenance operations (scrub/resilver)
 //
+// New devices are checked against the disk inventory, when one is set, so
+// that only unused disks off the root disk are accepted.
+//
 // All operations use proper validation and error handling.
 type PoolHandler struct {
 	manager *pool.Manager
+	disks   *disk.Inventory
+}
+
+// DiskHandler provides HTTP endpoints for the disk inventory.
+// It implements the following features:
+//   - Listing disks with size, rotational flag, model/serial and by-id links
+//   - Partitions, mounts and ZFS membership of each disk
+//   - Filtering disks available for new pools and vdevs
+type DiskHandler struct {
+	inventory *disk.Inventory
+}
+
+// ProgramHandler provides HTTP endpoints for ZFS channel programs.
+// It implements the following features:
+//   - Listing the vetted script library
+//   - Running library scripts with validated parameters
+//   - Running custom scripts when allowed by configuration
+type ProgramHandler struct {
+	manager *program.Manager
+}
+
+// NFSHandler provides HTTP endpoints for NFS shares of filesystems.
+// It implements the following features:
+//   - Share model of clients and options rendered into sharenfs
+//   - Live export state from the kernel export table
+//   - Atomic replacement of shares and of single clients
+type NFSHandler struct {
+	manager *nfs.Manager
+}
+
+// SMBHandler provides HTTP endpoints for Samba shares of filesystems.
+// It implements the following features:
+//   - Share stanzas generated into an smb.conf include file
+//   - Snapshots exposed as Previous Versions through shadow_copy2
+//   - Validation with testparm and smbd reload on every change
+type SMBHandler struct {
+	manager *smb.Manager
+}
+
+// ISCSIHandler provides HTTP endpoints for iSCSI exports of volumes.
+// It implements the following features:
+//   - LIO targets with one LUN mapped to the zvol device
+//   - ACLs by initiator name with optional CHAP credentials
+//   - Portal management per export
+type ISCSIHandler struct {
+	manager *iscsi.Manager
+}
+
+// CapabilitiesHandler provides HTTP endpoints for capability discovery.
+// It implements the following features:
+//   - Installed OpenZFS userland and kernel module versions
+//   - Release dependent features and zfs/zpool subcommands
+//   - Feature flags of imported pools
+type CapabilitiesHandler struct {
+	executor *command.CommandExecutor
+}
+
+// ExecutorHandler provides HTTP endpoints for the command executor.
+// It implements the following features:
+//   - Concurrency limits, running and queued commands per priority class
+//   - Time commands spent queued and running
+type ExecutorHandler struct {
+	executor *command.CommandExecutor
+}
+
+// ErrorCatalogHandler provides HTTP endpoints for the error catalog.
+// It implements the following features:
+//   - Every error code with its name, domain, message and HTTP status
+//   - The catalog as an OpenAPI 3 components section
+type ErrorCatalogHandler struct{}
+
+// OpenAPIHandler provides HTTP endpoints for the API description.
+// It implements the following features:
+//   - An OpenAPI 3 document generated from the registered routes
+//   - A docs viewer for the document
+type OpenAPIHandler struct {
+	routes func() gin.RoutesInfo
+	once   sync.Once
+	doc    *openapi.Document
 }
 
 // Request types
@@ -91,3 +183,13 @@ type mountRequest struct {
 type Property = dataset.Property
 type Dataset = dataset.Dataset
 type SnapshotInfo = dataset.SnapshotInfo
+
+type nfsDatasetRequest struct {
+	Dataset string `json:"dataset" binding:"required"`
+}
+
+type nfsClientRequest struct {
+	Dataset string `json:"dataset" binding:"required"`
+	Host    string `json:"host"    binding:"required"`
+	Access  string `json:"access"`
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+// binaryDirs are searched in order for zfs and zpool when no path is
+// configured
+var binaryDirs = []string{"/usr/sbin", "/sbin", "/usr/local/sbin"}
+
+// binaryOwner is the uid the binaries must be owned by
+var binaryOwner uint32 = 0
+
+// defaultBinary returns the first name found in binaryDirs, or the first
+// candidate path if there is none
+func defaultBinary(name string) string {
+	for _, dir := range binaryDirs {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(binaryDirs[0], name)
+}
+
+// ResolveBinaries sets BinZFS and BinZpool to the configured paths, or
+// searches the standard locations for paths left empty. Each binary must
+// be an executable owned by root and not writable by others. It must be
+// called before any command is executed.
+func ResolveBinaries(zfs, zpool string) error {
+	zfsPath, err := resolveBinary("zfs", zfs)
+	if err != nil {
+		return err
+	}
+	zpoolPath, err := resolveBinary("zpool", zpool)
+	if err != nil {
+		return err
+	}
+
+	BinZFS, BinZpool = zfsPath, zpoolPath
+	return nil
+}
+
+func resolveBinary(name, configured string) (string, error) {
+	if configured != "" {
+		if !filepath.IsAbs(configured) {
+			return "", errors.New(errors.CommandInvalidInput,
+				fmt.Sprintf("%s binary path must be absolute", name)).
+				WithMetadata("path", configured)
+		}
+		return configured, checkBinary(configured)
+	}
+
+	for _, dir := range binaryDirs {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		// The first match is what an administrator would run; don't
+		// skip past one that fails the checks
+		return path, checkBinary(path)
+	}
+	return "", errors.New(errors.CommandNotFound, fmt.Sprintf("%s binary not found", name)).
+		WithMetadata("searched", strings.Join(binaryDirs, ":"))
+}
+
+// checkBinary verifies that path is an executable file owned by root that
+// only root can modify
+func checkBinary(path string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return errors.Wrap(err, errors.CommandNotFound).WithMetadata("path", path)
+	}
+
+	mode := fi.Mode()
+	switch {
+	case !mode.IsRegular() || mode.Perm()&0111 == 0:
+		return errors.New(errors.CommandPermission, "not an executable file").
+			WithMetadata("path", path)
+	case mode.Perm()&0002 != 0:
+		return errors.New(errors.CommandPermission, "binary is world-writable").
+			WithMetadata("path", path)
+	}
+
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok || st.Uid != binaryOwner {
+		return errors.New(errors.CommandPermission, "binary is not owned by root").
+			WithMetadata("path", path)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveBinaries(t *testing.T) {
+	dirs, owner, zfs, zpool := binaryDirs, binaryOwner, BinZFS, BinZpool
+	t.Cleanup(func() {
+		binaryDirs, binaryOwner, BinZFS, BinZpool = dirs, owner, zfs, zpool
+	})
+
+	tmp := t.TempDir()
+	sbin := filepath.Join(tmp, "sbin")
+	local := filepath.Join(tmp, "local")
+	for _, dir := range []string{sbin, local} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(path string, mode os.FileMode) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+		// WriteFile is subject to the umask
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(sbin, "zpool"), 0755)
+	write(filepath.Join(local, "zfs"), 0755)
+	write(filepath.Join(local, "zpool"), 0755)
+	write(filepath.Join(tmp, "writable"), 0777)
+	write(filepath.Join(tmp, "noexec"), 0644)
+
+	binaryDirs = []string{sbin, local}
+	binaryOwner = uint32(os.Getuid())
+
+	if err := ResolveBinaries("", ""); err != nil {
+		t.Fatalf("ResolveBinaries() = %v", err)
+	}
+	if BinZFS != filepath.Join(local, "zfs") || BinZpool != filepath.Join(sbin, "zpool") {
+		t.Errorf("resolved %s, %s", BinZFS, BinZpool)
+	}
+
+	if err := ResolveBinaries(filepath.Join(local, "zfs"), filepath.Join(local, "zpool")); err != nil {
+		t.Fatalf("ResolveBinaries() with configured paths = %v", err)
+	}
+	if BinZpool != filepath.Join(local, "zpool") {
+		t.Errorf("configured zpool not used: %s", BinZpool)
+	}
+
+	for name, zfsPath := range map[string]string{
+		"relative":       "sbin/zfs",
+		"missing":        filepath.Join(tmp, "missing"),
+		"world-writable": filepath.Join(tmp, "writable"),
+		"not executable": filepath.Join(tmp, "noexec"),
+		"directory":      sbin,
+	} {
+		if err := ResolveBinaries(zfsPath, ""); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	binaryOwner = uint32(os.Getuid()) + 1
+	if err := ResolveBinaries("", ""); err == nil {
+		t.Error("expected an error for binaries not owned by root")
+	}
+
+	binaryDirs = []string{filepath.Join(tmp, "empty")}
+	if err := ResolveBinaries("", ""); err == nil {
+		t.Error("expected an error when no binary is found")
+	}
+}
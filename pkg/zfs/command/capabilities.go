@@ -0,0 +1,246 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+// Capabilities describes what the installed OpenZFS release and the
+// imported pools support
+type Capabilities struct {
+	Version     string `json:"version"`
+	KmodVersion string `json:"kmod_version,omitempty"`
+	// Userland features, e.g. json or raw_send
+	Features map[string]bool `json:"features"`
+	// Subcommands of zfs and zpool
+	Commands map[string][]string `json:"commands"`
+	// feature@ states of each pool: enabled, active or disabled
+	PoolFeatures map[string]map[string]string `json:"pool_features"`
+	ProbedAt     time.Time                    `json:"probed_at"`
+}
+
+// subcommandRegex matches the subcommand lines of zfs/zpool --help:
+//
+//	create [-Pnpuv] [-o property=value] ... <filesystem>
+var subcommandRegex = regexp.MustCompile(`^\t([a-z][a-z-]*)\b`)
+
+// Probe detects the installed release, the subcommands of zfs and zpool
+// and the feature flags of imported pools. Probing is best effort: what
+// can't be detected is assumed to be supported, and a version that failed
+// to be detected is detected again on the next command that needs it.
+func (e *CommandExecutor) Probe(ctx context.Context) (Capabilities, error) {
+	e.detectMu.Lock()
+	err := e.probeVersion(ctx)
+	e.detectMu.Unlock()
+	if err != nil {
+		return e.Capabilities(), err
+	}
+
+	commands := make(map[string]bool)
+	for _, bin := range []string{"zfs", "zpool"} {
+		out, err := e.Execute(ctx, CommandOptions{}, bin, "--help")
+		if err != nil {
+			return e.Capabilities(), err
+		}
+		for _, sub := range parseSubcommands(string(out)) {
+			commands[bin+" "+sub] = true
+		}
+	}
+
+	e.mu.Lock()
+	e.commands = commands
+	e.probedAt = time.Now()
+	e.mu.Unlock()
+
+	if err := e.ProbePoolFeatures(ctx); err != nil {
+		return e.Capabilities(), err
+	}
+	return e.Capabilities(), nil
+}
+
+// ProbePoolFeatures refreshes the feature flags of all imported pools
+func (e *CommandExecutor) ProbePoolFeatures(ctx context.Context) error {
+	features, err := e.readPoolFeatures(ctx, "all", "")
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.poolFeatures = features
+	e.mu.Unlock()
+	return nil
+}
+
+// Capabilities returns the result of the last probe
+func (e *CommandExecutor) Capabilities() Capabilities {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	c := Capabilities{
+		Version:      e.zfsVersion,
+		KmodVersion:  e.kmodVersion,
+		Features:     make(map[string]bool, len(e.features)),
+		Commands:     make(map[string][]string),
+		PoolFeatures: make(map[string]map[string]string, len(e.poolFeatures)),
+		ProbedAt:     e.probedAt,
+	}
+	for k, v := range e.features {
+		c.Features[k] = v
+	}
+	for cmd := range e.commands {
+		bin, sub, _ := strings.Cut(cmd, " ")
+		c.Commands[bin] = append(c.Commands[bin], sub)
+	}
+	for _, subs := range c.Commands {
+		sort.Strings(subs)
+	}
+	for pool, features := range e.poolFeatures {
+		c.PoolFeatures[pool] = make(map[string]string, len(features))
+		for k, v := range features {
+			c.PoolFeatures[pool][k] = v
+		}
+	}
+	return c
+}
+
+// HasCommand reports whether a subcommand such as "zpool wait" exists.
+// Before the first probe every subcommand is assumed to exist.
+func (e *CommandExecutor) HasCommand(cmd string) bool {
+	parts := strings.Fields(cmd)
+	if len(parts) < 2 {
+		return true
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return len(e.commands) == 0 || e.commands[parts[0]+" "+parts[1]]
+}
+
+// Require returns a CommandUnsupported error if the installed release
+// lacks a feature
+func (e *CommandExecutor) Require(ctx context.Context, feature string) error {
+	if e.HasFeature(ctx, feature) {
+		return nil
+	}
+
+	release := releaseFeatures[feature]
+	return errors.New(errors.CommandUnsupported,
+		fmt.Sprintf("%s requires OpenZFS %d.%d or later", feature, release[0], release[1])).
+		WithMetadata("feature", feature).
+		WithMetadata("version", e.Version(ctx))
+}
+
+// RequirePoolFeature returns a ZFSPoolFeatureDisabled error unless the
+// feature@ flag is enabled or active on the pool. The flag is read from the
+// pool, since it changes with zpool upgrade and set.
+func (e *CommandExecutor) RequirePoolFeature(ctx context.Context, pool, feature string) error {
+	features, err := e.readPoolFeatures(ctx, "feature@"+feature, pool)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	if e.poolFeatures == nil {
+		e.poolFeatures = make(map[string]map[string]string)
+	}
+	if e.poolFeatures[pool] == nil {
+		e.poolFeatures[pool] = make(map[string]string)
+	}
+	for k, v := range features[pool] {
+		e.poolFeatures[pool][k] = v
+	}
+	e.mu.Unlock()
+
+	switch state := features[pool][feature]; state {
+	case "enabled", "active":
+		return nil
+	case "":
+		return errors.New(errors.ZFSPoolFeatureDisabled,
+			fmt.Sprintf("pool %s doesn't know feature %s", pool, feature)).
+			WithMetadata("pool", pool).
+			WithMetadata("feature", feature)
+	default:
+		return errors.New(errors.ZFSPoolFeatureDisabled,
+			fmt.Sprintf("feature %s is %s on pool %s; enable it with zpool set feature@%s=enabled",
+				feature, state, pool, feature)).
+			WithMetadata("pool", pool).
+			WithMetadata("feature", feature)
+	}
+}
+
+// readPoolFeatures reads feature@ properties of one or all pools
+func (e *CommandExecutor) readPoolFeatures(
+	ctx context.Context,
+	property, pool string,
+) (map[string]map[string]string, error) {
+	args := []string{"get", "-H", "-o", "name,property,value", property}
+	if pool != "" {
+		args = append(args, pool)
+	}
+
+	out, err := e.Execute(ctx, CommandOptions{}, "zpool get", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return nil, errors.Wrap(err, errors.ZFSPoolGetProperty).
+				WithMetadata("output", string(out))
+		}
+		return nil, errors.Wrap(err, errors.ZFSPoolGetProperty)
+	}
+	return parsePoolFeatures(string(out)), nil
+}
+
+// parseSubcommands extracts subcommand names from zfs/zpool --help
+func parseSubcommands(out string) []string {
+	seen := make(map[string]bool)
+	var subs []string
+	for _, line := range strings.Split(out, "\n") {
+		m := subcommandRegex.FindStringSubmatch(line)
+		if m == nil || seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		subs = append(subs, m[1])
+	}
+	return subs
+}
+
+// parsePoolFeatures parses zpool get -H -o name,property,value output,
+// keeping only feature@ properties
+func parsePoolFeatures(out string) map[string]map[string]string {
+	features := make(map[string]map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 || !strings.HasPrefix(fields[1], "feature@") {
+			continue
+		}
+		pool := fields[0]
+		if features[pool] == nil {
+			features[pool] = make(map[string]string)
+		}
+		features[pool][strings.TrimPrefix(fields[1], "feature@")] = fields[2]
+	}
+	return features
+}
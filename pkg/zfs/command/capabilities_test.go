@@ -0,0 +1,146 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stratastor/logger"
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+const zfsHelpOutput = "usage: zfs command args ...\n" +
+	"where 'command' is one of the following:\n" +
+	"\n" +
+	"\tversion\n" +
+	"\n" +
+	"\tcreate [-Pnpuv] [-o property=value] ... <filesystem>\n" +
+	"\tcreate [-Pnpsuv] [-b blocksize] [-o property=value] ... -V <size> <volume>\n" +
+	"\tdestroy [-fnpRrv] <filesystem|volume>\n" +
+	"\n" +
+	"\tsend [-DLPbcehnpsvw] [-i|-I snapshot]\n" +
+	"\t    [-R [-X dataset[,dataset]...]]     <snapshot>\n" +
+	"\tsend --saved [-PVcensv] <dataset|bookmark>\n" +
+	"\tredact <snapshot> <bookmark> <redaction_snapshot> ...\n" +
+	"\n" +
+	"Each dataset is of the form: pool/[dataset/]*dataset[@name]\n" +
+	"\n" +
+	"For the property list, run: zfs set|get\n"
+
+func TestParseSubcommands(t *testing.T) {
+	got := parseSubcommands(zfsHelpOutput)
+	want := []string{"version", "create", "destroy", "send", "redact"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSubcommands() = %v, want %v", got, want)
+	}
+}
+
+func TestParsePoolFeatures(t *testing.T) {
+	out := "tank\tfeature@async_destroy\tenabled\n" +
+		"tank\tfeature@draid\tdisabled\n" +
+		"tank\tsize\t21474836480\n" +
+		"backup\tfeature@draid\tactive\n"
+
+	got := parsePoolFeatures(out)
+	want := map[string]map[string]string{
+		"tank":   {"async_destroy": "enabled", "draid": "disabled"},
+		"backup": {"draid": "active"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePoolFeatures() = %v, want %v", got, want)
+	}
+}
+
+func TestRequire(t *testing.T) {
+	ctx := context.Background()
+	e := NewCommandExecutor(false, logger.Config{LogLevel: "error"})
+	e.SetVersion("2.2.2")
+
+	for feature, supported := range map[string]bool{
+		FeatureJSON:        false,
+		FeatureProgramJSON: true,
+		FeatureRawSend:     true,
+		FeatureSavedSend:   true,
+		FeatureDRAID:       true,
+		"unknown":          true,
+	} {
+		err := e.Require(ctx, feature)
+		if supported != (err == nil) {
+			t.Errorf("Require(%s) = %v, want supported %v", feature, err, supported)
+		}
+		if err != nil && errorCode(err) != errors.CommandUnsupported {
+			t.Errorf("Require(%s) returned %v, want CommandUnsupported", feature, err)
+		}
+	}
+
+	// -j is refused before zfs runs
+	_, err := e.Execute(ctx, CommandOptions{Flags: FlagJSON}, "zfs list")
+	if errorCode(err) != errors.CommandUnsupported {
+		t.Errorf("Execute with -j on 2.2 returned %v, want CommandUnsupported", err)
+	}
+
+	caps := e.Capabilities()
+	if caps.Version != "2.2.2" || caps.Features[FeatureJSON] || !caps.Features[FeatureDRAID] {
+		t.Errorf("unexpected capabilities: %+v", caps)
+	}
+}
+
+func TestProgramJSONBefore23(t *testing.T) {
+	ctx := context.Background()
+	e := NewCommandExecutor(false, logger.Config{LogLevel: "error"})
+	e.SetVersion("2.2.2")
+
+	// zfs program has taken -j since 0.8, so channel programs keep
+	// running on releases without -j elsewhere
+	args, err := e.prepare(ctx, CommandOptions{Flags: FlagJSON}, "zfs program",
+		"-n", "-t", "1000", "-m", "1000", "tank", "/tmp/script.zcp")
+	if err != nil {
+		t.Fatalf("zfs program -j on 2.2 returned %v", err)
+	}
+	if !strings.Contains(strings.Join(args, " "), " -j ") {
+		t.Errorf("zfs program args %v lack -j", args)
+	}
+
+	e.SetVersion("0.7.13")
+	_, err = e.prepare(ctx, CommandOptions{Flags: FlagJSON}, "zfs program", "tank", "/tmp/script.zcp")
+	if errorCode(err) != errors.CommandUnsupported {
+		t.Errorf("zfs program -j on 0.7 returned %v, want CommandUnsupported", err)
+	}
+}
+
+func TestHasCommand(t *testing.T) {
+	e := NewCommandExecutor(false, logger.Config{LogLevel: "error"})
+	if !e.HasCommand("zpool wait") {
+		t.Error("commands should be assumed to exist before probing")
+	}
+
+	e.commands = map[string]bool{"zfs list": true}
+	if !e.HasCommand("zfs list") || e.HasCommand("zpool wait") || !e.HasCommand("zfs") {
+		t.Error("unexpected HasCommand result after probing")
+	}
+}
+
+func errorCode(err error) errors.ErrorCode {
+	if re, ok := err.(*errors.RodentError); ok {
+		return re.Code
+	}
+	return 0
+}
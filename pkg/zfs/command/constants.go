@@ -21,16 +21,24 @@ package command
 
 import "time"
 
-const (
-	// Base commands
-	// TODO: Make these configurable?
-	BinZFS   = "/usr/local/sbin/zfs"
-	BinZpool = "/usr/local/sbin/zpool"
+// Base commands. They default to the first found in binaryDirs;
+// ResolveBinaries replaces them with the configured or discovered paths,
+// verified, at startup. Commands run with any other binary are rejected.
+var (
+	BinZFS   = defaultBinary("zfs")
+	BinZpool = defaultBinary("zpool")
+)
 
+const (
 	maxCommandArgs = 64
 
 	// Default timeout for command execution
 	DefaultTimeout = 30 * time.Second
+
+	// Default timeout for streamed commands. They run at the pace of their
+	// reader and hold a worker meanwhile, so a stalled reader mustn't hold
+	// it indefinitely.
+	DefaultStreamTimeout = 10 * time.Minute
 )
 
 // Dangerous characters that could enable command injection
@@ -46,6 +54,7 @@ var JSONSupportedCommands = map[string]bool{
 	"zpool status":  true,
 	"zpool version": true,
 	"zpool history": true,
+	"zfs program":   true,
 }
 
 // Commands that require sudo
@@ -64,6 +73,7 @@ var SudoRequiredCommands = map[string]bool{
 	"zfs unallow":      true,
 	"zfs share":        true,
 	"zfs unshare":      true,
+	"zfs program":      true,
 	"zpool create":     true,
 	"zpool destroy":    true,
 	"zpool import":     true,
@@ -73,4 +83,15 @@ var SudoRequiredCommands = map[string]bool{
 	"zpool attach":     true,
 	"zpool detach":     true,
 	"zpool set":        true,
+	"zpool checkpoint": true,
+	"zpool upgrade":    true,
+	"zpool add":        true,
+	"zpool remove":     true,
+	"zpool replace":    true,
+	"zpool online":     true,
+	"zpool offline":    true,
+	"zpool clear":      true,
+	"zpool reopen":     true,
+	"zpool trim":       true,
+	"zpool history":    true,
 }
@@ -36,7 +36,20 @@ type CommandExecutor struct {
 	mu           sync.RWMutex
 	zfsVersion   string
 	zpoolVersion string
+	kmodVersion  string
 	features     map[string]bool // Supported ZFS features
+	// detectMu serializes the lazy detection of the installed version;
+	// detected is set once it succeeds
+	detectMu sync.Mutex
+	detected bool
+	// Subcommands listed by zfs/zpool --help, keyed by "zfs <subcommand>"
+	commands map[string]bool
+	// Pool feature@ states (enabled, active or disabled) keyed by pool
+	poolFeatures map[string]map[string]string
+	probedAt     time.Time
+
+	// queue bounds the commands running at once
+	queue *scheduler
 
 	useSudo bool          // Whether to use sudo for privileged commands
 	timeout time.Duration // Default command timeout
@@ -73,6 +86,7 @@ func NewCommandExecutor(useSudo bool, logConfig logger.Config) *CommandExecutor
 
 	return &CommandExecutor{
 		features: make(map[string]bool),
+		queue:    newScheduler(DefaultQueueConfig()),
 		useSudo:  useSudo,
 		logger:   l,
 	}
@@ -84,27 +98,19 @@ func (e *CommandExecutor) Execute(
 	cmd string,
 	args ...string,
 ) ([]byte, error) {
-	e.mu.RLock()
-	defer e.mu.RUnlock()
-
-	// Split command to get base command (zfs/zpool)
-	parts := strings.Fields(cmd)
-	if len(parts) == 0 {
-		return nil, errors.New(errors.CommandNotFound, "empty command")
-	}
-
-	// Validate command and arguments
-	if err := e.validateCommand(parts[0], args); err != nil {
+	cmdArgs, err := e.prepare(ctx, opts, cmd, args...)
+	if err != nil {
 		return nil, err
 	}
 
-	// Build command with security checks
-	cmdArgs := e.buildCommandArgs(cmd, opts, args...)
-
-	// Additional security checks for built command
-	if err := e.validateBuiltCommand(cmdArgs); err != nil {
+	release, err := e.queue.acquire(ctx, priorityOf(ctx, cmd))
+	if err != nil {
 		return nil, err
 	}
+	defer release()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 
 	// Set timeout
 	if opts.Timeout == 0 {
@@ -195,6 +201,48 @@ func (e *CommandExecutor) Execute(
 	}
 }
 
+// prepare rejects what the installed release doesn't support and returns
+// the validated command line
+func (e *CommandExecutor) prepare(
+	ctx context.Context,
+	opts CommandOptions,
+	cmd string,
+	args ...string,
+) ([]string, error) {
+	// Split command to get base command (zfs/zpool)
+	parts := strings.Fields(cmd)
+	if len(parts) == 0 {
+		return nil, errors.New(errors.CommandNotFound, "empty command")
+	}
+
+	// Validate command and arguments
+	if err := e.validateCommand(parts[0], args); err != nil {
+		return nil, err
+	}
+
+	// Reject what the installed release doesn't support up front rather
+	// than surfacing a zfs usage message
+	if !e.HasCommand(cmd) {
+		return nil, errors.New(errors.CommandUnsupported,
+			fmt.Sprintf("%s is not supported by the installed ZFS release", cmd)).
+			WithMetadata("command", cmd)
+	}
+	if opts.Flags&FlagJSON != 0 && JSONSupportedCommands[cmd] {
+		if err := e.Require(ctx, jsonFeature(cmd)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Build command with security checks
+	cmdArgs := e.buildCommandArgs(cmd, opts, args...)
+
+	// Additional security checks for built command
+	if err := e.validateBuiltCommand(cmdArgs); err != nil {
+		return nil, err
+	}
+	return cmdArgs, nil
+}
+
 func (e *CommandExecutor) buildCommandArgs(
 	cmd string,
 	opts CommandOptions,
@@ -279,7 +327,7 @@ func (e *CommandExecutor) validateBuiltCommand(args []string) error {
 		return errors.New(errors.CommandInvalidInput, "empty command")
 	}
 
-	// Ensure first argument is an absolute path to zfs/zpool
+	// Ensure first argument is the resolved zfs/zpool binary
 	switch args[0] {
 	case "sudo":
 		if len(args) < 2 {
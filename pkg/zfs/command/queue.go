@@ -0,0 +1,371 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+// Priority is the class a command is scheduled in. Lower values are
+// dispatched first when a worker frees up.
+type Priority int
+
+const (
+	// PriorityMutating is for commands an API caller waits on that change
+	// pools or datasets, e.g. zfs snapshot
+	PriorityMutating Priority = iota
+	// PriorityRead is for commands an API caller waits on that only read
+	// state, e.g. zfs list
+	PriorityRead
+	// PriorityBackground is for periodic and housekeeping work such as
+	// the scrub scheduler
+	PriorityBackground
+
+	numPriorities = 3
+)
+
+var priorityNames = [numPriorities]string{"interactive-mutating", "interactive-read", "background"}
+
+func (p Priority) String() string {
+	if p < 0 || p >= numPriorities {
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+	return priorityNames[p]
+}
+
+// Subcommands that only read state. Commands not listed here, and not
+// marked otherwise with WithPriority, are scheduled as mutating.
+var readOnlyCommands = map[string]bool{
+	"zfs list":         true,
+	"zfs get":          true,
+	"zfs diff":         true,
+	"zfs holds":        true,
+	"zfs userspace":    true,
+	"zfs groupspace":   true,
+	"zfs projectspace": true,
+	"zfs version":      true,
+	"zfs --help":       true,
+	"zpool list":       true,
+	"zpool get":        true,
+	"zpool status":     true,
+	"zpool iostat":     true,
+	"zpool history":    true,
+	"zpool version":    true,
+	"zpool --help":     true,
+}
+
+type priorityKey struct{}
+
+// WithPriority schedules the commands run with the returned context in
+// class p instead of the class derived from the command
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// priorityOf returns the class of cmd run with ctx
+func priorityOf(ctx context.Context, cmd string) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok && p >= 0 && p < numPriorities {
+		return p
+	}
+	if readOnlyCommands[strings.Join(strings.Fields(cmd), " ")] {
+		return PriorityRead
+	}
+	return PriorityMutating
+}
+
+// QueueConfig bounds the zfs/zpool processes run at once
+type QueueConfig struct {
+	// MaxConcurrent caps the commands running across all classes
+	MaxConcurrent int
+	// Limits caps the commands running in each class, indexed by Priority.
+	// Keeping the interactive read limit below MaxConcurrent leaves room
+	// for mutating commands during bursts of reads.
+	Limits [numPriorities]int
+	// QueueDepth caps the commands waiting in each class; commands beyond
+	// it fail with CommandQueueFull
+	QueueDepth int
+}
+
+// DefaultQueueConfig returns the limits used unless SetQueueConfig is called
+func DefaultQueueConfig() QueueConfig {
+	return QueueConfig{
+		MaxConcurrent: 16,
+		Limits:        [numPriorities]int{12, 8, 2},
+		QueueDepth:    64,
+	}
+}
+
+// ClassStats are the queue metrics of one priority class
+type ClassStats struct {
+	Class    string `json:"class"`
+	Limit    int    `json:"limit"`
+	Running  int    `json:"running"`
+	Queued   int    `json:"queued"`
+	Admitted uint64 `json:"admitted"`
+	Rejected uint64 `json:"rejected"`
+	// Time commands spent queued before they started
+	AvgWaitMs float64 `json:"avg_wait_ms"`
+	MaxWaitMs float64 `json:"max_wait_ms"`
+	// Time commands held a worker
+	AvgRunMs float64 `json:"avg_run_ms"`
+}
+
+// QueueStats are the executor's queue metrics
+type QueueStats struct {
+	MaxConcurrent int          `json:"max_concurrent"`
+	QueueDepth    int          `json:"queue_depth"`
+	Running       int          `json:"running"`
+	Classes       []ClassStats `json:"classes"`
+}
+
+// scheduler admits commands into a bounded set of workers. Waiting
+// commands are started highest class first and in arrival order within a
+// class.
+type scheduler struct {
+	mu      sync.Mutex
+	cfg     QueueConfig
+	running int
+	classes [numPriorities]classState
+}
+
+type classState struct {
+	running int
+	waiting []*waiter
+
+	admitted  uint64
+	rejected  uint64
+	waitTotal time.Duration
+	waitMax   time.Duration
+	completed uint64
+	runTotal  time.Duration
+}
+
+type waiter struct {
+	ready    chan struct{}
+	queuedAt time.Time
+	granted  bool
+}
+
+func newScheduler(cfg QueueConfig) *scheduler {
+	s := &scheduler{}
+	s.configure(cfg)
+	return s
+}
+
+// configure replaces the limits; zero values keep the defaults. Commands
+// already running or queued are not affected, except that lifting a limit
+// may start queued ones.
+func (s *scheduler) configure(cfg QueueConfig) {
+	def := DefaultQueueConfig()
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = def.MaxConcurrent
+	}
+	if cfg.QueueDepth <= 0 {
+		cfg.QueueDepth = def.QueueDepth
+	}
+	for p := range cfg.Limits {
+		if cfg.Limits[p] <= 0 {
+			cfg.Limits[p] = def.Limits[p]
+		}
+		if cfg.Limits[p] > cfg.MaxConcurrent {
+			cfg.Limits[p] = cfg.MaxConcurrent
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg = cfg
+	s.dispatch()
+}
+
+// acquire waits for a worker in class p. The returned func releases it and
+// must be called once the command has exited.
+func (s *scheduler) acquire(ctx context.Context, p Priority) (func(), error) {
+	s.mu.Lock()
+	c := &s.classes[p]
+	if len(c.waiting) == 0 && s.canRun(p) {
+		s.start(p, 0)
+		s.mu.Unlock()
+		return s.releaser(p), nil
+	}
+	if len(c.waiting) >= s.cfg.QueueDepth {
+		c.rejected++
+		retry := s.retryAfter(p)
+		s.mu.Unlock()
+		return nil, errors.New(errors.CommandQueueFull,
+			fmt.Sprintf("%d %s commands are already queued", s.cfg.QueueDepth, p)).
+			WithMetadata("priority", p.String()).
+			WithMetadata("retry_after", fmt.Sprintf("%d", retry))
+	}
+	w := &waiter{ready: make(chan struct{}), queuedAt: time.Now()}
+	c.waiting = append(c.waiting, w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return s.releaser(p), nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		granted := w.granted
+		if !granted {
+			s.remove(p, w)
+		}
+		s.mu.Unlock()
+		if granted {
+			// Started just as ctx was done; hand the worker on
+			s.releaser(p)()
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, errors.New(errors.CommandTimeout, "timed out waiting in the command queue")
+		}
+		return nil, errors.Wrap(ctx.Err(), errors.CommandContext)
+	}
+}
+
+func (s *scheduler) canRun(p Priority) bool {
+	return s.running < s.cfg.MaxConcurrent && s.classes[p].running < s.cfg.Limits[p]
+}
+
+// start accounts for a command of class p taking a worker. Called with
+// s.mu held.
+func (s *scheduler) start(p Priority, wait time.Duration) {
+	c := &s.classes[p]
+	s.running++
+	c.running++
+	c.admitted++
+	c.waitTotal += wait
+	if wait > c.waitMax {
+		c.waitMax = wait
+	}
+}
+
+func (s *scheduler) releaser(p Priority) func() {
+	started := time.Now()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			c := &s.classes[p]
+			s.running--
+			c.running--
+			c.completed++
+			c.runTotal += time.Since(started)
+			s.dispatch()
+		})
+	}
+}
+
+// dispatch starts queued commands while workers are free. Called with s.mu
+// held.
+func (s *scheduler) dispatch() {
+	now := time.Now()
+	for p := Priority(0); p < numPriorities; p++ {
+		c := &s.classes[p]
+		for len(c.waiting) > 0 && s.canRun(p) {
+			w := c.waiting[0]
+			c.waiting[0] = nil
+			c.waiting = c.waiting[1:]
+			s.start(p, now.Sub(w.queuedAt))
+			w.granted = true
+			close(w.ready)
+		}
+	}
+}
+
+func (s *scheduler) remove(p Priority, w *waiter) {
+	c := &s.classes[p]
+	for i, q := range c.waiting {
+		if q == w {
+			c.waiting = append(c.waiting[:i], c.waiting[i+1:]...)
+			return
+		}
+	}
+}
+
+// retryAfter estimates the seconds until the queue of class p has room:
+// about the average run time of its commands, by when one of the running
+// commands will have exited and the first queued one started. Called with
+// s.mu held.
+func (s *scheduler) retryAfter(p Priority) int {
+	c := &s.classes[p]
+	avg := time.Second
+	if c.completed > 0 {
+		avg = c.runTotal / time.Duration(c.completed)
+	}
+	secs := int(math.Ceil(avg.Seconds()))
+	switch {
+	case secs < 1:
+		return 1
+	case secs > 60:
+		return 60
+	}
+	return secs
+}
+
+func (s *scheduler) stats() QueueStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := QueueStats{
+		MaxConcurrent: s.cfg.MaxConcurrent,
+		QueueDepth:    s.cfg.QueueDepth,
+		Running:       s.running,
+	}
+	for p := Priority(0); p < numPriorities; p++ {
+		c := &s.classes[p]
+		cs := ClassStats{
+			Class:     p.String(),
+			Limit:     s.cfg.Limits[p],
+			Running:   c.running,
+			Queued:    len(c.waiting),
+			Admitted:  c.admitted,
+			Rejected:  c.rejected,
+			MaxWaitMs: milliseconds(c.waitMax),
+		}
+		if c.admitted > 0 {
+			cs.AvgWaitMs = milliseconds(c.waitTotal / time.Duration(c.admitted))
+		}
+		if c.completed > 0 {
+			cs.AvgRunMs = milliseconds(c.runTotal / time.Duration(c.completed))
+		}
+		st.Classes = append(st.Classes, cs)
+	}
+	return st
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// SetQueueConfig replaces the concurrency limits of the executor
+func (e *CommandExecutor) SetQueueConfig(cfg QueueConfig) {
+	e.queue.configure(cfg)
+}
+
+// QueueStats returns the queue metrics of the executor
+func (e *CommandExecutor) QueueStats() QueueStats {
+	return e.queue.stats()
+}
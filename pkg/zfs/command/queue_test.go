@@ -0,0 +1,162 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+// waitQueued waits until n commands of class p are queued
+func waitQueued(t *testing.T, s *scheduler, p Priority, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for s.stats().Classes[p].Queued != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s: want %d queued, have %d", p, n, s.stats().Classes[p].Queued)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSchedulerLimits(t *testing.T) {
+	s := newScheduler(QueueConfig{MaxConcurrent: 2, Limits: [numPriorities]int{2, 1, 1}, QueueDepth: 1})
+	ctx := context.Background()
+
+	releaseRead, err := s.acquire(ctx, PriorityRead)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The read class is at its limit, so the next read is queued...
+	queued := make(chan func())
+	go func() {
+		release, err := s.acquire(ctx, PriorityRead)
+		if err != nil {
+			t.Error(err)
+		}
+		queued <- release
+	}()
+	waitQueued(t, s, PriorityRead, 1)
+
+	// ...and the one after that doesn't fit the queue
+	_, err = s.acquire(ctx, PriorityRead)
+	if errorCode(err) != errors.CommandQueueFull {
+		t.Fatalf("got %v, want CommandQueueFull", err)
+	}
+	if re := err.(*errors.RodentError); re.HTTPStatus != 503 || re.Metadata["retry_after"] != "1" {
+		t.Errorf("got status %d, metadata %v", re.HTTPStatus, re.Metadata)
+	}
+
+	// Reads don't hold up mutating commands
+	releaseMutating, err := s.acquire(ctx, PriorityMutating)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st := s.stats()
+	if st.Running != 2 || st.Classes[PriorityRead].Rejected != 1 {
+		t.Errorf("got %+v", st)
+	}
+
+	releaseMutating()
+	releaseRead()
+	(<-queued)()
+	// Releasing twice is harmless
+	releaseRead()
+
+	st = s.stats()
+	if st.Running != 0 || st.Classes[PriorityRead].Admitted != 2 {
+		t.Errorf("got %+v", st)
+	}
+}
+
+func TestSchedulerPriority(t *testing.T) {
+	s := newScheduler(QueueConfig{MaxConcurrent: 1, Limits: [numPriorities]int{1, 1, 1}, QueueDepth: 4})
+	ctx := context.Background()
+
+	release, err := s.acquire(ctx, PriorityMutating)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan Priority, numPriorities)
+	for _, p := range []Priority{PriorityBackground, PriorityRead, PriorityMutating} {
+		go func(p Priority) {
+			release, err := s.acquire(ctx, p)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			started <- p
+			release()
+		}(p)
+		waitQueued(t, s, p, 1)
+	}
+
+	release()
+	for _, want := range []Priority{PriorityMutating, PriorityRead, PriorityBackground} {
+		if got := <-started; got != want {
+			t.Errorf("started %s, want %s", got, want)
+		}
+	}
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	s := newScheduler(QueueConfig{MaxConcurrent: 1, QueueDepth: 4})
+
+	release, err := s.acquire(context.Background(), PriorityMutating)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = s.acquire(ctx, PriorityRead)
+	if errorCode(err) != errors.CommandTimeout {
+		t.Errorf("got %v, want CommandTimeout", err)
+	}
+	if q := s.stats().Classes[PriorityRead].Queued; q != 0 {
+		t.Errorf("%d commands still queued", q)
+	}
+}
+
+func TestPriorityOf(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		ctx  context.Context
+		cmd  string
+		want Priority
+	}{
+		{ctx, "zfs list", PriorityRead},
+		{ctx, "zpool status", PriorityRead},
+		{ctx, "zfs snapshot", PriorityMutating},
+		{ctx, "zpool scrub", PriorityMutating},
+		{WithPriority(ctx, PriorityBackground), "zpool scrub", PriorityBackground},
+		{WithPriority(ctx, PriorityBackground), "zpool status", PriorityBackground},
+	}
+	for _, tt := range tests {
+		if got := priorityOf(tt.ctx, tt.cmd); got != tt.want {
+			t.Errorf("priorityOf(%q) = %s, want %s", tt.cmd, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+// maxStderr bounds the stderr kept of a streaming command
+const maxStderr = 64 * 1024
+
+// maxLine bounds a single line read by Stream.Lines
+const maxLine = 1024 * 1024
+
+// Stream is the stdout of a running command. Read it to the end, or Close
+// it to stop the command early, then call Wait for the exit status.
+type Stream struct {
+	stdout  io.ReadCloser
+	cmd     *exec.Cmd
+	cancel  context.CancelFunc
+	release func()
+	ctx     context.Context
+	command string
+	stderr  limitedBuffer
+
+	mu      sync.Mutex
+	eof     bool
+	stopped bool
+
+	waitOnce sync.Once
+	waitErr  error
+}
+
+// ExecuteStream starts a command and returns its stdout without buffering
+// it. Commands are validated like Execute. As output is consumed at the
+// pace of the reader, the command is stopped after DefaultStreamTimeout
+// unless opts.Timeout is set.
+func (e *CommandExecutor) ExecuteStream(
+	ctx context.Context,
+	opts CommandOptions,
+	cmd string,
+	args ...string,
+) (*Stream, error) {
+	cmdArgs, err := e.prepare(ctx, opts, cmd, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// The worker is held until the output has been read or the command
+	// has exited, see Read and Wait
+	release, err := e.queue.acquire(ctx, priorityOf(ctx, cmd))
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultStreamTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+
+	e.logger.Debug("Streaming command", "cmd", strings.Join(cmdArgs, " "))
+
+	execCmd := exec.CommandContext(ctx, cmdArgs[0], cmdArgs[1:]...)
+	// Prevent shell expansion
+	execCmd.Env = []string{}
+
+	s := &Stream{
+		cmd:     execCmd,
+		cancel:  cancel,
+		release: release,
+		ctx:     ctx,
+		command: strings.Join(cmdArgs, " "),
+		stderr:  limitedBuffer{limit: maxStderr},
+	}
+	execCmd.Stderr = &s.stderr
+
+	s.stdout, err = execCmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		release()
+		return nil, errors.Wrap(err, errors.CommandPipe)
+	}
+
+	if err := execCmd.Start(); err != nil {
+		cancel()
+		release()
+		return nil, errors.NewCommandError(
+			s.command,
+			-1,
+			fmt.Sprintf("failed to start command: %v", err),
+		)
+	}
+	return s, nil
+}
+
+func (s *Stream) Read(p []byte) (int, error) {
+	n, err := s.stdout.Read(p)
+	if err == io.EOF {
+		s.mu.Lock()
+		s.eof = true
+		s.mu.Unlock()
+		// The command is done writing; free the worker without waiting on
+		// the consumer to call Wait
+		s.release()
+	}
+	return n, err
+}
+
+// Close stops the command if its output hasn't been read to the end and
+// releases its resources. Wait reports the exit status.
+func (s *Stream) Close() error {
+	s.mu.Lock()
+	if !s.eof {
+		s.stopped = true
+		s.cancel()
+	}
+	s.mu.Unlock()
+
+	err := s.Wait()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return nil
+	}
+	return err
+}
+
+// Wait waits for the command to exit. It returns the same errors Execute
+// does; a command stopped by Close is not an error.
+func (s *Stream) Wait() error {
+	s.waitOnce.Do(func() {
+		err := s.cmd.Wait()
+		ctxErr := s.ctx.Err()
+		s.cancel()
+		s.release()
+
+		s.mu.Lock()
+		stopped := s.stopped
+		s.mu.Unlock()
+
+		switch {
+		case err == nil || stopped:
+		case ctxErr == context.DeadlineExceeded:
+			s.waitErr = errors.New(errors.CommandTimeout, "command execution timed out")
+		case ctxErr != nil:
+			s.waitErr = errors.Wrap(ctxErr, errors.CommandContext).
+				WithMetadata("command", s.command)
+		default:
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				s.waitErr = errors.NewCommandError(s.command, exitErr.ExitCode(), s.stderr.String())
+			} else {
+				s.waitErr = errors.Wrap(err, errors.CommandExecution).
+					WithMetadata("command", s.command).
+					WithMetadata("stderr", s.stderr.String())
+			}
+		}
+	})
+	return s.waitErr
+}
+
+// Lines calls fn for each line of output, without the line ending, and
+// waits for the command. An error from fn stops the command.
+func (s *Stream) Lines(fn func(line string) error) error {
+	scanner := bufio.NewScanner(s)
+	scanner.Buffer(make([]byte, 64*1024), maxLine)
+	for scanner.Scan() {
+		if err := fn(scanner.Text()); err != nil {
+			s.Close()
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		s.Close()
+		if werr := s.Wait(); werr != nil {
+			return werr
+		}
+		return errors.Wrap(err, errors.CommandOutputParse)
+	}
+	return s.Wait()
+}
+
+// JSONObjects decodes -j output one entry at a time: fn is called with the
+// key and raw value of each member of the top level object field, e.g.
+// each dataset of
+//
+//	{"output_version": {...}, "datasets": {"tank": {...}, "tank/a": {...}}}
+//
+// so memory use doesn't grow with the number of entries. An error from fn
+// stops the command.
+func (s *Stream) JSONObjects(field string, fn func(key string, value json.RawMessage) error) error {
+	err := decodeJSONObjects(json.NewDecoder(s), field, fn)
+	if err != nil {
+		s.Close()
+		// A failed command leaves incomplete output; report why it failed
+		if werr := s.Wait(); werr != nil {
+			return werr
+		}
+		return err
+	}
+	// Drain what follows the object so the command can exit
+	if _, err := io.Copy(io.Discard, s); err != nil {
+		s.Close()
+	}
+	return s.Wait()
+}
+
+func decodeJSONObjects(
+	dec *json.Decoder,
+	field string,
+	fn func(key string, value json.RawMessage) error,
+) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		key, err := decodeKey(dec)
+		if err != nil {
+			return err
+		}
+		if key != field {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return errors.Wrap(err, errors.CommandOutputParse)
+			}
+			continue
+		}
+
+		if err := expectDelim(dec, '{'); err != nil {
+			return err
+		}
+		for dec.More() {
+			name, err := decodeKey(dec)
+			if err != nil {
+				return err
+			}
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return errors.Wrap(err, errors.CommandOutputParse)
+			}
+			if err := fn(name, value); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(dec, '}'); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return errors.Wrap(err, errors.CommandOutputParse)
+	}
+	if d, ok := tok.(json.Delim); !ok || d != delim {
+		return errors.New(errors.CommandOutputParse, fmt.Sprintf("expected %q, got %v", delim, tok))
+	}
+	return nil
+}
+
+func decodeKey(dec *json.Decoder) (string, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return "", errors.Wrap(err, errors.CommandOutputParse)
+	}
+	key, ok := tok.(string)
+	if !ok {
+		return "", errors.New(errors.CommandOutputParse, fmt.Sprintf("expected object key, got %v", tok))
+	}
+	return key, nil
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards
+// the rest
+type limitedBuffer struct {
+	mu    sync.Mutex
+	buf   []byte
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if room := b.limit - len(b.buf); room > 0 {
+		if len(p) < room {
+			room = len(p)
+		}
+		b.buf = append(b.buf, p[:room]...)
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
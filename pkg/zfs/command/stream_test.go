@@ -0,0 +1,201 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stratastor/logger"
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+// fakeZFS stands in for the zfs binary; it answers by subcommand
+const fakeZFS = `#!/bin/sh
+case "$1" in
+version)
+	echo zfs-2.3.0-1
+	echo zfs-kmod-2.3.0-1
+	;;
+list)
+	echo '{"output_version": {"command": "zfs list"},'
+	echo ' "datasets": {"tank": {"name": "tank", "type": "FILESYSTEM"},'
+	echo '  "tank@a": {"name": "tank@a", "type": "SNAPSHOT"}}}'
+	;;
+diff)
+	i=0
+	while [ $i -lt 100000 ]; do
+		echo "1700000000.000000001	M	F	/tank/file$i"
+		i=$((i+1))
+	done
+	;;
+*)
+	echo "cannot open '$2': dataset does not exist" >&2
+	exit 1
+	;;
+esac
+`
+
+func newStreamExecutor(t *testing.T) *CommandExecutor {
+	t.Helper()
+	bin := filepath.Join(t.TempDir(), "zfs")
+	if err := os.WriteFile(bin, []byte(fakeZFS), 0755); err != nil {
+		t.Fatal(err)
+	}
+	zfs := BinZFS
+	BinZFS = bin
+	t.Cleanup(func() { BinZFS = zfs })
+
+	return NewCommandExecutor(false, logger.Config{LogLevel: "error"})
+}
+
+func TestExecuteStreamLines(t *testing.T) {
+	e := newStreamExecutor(t)
+
+	stream, err := e.ExecuteStream(context.Background(), CommandOptions{}, "zfs diff", "diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	if err := stream.Lines(func(line string) error {
+		if !strings.HasSuffix(line, "/tank/file"+strconv.Itoa(count)) {
+			t.Fatalf("line %d: %q", count, line)
+		}
+		count++
+		return nil
+	}); err != nil {
+		t.Fatalf("Lines() = %v", err)
+	}
+	if count != 100000 {
+		t.Errorf("got %d lines, want 100000", count)
+	}
+}
+
+func TestExecuteStreamEarlyClose(t *testing.T) {
+	e := newStreamExecutor(t)
+
+	stream, err := e.ExecuteStream(context.Background(), CommandOptions{}, "zfs diff", "diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := errors.New(errors.ServerResponseError, "client went away")
+	count := 0
+	err = stream.Lines(func(string) error {
+		if count++; count == 10 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Fatalf("Lines() = %v, want the callback error", err)
+	}
+	if err := stream.Wait(); err != nil {
+		t.Errorf("Wait() after Close = %v, want nil", err)
+	}
+}
+
+func TestExecuteStreamReleasesWorkerAtEOF(t *testing.T) {
+	e := newStreamExecutor(t)
+
+	stream, err := e.ExecuteStream(context.Background(), CommandOptions{}, "zfs diff", "diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if running := e.QueueStats().Running; running != 1 {
+		t.Fatalf("running = %d while streaming, want 1", running)
+	}
+	if _, err := io.Copy(io.Discard, stream); err != nil {
+		t.Fatal(err)
+	}
+	// The consumer hasn't called Wait yet
+	if running := e.QueueStats().Running; running != 0 {
+		t.Errorf("running = %d after EOF, want 0", running)
+	}
+	if err := stream.Wait(); err != nil {
+		t.Errorf("Wait() = %v", err)
+	}
+}
+
+func TestExecuteStreamFailure(t *testing.T) {
+	e := newStreamExecutor(t)
+
+	stream, err := e.ExecuteStream(context.Background(), CommandOptions{}, "zfs get", "get", "tank/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = stream.Lines(func(string) error { return nil })
+	re, ok := err.(*errors.RodentError)
+	if !ok || re.Code != errors.ZFSNotFound ||
+		!strings.Contains(re.Metadata["stderr"], "dataset does not exist") {
+		t.Errorf("Lines() = %v, want ZFSNotFound with stderr", err)
+	}
+}
+
+func TestExecuteStreamJSON(t *testing.T) {
+	e := newStreamExecutor(t)
+
+	stream, err := e.ExecuteStream(context.Background(), CommandOptions{Flags: FlagJSON}, "zfs list", "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	if err := stream.JSONObjects("datasets", func(key string, value json.RawMessage) error {
+		names = append(names, key)
+		return nil
+	}); err != nil {
+		t.Fatalf("JSONObjects() = %v", err)
+	}
+	if want := []string{"tank", "tank@a"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got %v, want %v", names, want)
+	}
+}
+
+func TestDecodeJSONObjects(t *testing.T) {
+	in := `{"output_version": {"command": "zfs list", "vers_major": 0},
+		"datasets": {"a": {"n": 1}, "b": {"n": [2, {"x": "}"}]}},
+		"trailer": [1, 2]}`
+
+	got := map[string]string{}
+	err := decodeJSONObjects(json.NewDecoder(strings.NewReader(in)), "datasets",
+		func(key string, value json.RawMessage) error {
+			got[key] = string(value)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"a": `{"n": 1}`, "b": `{"n": [2, {"x": "}"}]}`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{`[]`, `{"datasets": [1]}`, `{"datasets": {"a": 1`} {
+		err := decodeJSONObjects(json.NewDecoder(strings.NewReader(bad)), "datasets",
+			func(string, json.RawMessage) error { return nil })
+		if err == nil {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+}
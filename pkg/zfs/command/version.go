@@ -0,0 +1,196 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+// Features that depend on the installed OpenZFS release
+const (
+	// FeatureJSON is the -j output of zfs/zpool list, get and status
+	FeatureJSON = "json"
+	// FeatureProgramJSON is the -j output of zfs program, which predates
+	// the -j of the other commands
+	FeatureProgramJSON = "program_json"
+	// FeatureRawSend is zfs send -w of encrypted datasets
+	FeatureRawSend = "raw_send"
+	// FeatureSavedSend is zfs send --saved of partially received datasets
+	FeatureSavedSend = "saved_send"
+	// FeatureDRAID is the draid vdev type
+	FeatureDRAID = "draid"
+)
+
+// releaseFeatures maps features to the OpenZFS release that added them
+var releaseFeatures = map[string][2]int{
+	FeatureJSON:        {2, 3},
+	FeatureProgramJSON: {0, 8},
+	FeatureRawSend:     {0, 8},
+	FeatureSavedSend:   {2, 0},
+	FeatureDRAID:       {2, 1},
+}
+
+// jsonFeatures maps commands whose -j isn't FeatureJSON to their feature
+var jsonFeatures = map[string]string{
+	"zfs program": FeatureProgramJSON,
+}
+
+// jsonFeature returns the feature that gates -j of a command
+func jsonFeature(cmd string) string {
+	if feature, ok := jsonFeatures[cmd]; ok {
+		return feature
+	}
+	return FeatureJSON
+}
+
+// Version returns the installed OpenZFS userland version, e.g. 2.2.2, or
+// an empty string when it couldn't be detected
+func (e *CommandExecutor) Version(ctx context.Context) string {
+	e.detect(ctx)
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.zfsVersion
+}
+
+// HasFeature reports whether the installed release supports a feature.
+// When the version can't be detected the current release is assumed.
+func (e *CommandExecutor) HasFeature(ctx context.Context, feature string) bool {
+	e.detect(ctx)
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	supported, ok := e.features[feature]
+	return !ok || supported
+}
+
+// SupportsJSON reports whether zfs and zpool accept -j
+func (e *CommandExecutor) SupportsJSON(ctx context.Context) bool {
+	return e.HasFeature(ctx, FeatureJSON)
+}
+
+// SetVersion overrides version detection, e.g. for tests or when the
+// version is known from configuration
+func (e *CommandExecutor) SetVersion(version string) {
+	e.setVersion(version)
+}
+
+// detect runs zfs version and records the userland version with the
+// features it supports. A failed detection isn't latched: it's retried on
+// the next call until it succeeds.
+func (e *CommandExecutor) detect(ctx context.Context) {
+	if e.versionDetected() {
+		return
+	}
+
+	e.detectMu.Lock()
+	defer e.detectMu.Unlock()
+	if e.versionDetected() {
+		return
+	}
+	if err := e.probeVersion(ctx); err != nil {
+		e.logger.Warn("Failed to detect ZFS version; assuming current release until detected", "err", err)
+	}
+}
+
+func (e *CommandExecutor) versionDetected() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.detected
+}
+
+func (e *CommandExecutor) probeVersion(ctx context.Context) error {
+	out, err := e.Execute(ctx, CommandOptions{}, "zfs version")
+	if err != nil {
+		return err
+	}
+	userland, kmod := parseVersion(string(out))
+	if userland == "" {
+		return errors.New(errors.CommandOutputParse, "unrecognized zfs version output").
+			WithMetadata("output", string(out))
+	}
+	e.setVersion(userland)
+
+	e.mu.Lock()
+	e.kmodVersion = kmod
+	e.mu.Unlock()
+
+	e.logger.Debug("Detected ZFS version", "zfs", userland, "kmod", kmod)
+	return nil
+}
+
+func (e *CommandExecutor) setVersion(version string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// zfs and zpool ship together; both report the userland version
+	e.detected = true
+	e.zfsVersion = version
+	e.zpoolVersion = version
+	for feature, release := range releaseFeatures {
+		e.features[feature] = versionAtLeast(version, release[0], release[1])
+	}
+}
+
+// parseVersion extracts the userland and kernel module versions from zfs
+// version output:
+//
+//	zfs-2.2.2-0ubuntu9.1
+//	zfs-kmod-2.2.2-0ubuntu9.1
+func parseVersion(out string) (userland, kmod string) {
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "zfs-kmod-"):
+			kmod = releaseVersion(strings.TrimPrefix(line, "zfs-kmod-"))
+		case strings.HasPrefix(line, "zfs-"):
+			userland = releaseVersion(strings.TrimPrefix(line, "zfs-"))
+		}
+	}
+	return userland, kmod
+}
+
+// releaseVersion strips the package release from a version such as
+// 2.2.2-0ubuntu9.1 or 2.3.0-rc5
+func releaseVersion(v string) string {
+	if i := strings.IndexByte(v, '-'); i >= 0 {
+		v = v[:i]
+	}
+	return v
+}
+
+// versionAtLeast reports whether version v is at least major.minor
+func versionAtLeast(v string, major, minor int) bool {
+	parts := strings.SplitN(v, ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	maj, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	mnr, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false
+	}
+	return maj > major || (maj == major && mnr >= minor)
+}
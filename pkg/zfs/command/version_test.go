@@ -0,0 +1,106 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stratastor/logger"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		out      string
+		userland string
+		kmod     string
+		json     bool
+	}{
+		{
+			out:      "zfs-2.2.2-0ubuntu9.1\nzfs-kmod-2.2.2-0ubuntu9.1\n",
+			userland: "2.2.2",
+			kmod:     "2.2.2",
+		},
+		{
+			out:      "zfs-2.1.5-1ubuntu6~22.04.4\nzfs-kmod-2.1.5-1ubuntu6~22.04.1\n",
+			userland: "2.1.5",
+			kmod:     "2.1.5",
+		},
+		{
+			out:      "zfs-2.3.0-1\nzfs-kmod-2.3.0-1\n",
+			userland: "2.3.0",
+			kmod:     "2.3.0",
+			json:     true,
+		},
+		{
+			out:      "zfs-2.3.99-47_g1d51c4d0b\nzfs-kmod-2.2.6-1\n",
+			userland: "2.3.99",
+			kmod:     "2.2.6",
+			json:     true,
+		},
+		{
+			out:      "zfs-3.0.0-1\n",
+			userland: "3.0.0",
+			json:     true,
+		},
+		{out: "unexpected\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.userland, func(t *testing.T) {
+			userland, kmod := parseVersion(tt.out)
+			if userland != tt.userland || kmod != tt.kmod {
+				t.Errorf("parseVersion() = %q, %q, want %q, %q", userland, kmod, tt.userland, tt.kmod)
+			}
+			if got := versionAtLeast(userland, 2, 3); got != tt.json {
+				t.Errorf("versionAtLeast(%q, 2, 3) = %v, want %v", userland, got, tt.json)
+			}
+		})
+	}
+}
+
+func TestDetectRetriesAfterFailure(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "called")
+	// zfs version fails the first time it runs, e.g. on a sudo hiccup
+	script := "#!/bin/sh\n" +
+		"if [ -f " + marker + " ]; then\n" +
+		"  echo zfs-2.2.2-1\n  echo zfs-kmod-2.2.2-1\n" +
+		"else\n  : > " + marker + "\n  exit 1\nfi\n"
+	bin := filepath.Join(dir, "zfs")
+	if err := os.WriteFile(bin, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer func(orig string) { BinZFS = orig }(BinZFS)
+	BinZFS = bin
+
+	ctx := context.Background()
+	e := NewCommandExecutor(false, logger.Config{LogLevel: "error"})
+
+	if !e.HasFeature(ctx, FeatureJSON) {
+		t.Fatal("features should be assumed while the version is unknown")
+	}
+	if e.HasFeature(ctx, FeatureJSON) {
+		t.Error("detection should have been retried and found 2.2.2")
+	}
+	if v := e.Version(ctx); v != "2.2.2" {
+		t.Errorf("Version() = %q, want 2.2.2", v)
+	}
+}
@@ -152,6 +152,7 @@ var readonlyPoolProps = map[string]struct{}{
 	"bootfs":        {},
 	"delegation":    {},
 	"autoreplace":   {},
+	"autotrim":      {},
 	"cachefile":     {},
 	"failmode":      {},
 	"listsnapshots": {},
@@ -172,6 +173,7 @@ var anytimePoolProps = map[string]struct{}{
 	"listsnapshots": {},
 	"autoexpand":    {},
 	"autoreplace":   {},
+	"autotrim":      {},
 	"delegation":    {},
 	"failmode":      {},
 	"cachefile":     {},
@@ -81,6 +81,9 @@ type SendConfig struct {
 	// Resume options
 	ResumeToken string `json:"resume_token"` // Token for resuming send
 	Progress    bool   `json:"progress"`     // -P: Print parsable progress statistics
+	// --saved: Send the partially received state of a dataset; Snapshot
+	// names the filesystem or volume
+	Saved bool `json:"saved"`
 
 	// Transfer control
 	// TODO: Implement timeout
@@ -218,6 +221,9 @@ func (m *Manager) SendReceive(
 	if err := validateReceiveConfig(recvCfg); err != nil {
 		return err
 	}
+	if err := m.checkSendFeatures(ctx, sendCfg); err != nil {
+		return err
+	}
 	if recvCfg.RemoteConfig.Host != "" {
 		if err := validateSSHConfig(recvCfg.RemoteConfig); err != nil {
 			return err
@@ -239,6 +245,9 @@ func (m *Manager) SendReceive(
 	if sendCfg.ResumeToken != "" {
 		sendPart = append(sendPart, "-t", sendCfg.ResumeToken)
 	}
+	if sendCfg.Saved {
+		sendPart = append(sendPart, "--saved")
+	}
 	if sendCfg.Progress {
 		sendPart = append(sendPart, "-P")
 	}
@@ -408,6 +417,17 @@ func validateSendConfig(cfg SendConfig) error {
 		return nil
 	}
 
+	if cfg.Saved {
+		if !datasetNameRegex.MatchString(cfg.Snapshot) {
+			return errors.New(errors.CommandInvalidInput, "Invalid dataset name")
+		}
+		if cfg.FromSnapshot != "" || cfg.Replicate {
+			return errors.New(errors.CommandInvalidInput,
+				"Saved sends can't be incremental or replicated")
+		}
+		return nil
+	}
+
 	// Validate snapshot name
 	if !snapshotNameRegex.MatchString(cfg.Snapshot) {
 		return errors.New(errors.CommandInvalidInput, "Invalid snapshot name")
@@ -421,6 +441,21 @@ func validateSendConfig(cfg SendConfig) error {
 	return nil
 }
 
+// checkSendFeatures rejects send options the installed release lacks
+func (m *Manager) checkSendFeatures(ctx context.Context, cfg SendConfig) error {
+	if cfg.Raw {
+		if err := m.executor.Require(ctx, command.FeatureRawSend); err != nil {
+			return err
+		}
+	}
+	if cfg.Saved {
+		if err := m.executor.Require(ctx, command.FeatureSavedSend); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func validateReceiveConfig(cfg ReceiveConfig) error {
 	// Validate target dataset
 	if !datasetNameRegex.MatchString(cfg.Target) {
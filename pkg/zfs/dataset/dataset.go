@@ -42,6 +42,68 @@ func NewManager(executor *command.CommandExecutor) *Manager {
 
 // List returns a list of datasets
 func (m *Manager) List(ctx context.Context, cfg ListConfig) (ListResult, error) {
+	args, err := listArgs(cfg)
+	if err != nil {
+		return ListResult{}, err
+	}
+
+	opts := command.CommandOptions{
+		Flags: command.FlagJSON,
+	}
+
+	result := ListResult{}
+
+	out, err := m.executor.Execute(ctx, opts, "zfs list", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return result, errors.Wrap(err, errors.ZFSDatasetList).
+				WithMetadata("output", string(out))
+		}
+		return result, errors.Wrap(err, errors.ZFSDatasetList)
+	}
+
+	if err := json.Unmarshal(out, &result); err != nil {
+		return ListResult{}, errors.Wrap(err, errors.CommandOutputParse)
+	}
+
+	return result, nil
+}
+
+// ListStream calls fn for each dataset as zfs list output is decoded,
+// without holding the whole listing in memory. Datasets arrive in zfs list
+// order. An error from fn stops the listing and is returned.
+func (m *Manager) ListStream(ctx context.Context, cfg ListConfig, fn func(Dataset) error) error {
+	args, err := listArgs(cfg)
+	if err != nil {
+		return err
+	}
+
+	opts := command.CommandOptions{
+		Flags: command.FlagJSON,
+	}
+
+	stream, err := m.executor.ExecuteStream(ctx, opts, "zfs list", args...)
+	if err != nil {
+		return errors.Wrap(err, errors.ZFSDatasetList)
+	}
+
+	var fnErr error
+	err = stream.JSONObjects("datasets", func(name string, value json.RawMessage) error {
+		var ds Dataset
+		if err := json.Unmarshal(value, &ds); err != nil {
+			return errors.Wrap(err, errors.CommandOutputParse)
+		}
+		fnErr = fn(ds)
+		return fnErr
+	})
+	if err != nil && err != fnErr {
+		return errors.Wrap(err, errors.ZFSDatasetList)
+	}
+	return err
+}
+
+// listArgs builds zfs list arguments
+func listArgs(cfg ListConfig) ([]string, error) {
 	// A comma-separated list of types to display, where type  is  one  of:
 	// filesystem, snapshot, volume, bookmark, or all.
 	args := []string{"list", "-t"}
@@ -61,7 +123,7 @@ func (m *Manager) List(ctx context.Context, cfg ListConfig) (ListResult, error)
 		case "all", "":
 			listTypes = append(listTypes, "all")
 		default:
-			return ListResult{}, errors.New(
+			return nil, errors.New(
 				errors.ZFSNameInvalid,
 				"List type must be one of: filesystem, snapshot, volume, bookmark, all",
 			)
@@ -86,30 +148,11 @@ func (m *Manager) List(ctx context.Context, cfg ListConfig) (ListResult, error)
 		args = append(args, "-p")
 	}
 
-	opts := command.CommandOptions{
-		Flags: command.FlagJSON,
-	}
-
 	if cfg.Name != "" {
 		args = append(args, cfg.Name)
 	}
 
-	result := ListResult{}
-
-	out, err := m.executor.Execute(ctx, opts, "zfs list", args...)
-	if err != nil {
-		if len(out) > 0 {
-			return result, errors.Wrap(err, errors.ZFSDatasetList).
-				WithMetadata("output", string(out))
-		}
-		return result, errors.Wrap(err, errors.ZFSDatasetList)
-	}
-
-	if err := json.Unmarshal(out, &result); err != nil {
-		return ListResult{}, errors.Wrap(err, errors.CommandOutputParse)
-	}
-
-	return result, nil
+	return args, nil
 }
 
 // Destroy removes a dataset
@@ -600,13 +643,7 @@ func (m *Manager) Diff(ctx context.Context, cfg DiffConfig) (DiffResult, error)
 			"Exactly two names required for diff operation")
 	}
 
-	args := []string{"diff"}
-
-	// Always use these flags for consistent parsable output
-	args = append(args, "-H", "-t", "-F")
-
-	// Add snapshot/filesystem arguments
-	args = append(args, cfg.Names...)
+	args := diffArgs(cfg)
 
 	opts := command.CommandOptions{}
 
@@ -626,38 +663,80 @@ func (m *Manager) Diff(ctx context.Context, cfg DiffConfig) (DiffResult, error)
 
 	lines := strings.Split(string(out), "\n")
 	for _, line := range lines {
-		if line == "" {
-			continue
+		if entry, ok := parseDiffLine(line); ok {
+			result.Changes = append(result.Changes, entry)
 		}
+	}
 
-		// Split on tabs
-		fields := strings.Split(line, "\t")
-		if len(fields) < 4 {
-			continue
-		}
+	return result, nil
+}
 
-		// Parse timestamp
-		timestamp, err := strconv.ParseFloat(fields[0], 64)
-		if err != nil {
-			continue
-		}
+// DiffStream calls fn for each change as zfs diff reports it, without
+// holding the whole diff in memory. An error from fn stops the diff and is
+// returned.
+func (m *Manager) DiffStream(ctx context.Context, cfg DiffConfig, fn func(DiffEntry) error) error {
+	if len(cfg.Names) != 2 {
+		return errors.New(errors.CommandInvalidInput,
+			"Exactly two names required for diff operation")
+	}
 
-		entry := DiffEntry{
-			Timestamp:  timestamp,
-			ChangeType: fields[1],
-			FileType:   fields[2],
-			Path:       fields[3],
-		}
+	stream, err := m.executor.ExecuteStream(ctx, command.CommandOptions{}, "zfs diff", diffArgs(cfg)...)
+	if err != nil {
+		return errors.Wrap(err, errors.ZFSDatasetOperation)
+	}
 
-		// Handle rename entries which have an additional field
-		if entry.ChangeType == "R" && len(fields) > 4 {
-			entry.NewPath = fields[4]
+	var fnErr error
+	err = stream.Lines(func(line string) error {
+		if entry, ok := parseDiffLine(line); ok {
+			fnErr = fn(entry)
 		}
+		return fnErr
+	})
+	if err != nil && err != fnErr {
+		return errors.Wrap(err, errors.ZFSDatasetOperation)
+	}
+	return err
+}
+
+func diffArgs(cfg DiffConfig) []string {
+	args := []string{"diff"}
+
+	// Always use these flags for consistent parsable output
+	args = append(args, "-H", "-t", "-F")
+
+	// Add snapshot/filesystem arguments
+	return append(args, cfg.Names...)
+}
 
-		result.Changes = append(result.Changes, entry)
+// parseDiffLine parses a line of zfs diff -H -t -F output:
+//
+//	1700000000.123456789	M	/	/tank/fs
+//	1700000000.123456789	R	F	/tank/fs/a	/tank/fs/b
+func parseDiffLine(line string) (DiffEntry, bool) {
+	// Split on tabs
+	fields := strings.Split(line, "\t")
+	if len(fields) < 4 {
+		return DiffEntry{}, false
 	}
 
-	return result, nil
+	// Parse timestamp
+	timestamp, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return DiffEntry{}, false
+	}
+
+	entry := DiffEntry{
+		Timestamp:  timestamp,
+		ChangeType: fields[1],
+		FileType:   fields[2],
+		Path:       fields[3],
+	}
+
+	// Handle rename entries which have an additional field
+	if entry.ChangeType == "R" && len(fields) > 4 {
+		entry.NewPath = fields[4]
+	}
+	return entry, true
 }
 
 // Allow grants permissions on a dataset
@@ -910,6 +989,7 @@ func parseAllowOutput(output string) (AllowResult, error) {
 // If sharenfs and sharesmb properties are set to off, it falls to being legacy which is then useless for `zfs share`
 // When sharenfs and sharesmb are set to a value, it's exposed by default and requires handling.
 // Besides, sharesmb seems to be very rudimentary and needs manual work on smb.conf anyway.
+// Managed shares live in pkg/share: nfs renders sharenfs and smb generates an smb.conf include.
 // Share shares a ZFS dataset
 func (m *Manager) Share(ctx context.Context, cfg ShareConfig) error {
 	args := []string{"share"}
@@ -392,6 +392,41 @@ func TestDatasetOperations(t *testing.T) {
 			}
 		})
 
+		t.Run("ResizeVolume", func(t *testing.T) {
+			volName := poolName + "/vol1"
+			result, err := datasetMgr.ResizeVolume(context.Background(), VolumeResizeConfig{
+				NameConfig: NameConfig{Name: volName},
+				Size:       "40M",
+			})
+			if err != nil {
+				t.Fatalf("failed to resize volume: %v", err)
+			}
+			if result.NewSize != 40<<20 {
+				t.Errorf("new size = %d, want %d", result.NewSize, 40<<20)
+			}
+			if result.Sparse || result.RefReservation < result.NewSize {
+				t.Errorf("refreservation = %d, want at least %d", result.RefReservation, result.NewSize)
+			}
+		})
+
+		t.Run("CloneVolume", func(t *testing.T) {
+			result, err := datasetMgr.CloneVolume(context.Background(), VolumeCloneConfig{
+				NameConfig: NameConfig{Name: poolName + "/vol1"},
+				CloneName:  poolName + "/vol1-copy",
+				SnapName:   "copy",
+			})
+			if err != nil {
+				t.Fatalf("failed to clone volume: %v", err)
+			}
+			if result.Snapshot != poolName+"/vol1-copy@copy" {
+				t.Errorf("snapshot = %s, want %s", result.Snapshot, poolName+"/vol1-copy@copy")
+			}
+
+			exists, err := datasetMgr.Exists(context.Background(), result.Snapshot)
+			if err != nil || !exists {
+				t.Errorf("promoted clone doesn't own the snapshot: %v", err)
+			}
+		})
 	})
 
 	t.Run("DiffOperations", func(t *testing.T) {
@@ -125,6 +125,49 @@ type VolumeConfig struct {
 	Verbose   bool   `json:"verbose"`
 }
 
+// VolumeResizeConfig changes the volsize of a volume
+type VolumeResizeConfig struct {
+	NameConfig
+	Size string `json:"size" binding:"required"`
+	// Force permits shrinking below the data referenced by the volume,
+	// discarding the blocks past the new end
+	Force bool `json:"force"`
+}
+
+// VolumeResizeResult reports a volume after a resize
+type VolumeResizeResult struct {
+	Name    string `json:"name"`
+	OldSize uint64 `json:"old_size"`
+	NewSize uint64 `json:"new_size"`
+	// Sparse volumes have no refreservation to adjust
+	Sparse         bool   `json:"sparse"`
+	RefReservation uint64 `json:"refreservation"`
+	Device         string `json:"device"`
+	// DeviceSize is the size of the block device as seen by the kernel,
+	// zero when the device node can't be read
+	DeviceSize uint64 `json:"device_size,omitempty"`
+}
+
+// VolumeCloneConfig clones a volume into a new, independent volume
+type VolumeCloneConfig struct {
+	NameConfig
+	CloneName string `json:"clone_name" binding:"required"`
+	// SnapName names the snapshot the clone is made from; generated from
+	// the current time when empty
+	SnapName   string            `json:"snap_name,omitempty"`
+	Properties map[string]string `json:"properties,omitempty"`
+	Parents    bool              `json:"parents,omitempty"`
+}
+
+// VolumeCloneResult reports a volume clone
+type VolumeCloneResult struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	// Snapshot is the snapshot shared by both volumes. After promotion it
+	// belongs to the clone and the source depends on it.
+	Snapshot string `json:"snapshot"`
+}
+
 type SnapshotConfig struct {
 	NameConfig
 	SnapName   string            `json:"snap_name"            binding:"required"`
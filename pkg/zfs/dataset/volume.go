@@ -0,0 +1,269 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dataset
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/zfs/command"
+)
+
+var (
+	// sizeRegex matches sizes as zfs accepts them: bytes, or a possibly
+	// fractional number with a binary unit suffix
+	sizeRegex = regexp.MustCompile(`^(?i)(\d+(?:\.\d+)?)([KMGTPE])?(?:i?B)?$`)
+
+	// zvolDir and sysBlockDir locate volume device nodes and their sizes
+	zvolDir     = "/dev/zvol"
+	sysBlockDir = "/sys/class/block"
+)
+
+// ParseSize converts a size such as 10G, 1.5T or 4096 to bytes. Units are
+// powers of 1024, as with zfs.
+func ParseSize(s string) (uint64, error) {
+	m := sizeRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, errors.New(errors.ZFSInvalidSize, "invalid size").
+			WithMetadata("size", s)
+	}
+
+	shift := 0
+	if m[2] != "" {
+		shift = 10 * (strings.Index("KMGTPE", strings.ToUpper(m[2])) + 1)
+	} else if strings.Contains(m[1], ".") {
+		return 0, errors.New(errors.ZFSInvalidSize, "fractional sizes need a unit").
+			WithMetadata("size", s)
+	}
+
+	value, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ZFSInvalidSize).WithMetadata("size", s)
+	}
+	bytes := value * math.Pow(2, float64(shift))
+	if bytes >= math.MaxUint64 {
+		return 0, errors.New(errors.ZFSInvalidSize, "size is too large").
+			WithMetadata("size", s)
+	}
+	return uint64(bytes), nil
+}
+
+// volumeState holds the properties a resize depends on
+type volumeState struct {
+	volsize           uint64
+	volblocksize      uint64
+	refreservation    uint64
+	logicalreferenced uint64
+}
+
+// ResizeVolume changes the volsize of a volume. The size is rounded up to
+// a multiple of volblocksize. Shrinking below the data the volume
+// references is refused unless forced. The refreservation of a
+// non-sparse volume follows the new size; sparse volumes and volumes with
+// a hand-set smaller reservation keep theirs.
+func (m *Manager) ResizeVolume(ctx context.Context, cfg VolumeResizeConfig) (*VolumeResizeResult, error) {
+	size, err := ParseSize(cfg.Size)
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 {
+		return nil, errors.New(errors.ZFSInvalidSize, "volume size must be greater than zero")
+	}
+
+	state, err := m.volumeState(ctx, cfg.Name)
+	if err != nil {
+		return nil, err
+	}
+	if state.volblocksize > 0 {
+		if rem := size % state.volblocksize; rem != 0 {
+			size += state.volblocksize - rem
+		}
+	}
+
+	if size < state.logicalreferenced && !cfg.Force {
+		return nil, errors.New(errors.ZFSVolumeShrink,
+			fmt.Sprintf("volume references %d bytes; set force to shrink to %d", state.logicalreferenced, size)).
+			WithMetadata("name", cfg.Name)
+	}
+
+	// A thick volume's reservation covers volsize plus metadata, so it is
+	// never below volsize
+	thick := state.refreservation >= state.volsize
+	args := []string{"set", fmt.Sprintf("volsize=%d", size)}
+	if thick {
+		args = append(args, "refreservation=auto")
+	}
+	args = append(args, cfg.Name)
+
+	if size != state.volsize {
+		out, err := m.executor.Execute(ctx, command.CommandOptions{}, "zfs set", args...)
+		if err != nil {
+			if len(out) > 0 {
+				return nil, errors.Wrap(err, errors.ZFSVolumeResize).
+					WithMetadata("output", string(out))
+			}
+			return nil, errors.Wrap(err, errors.ZFSVolumeResize)
+		}
+	}
+
+	after, err := m.volumeState(ctx, cfg.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	device := filepath.Join(zvolDir, cfg.Name)
+	return &VolumeResizeResult{
+		Name:           cfg.Name,
+		OldSize:        state.volsize,
+		NewSize:        after.volsize,
+		Sparse:         state.refreservation == 0,
+		RefReservation: after.refreservation,
+		Device:         device,
+		DeviceSize:     deviceSize(device),
+	}, nil
+}
+
+func (m *Manager) volumeState(ctx context.Context, name string) (*volumeState, error) {
+	args := []string{
+		"get", "-H", "-p", "-o", "property,value",
+		"type,volsize,volblocksize,refreservation,logicalreferenced", name,
+	}
+
+	out, err := m.executor.Execute(ctx, command.CommandOptions{}, "zfs get", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return nil, errors.Wrap(err, errors.ZFSDatasetGetProperty).
+				WithMetadata("output", string(out))
+		}
+		return nil, errors.Wrap(err, errors.ZFSDatasetGetProperty)
+	}
+
+	props := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if fields := strings.SplitN(line, "\t", 2); len(fields) == 2 {
+			props[fields[0]] = fields[1]
+		}
+	}
+	if props["type"] != "volume" {
+		return nil, errors.New(errors.ZFSDatasetInvalidName, "dataset is not a volume").
+			WithMetadata("name", name)
+	}
+
+	state := &volumeState{}
+	for prop, dst := range map[string]*uint64{
+		"volsize":           &state.volsize,
+		"volblocksize":      &state.volblocksize,
+		"refreservation":    &state.refreservation,
+		"logicalreferenced": &state.logicalreferenced,
+	} {
+		value := props[prop]
+		if value == "" || value == "-" || value == "none" {
+			continue
+		}
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CommandOutputParse).
+				WithMetadata("property", prop)
+		}
+		*dst = n
+	}
+	return state, nil
+}
+
+// deviceSize returns the size of the block device behind a zvol link, 0
+// when it can't be read
+func deviceSize(device string) uint64 {
+	target, err := filepath.EvalSymlinks(device)
+	if err != nil {
+		return 0
+	}
+	data, err := os.ReadFile(filepath.Join(sysBlockDir, filepath.Base(target), "size"))
+	if err != nil {
+		return 0
+	}
+	sectors, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	// The kernel reports sizes in 512 byte sectors regardless of the
+	// logical block size
+	return sectors * 512
+}
+
+// CloneVolume snapshots a volume, clones the snapshot into a new volume
+// and promotes the clone, so the new volume owns the snapshot and the
+// source becomes its dependent. A failed step undoes the earlier ones.
+func (m *Manager) CloneVolume(ctx context.Context, cfg VolumeCloneConfig) (*VolumeCloneResult, error) {
+	if _, err := m.volumeState(ctx, cfg.Name); err != nil {
+		return nil, err
+	}
+
+	snapName := cfg.SnapName
+	if snapName == "" {
+		snapName = "clone-" + time.Now().UTC().Format("20060102-150405")
+	}
+	snapshot := cfg.Name + "@" + snapName
+
+	if err := m.CreateSnapshot(ctx, SnapshotConfig{
+		NameConfig: NameConfig{Name: cfg.Name},
+		SnapName:   snapName,
+	}); err != nil {
+		return nil, errors.Wrap(err, errors.ZFSVolumeClone).
+			WithMetadata("step", "snapshot")
+	}
+
+	if err := m.Clone(ctx, CloneConfig{
+		NameConfig: NameConfig{Name: snapshot},
+		CloneName:  cfg.CloneName,
+		Properties: cfg.Properties,
+		Parents:    cfg.Parents,
+	}); err != nil {
+		m.undoClone(ctx, snapshot, "")
+		return nil, errors.Wrap(err, errors.ZFSVolumeClone).
+			WithMetadata("step", "clone")
+	}
+
+	if err := m.PromoteClone(ctx, NameConfig{Name: cfg.CloneName}); err != nil {
+		m.undoClone(ctx, snapshot, cfg.CloneName)
+		return nil, errors.Wrap(err, errors.ZFSVolumeClone).
+			WithMetadata("step", "promote")
+	}
+
+	return &VolumeCloneResult{
+		Name:     cfg.CloneName,
+		Source:   cfg.Name,
+		Snapshot: cfg.CloneName + "@" + snapName,
+	}, nil
+}
+
+// undoClone destroys the clone, when given, and the snapshot of a failed
+// CloneVolume. Errors are ignored; the original failure is reported.
+func (m *Manager) undoClone(ctx context.Context, snapshot, clone string) {
+	if clone != "" {
+		_ = m.Destroy(ctx, DestroyConfig{NameConfig: NameConfig{Name: clone}})
+	}
+	_ = m.Destroy(ctx, DestroyConfig{NameConfig: NameConfig{Name: snapshot}})
+}
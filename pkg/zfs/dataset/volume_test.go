@@ -0,0 +1,90 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dataset
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    uint64
+		wantErr bool
+	}{
+		{in: "4096", want: 4096},
+		{in: "10K", want: 10 << 10},
+		{in: "30M", want: 30 << 20},
+		{in: "1.5G", want: 3 << 29},
+		{in: "2t", want: 2 << 40},
+		{in: "1GiB", want: 1 << 30},
+		{in: "1GB", want: 1 << 30},
+		{in: "1E", want: 1 << 60},
+		{in: "1.5", wantErr: true},
+		{in: "10X", wantErr: true},
+		{in: "-1G", wantErr: true},
+		{in: "16E", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseSize(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSize(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSize(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeviceSize(t *testing.T) {
+	root := t.TempDir()
+	sys := filepath.Join(root, "sys")
+	dev := filepath.Join(root, "dev")
+
+	if err := os.MkdirAll(filepath.Join(sys, "zd16"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sys, "zd16", "size"), []byte("81920\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dev, "zvol", "tank"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dev, "zd16"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("../../zd16", filepath.Join(dev, "zvol", "tank", "vol1")); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := sysBlockDir
+	sysBlockDir = sys
+	defer func() { sysBlockDir = saved }()
+
+	if got := deviceSize(filepath.Join(dev, "zvol", "tank", "vol1")); got != 40<<20 {
+		t.Errorf("deviceSize() = %d, want %d", got, 40<<20)
+	}
+	if got := deviceSize(filepath.Join(dev, "zvol", "tank", "missing")); got != 0 {
+		t.Errorf("deviceSize() of a missing device = %d, want 0", got)
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/zfs/command"
+)
+
+// Checkpoint takes a checkpoint of the pool. A pool holds at most one
+// checkpoint at a time.
+func (p *Manager) Checkpoint(ctx context.Context, name string) error {
+	args := []string{"checkpoint", name}
+
+	out, err := p.executor.Execute(ctx, command.CommandOptions{}, "zpool checkpoint", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return errors.Wrap(err, errors.ZFSPoolCheckpoint).
+				WithMetadata("output", string(out))
+		}
+		return errors.Wrap(err, errors.ZFSPoolCheckpoint)
+	}
+	return nil
+}
+
+// DiscardCheckpoint discards the checkpoint of the pool. If wait is set, the
+// call blocks until the space held by the checkpoint has been reclaimed. An
+// unfinished maintenance session of the pool is abandoned with it.
+func (p *Manager) DiscardCheckpoint(ctx context.Context, name string, wait bool) error {
+	session, prev, err := p.sessions.begin(func() (*MaintenanceSession, error) {
+		return p.sessions.active(name), nil
+	}, "abandoned", MaintenanceAbandoning, MaintenanceCheckpointed, MaintenanceApplied)
+	if err != nil {
+		return err
+	}
+	if session != nil {
+		_, err := p.abandon(ctx, session, prev, wait)
+		return err
+	}
+	return p.discardCheckpoint(ctx, name, wait)
+}
+
+func (p *Manager) discardCheckpoint(ctx context.Context, name string, wait bool) error {
+	args := []string{"checkpoint", "-d"}
+	if wait {
+		args = append(args, "-w")
+	}
+	args = append(args, name)
+
+	out, err := p.executor.Execute(ctx, command.CommandOptions{}, "zpool checkpoint", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return errors.Wrap(err, errors.ZFSPoolCheckpoint).
+				WithMetadata("output", string(out))
+		}
+		return errors.Wrap(err, errors.ZFSPoolCheckpoint)
+	}
+	return nil
+}
+
+// CheckpointInfo reports whether the pool has a checkpoint and how much
+// space it holds
+func (p *Manager) CheckpointInfo(ctx context.Context, name string) (*CheckpointInfo, error) {
+	props, err := p.GetProperty(ctx, name, "checkpoint")
+	if err != nil {
+		return nil, err
+	}
+
+	info := &CheckpointInfo{Pool: name}
+	if pool, ok := props.Pools[name]; ok {
+		if prop, ok := pool.Properties["checkpoint"]; ok && prop.Value != nil {
+			if v := fmt.Sprint(prop.Value); v != "-" && v != "" {
+				info.Exists = true
+				info.Space = v
+			}
+		}
+	}
+
+	if !info.Exists {
+		return info, nil
+	}
+
+	status, err := p.Status(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if pool, ok := status.Pools[name]; ok && pool.CheckpointStats != nil {
+		info.State = pool.CheckpointStats.State
+		info.CreatedAt = pool.CheckpointStats.StartTime
+	}
+
+	return info, nil
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"context"
+
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/zfs/command"
+)
+
+// OnlineDevice brings devices back online. With expand set, the devices are
+// expanded to use all available space.
+func (p *Manager) OnlineDevice(ctx context.Context, pool string, devices []string, expand bool) error {
+	if len(devices) == 0 {
+		return errors.New(errors.ZFSPoolInvalidDevice, "no devices specified")
+	}
+
+	args := []string{"online"}
+	if expand {
+		args = append(args, "-e")
+	}
+	args = append(args, pool)
+	args = append(args, devices...)
+
+	out, err := p.executor.Execute(ctx, command.CommandOptions{}, "zpool online", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return errors.Wrap(err, errors.ZFSPoolDeviceOperation).
+				WithMetadata("output", string(out))
+		}
+		return errors.Wrap(err, errors.ZFSPoolDeviceOperation)
+	}
+	return nil
+}
+
+// OfflineDevice takes a device offline. A temporary offline doesn't persist
+// across reboots; force marks the device faulted instead.
+func (p *Manager) OfflineDevice(ctx context.Context, pool, device string, temporary, force bool) error {
+	args := []string{"offline"}
+	if force {
+		args = append(args, "-f")
+	}
+	if temporary {
+		args = append(args, "-t")
+	}
+	args = append(args, pool, device)
+
+	out, err := p.executor.Execute(ctx, command.CommandOptions{}, "zpool offline", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return errors.Wrap(err, errors.ZFSPoolDeviceOperation).
+				WithMetadata("output", string(out))
+		}
+		return errors.Wrap(err, errors.ZFSPoolDeviceOperation)
+	}
+	return nil
+}
+
+// ClearErrors clears device errors in the pool, or only those of device when
+// it is not empty
+func (p *Manager) ClearErrors(ctx context.Context, pool, device string) error {
+	args := []string{"clear", pool}
+	if device != "" {
+		args = append(args, device)
+	}
+
+	out, err := p.executor.Execute(ctx, command.CommandOptions{}, "zpool clear", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return errors.Wrap(err, errors.ZFSPoolDeviceOperation).
+				WithMetadata("output", string(out))
+		}
+		return errors.Wrap(err, errors.ZFSPoolDeviceOperation)
+	}
+	return nil
+}
+
+// Reopen reopens all vdevs of the pool. With noRestart set, an in-progress
+// scrub is not restarted.
+func (p *Manager) Reopen(ctx context.Context, pool string, noRestart bool) error {
+	args := []string{"reopen"}
+	if noRestart {
+		args = append(args, "-n")
+	}
+	args = append(args, pool)
+
+	out, err := p.executor.Execute(ctx, command.CommandOptions{}, "zpool reopen", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return errors.Wrap(err, errors.ZFSPoolDeviceOperation).
+				WithMetadata("output", string(out))
+		}
+		return errors.Wrap(err, errors.ZFSPoolDeviceOperation)
+	}
+	return nil
+}
+
+// MemberDevices returns the device paths of the leaf vdevs of all imported
+// pools, mapped to the pool using them
+func (p *Manager) MemberDevices(ctx context.Context) (map[string]string, error) {
+	status, err := p.Status(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	members := make(map[string]string)
+	var walk func(pool string, vdevs map[string]*VDev)
+	walk = func(pool string, vdevs map[string]*VDev) {
+		for _, v := range vdevs {
+			if v.Path != "" {
+				members[v.Path] = pool
+			}
+			walk(pool, v.VDevs)
+		}
+	}
+
+	for name, pool := range status.Pools {
+		for _, vdevs := range []map[string]*VDev{
+			pool.VDevs, pool.Logs, pool.L2Cache, pool.Spares, pool.Special, pool.Dedup,
+		} {
+			walk(name, vdevs)
+		}
+	}
+	return members, nil
+}
@@ -0,0 +1,169 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"bufio"
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/zfs/command"
+)
+
+// historyTimeLayout is the timestamp format of zpool history (%F.%T)
+const historyTimeLayout = "2006-01-02.15:04:05"
+
+var (
+	historyHeaderRegex = regexp.MustCompile(`^History for '([^']+)':$`)
+	historyLineRegex   = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}\.\d{2}:\d{2}:\d{2}) (.*)$`)
+	// Long format suffix: [user 0 (root) on host:zone]
+	historyLongRegex = regexp.MustCompile(
+		`\s*\[(?:user (\d+) (?:\(([^)]*)\) )?)?(?:on ([^\]:]*)(?::([^\]]*))?)?\]$`,
+	)
+	// Internal event: [txg:42] set tank/data (68) compression=lz4
+	historyInternalRegex = regexp.MustCompile(`^\[txg:(\d+)\] (\S+) (\S+) \(\d+\)\s*(.*)$`)
+)
+
+// History returns the command history of a pool, or of all pools if name is
+// empty, including changes made outside of Rodent
+func (p *Manager) History(ctx context.Context, name string, cfg HistoryConfig) ([]HistoryEntry, error) {
+	args := []string{"history"}
+	if cfg.Internal {
+		args = append(args, "-i")
+	}
+	if cfg.Long {
+		args = append(args, "-l")
+	}
+	if name != "" {
+		args = append(args, name)
+	}
+
+	out, err := p.executor.Execute(ctx, command.CommandOptions{}, "zpool history", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return nil, errors.Wrap(err, errors.ZFSPoolHistory).
+				WithMetadata("output", string(out))
+		}
+		return nil, errors.Wrap(err, errors.ZFSPoolHistory)
+	}
+
+	entries := parseHistory(string(out), cfg.Long, time.Local)
+	return filterHistory(entries, cfg), nil
+}
+
+// parseHistory parses zpool history text output. Lines that don't start with
+// a timestamp, such as the nvlists printed for ioctls in long mode, are
+// skipped.
+func parseHistory(out string, long bool, loc *time.Location) []HistoryEntry {
+	entries := []HistoryEntry{}
+
+	var pool string
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " ")
+
+		if m := historyHeaderRegex.FindStringSubmatch(line); m != nil {
+			pool = m[1]
+			continue
+		}
+
+		m := historyLineRegex.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		ts, err := time.ParseInLocation(historyTimeLayout, m[1], loc)
+		if err != nil {
+			continue
+		}
+
+		entry := HistoryEntry{Pool: pool, Time: ts}
+		rest := m[2]
+
+		if lm := historyLongRegex.FindStringSubmatchIndex(rest); long && lm != nil {
+			sub := func(i int) string {
+				if lm[2*i] < 0 {
+					return ""
+				}
+				return rest[lm[2*i]:lm[2*i+1]]
+			}
+			entry.UID = sub(1)
+			entry.User = sub(2)
+			entry.Host = sub(3)
+			entry.Zone = sub(4)
+			rest = rest[:lm[0]]
+		}
+
+		if im := historyInternalRegex.FindStringSubmatch(rest); im != nil {
+			entry.Internal = true
+			entry.TXG, _ = strconv.ParseUint(im[1], 10, 64)
+			entry.Operation = im[2]
+			entry.Dataset = im[3]
+			entry.Command = strings.TrimSpace(im[2] + " " + im[4])
+		} else {
+			entry.Internal = strings.HasPrefix(rest, "ioctl ")
+			entry.Command = rest
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func filterHistory(entries []HistoryEntry, cfg HistoryConfig) []HistoryEntry {
+	filtered := entries[:0]
+	for _, e := range entries {
+		if !cfg.Since.IsZero() && e.Time.Before(cfg.Since) {
+			continue
+		}
+		if !cfg.Until.IsZero() && e.Time.After(cfg.Until) {
+			continue
+		}
+		if cfg.Dataset != "" && !e.touches(cfg.Dataset) {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+	return filtered
+}
+
+// touches reports whether the entry refers to the dataset, one of its
+// children, or one of its snapshots and bookmarks
+func (e HistoryEntry) touches(dataset string) bool {
+	match := func(name string) bool {
+		return name == dataset ||
+			strings.HasPrefix(name, dataset+"/") ||
+			strings.HasPrefix(name, dataset+"@") ||
+			strings.HasPrefix(name, dataset+"#")
+	}
+
+	if e.Dataset != "" {
+		return match(e.Dataset)
+	}
+	for _, arg := range strings.Fields(e.Command) {
+		if match(arg) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+const historyLongInternalOutput = `History for 'tank':
+2025-01-12.00:24:01 zpool create tank mirror /dev/loop0 /dev/loop1 [user 0 (root) on storage01:linux]
+2025-01-12.00:30:00 [txg:12] create tank/data (68)  [on storage01]
+2025-01-12.00:30:00 zfs create tank/data [user 1000 (rodent) on storage01:linux]
+2025-01-12.01:00:00 [txg:40] set tank/data (68) compression=lz4 [on storage01]
+2025-01-12.01:00:00 zfs set compression=lz4 tank/data [user 1000 (rodent) on storage01:linux]
+2025-01-12.02:00:00 zfs snapshot tank/other@daily [user 0 (root) on storage01:linux]
+2025-01-12.03:00:00 ioctl destroy_snaps [user 0 (root) on storage01]
+    input:
+        snaps:
+            tank/other@daily
+
+`
+
+func TestParseHistory(t *testing.T) {
+	entries := parseHistory(historyLongInternalOutput, true, time.UTC)
+	if len(entries) != 7 {
+		t.Fatalf("parseHistory() returned %d entries, want 7", len(entries))
+	}
+
+	first := entries[0]
+	if first.Pool != "tank" || first.UID != "0" || first.User != "root" ||
+		first.Host != "storage01" || first.Zone != "linux" {
+		t.Errorf("unexpected long fields: %+v", first)
+	}
+	if first.Command != "zpool create tank mirror /dev/loop0 /dev/loop1" {
+		t.Errorf("Command = %q", first.Command)
+	}
+	if want := time.Date(2025, 1, 12, 0, 24, 1, 0, time.UTC); !first.Time.Equal(want) {
+		t.Errorf("Time = %v, want %v", first.Time, want)
+	}
+
+	set := entries[3]
+	if !set.Internal || set.TXG != 40 || set.Operation != "set" ||
+		set.Dataset != "tank/data" || set.Command != "set compression=lz4" || set.Host != "storage01" {
+		t.Errorf("unexpected internal entry: %+v", set)
+	}
+
+	if !entries[6].Internal || entries[6].Command != "ioctl destroy_snaps" {
+		t.Errorf("unexpected ioctl entry: %+v", entries[6])
+	}
+}
+
+func TestFilterHistory(t *testing.T) {
+	entries := parseHistory(historyLongInternalOutput, true, time.UTC)
+
+	got := filterHistory(append([]HistoryEntry(nil), entries...), HistoryConfig{Dataset: "tank/data"})
+	if len(got) != 4 {
+		t.Errorf("dataset filter returned %d entries, want 4", len(got))
+	}
+
+	got = filterHistory(append([]HistoryEntry(nil), entries...), HistoryConfig{
+		Since: time.Date(2025, 1, 12, 1, 0, 0, 0, time.UTC),
+		Until: time.Date(2025, 1, 12, 2, 0, 0, 0, time.UTC),
+	})
+	if len(got) != 3 {
+		t.Errorf("time filter returned %d entries, want 3", len(got))
+	}
+
+	got = filterHistory(append([]HistoryEntry(nil), entries...), HistoryConfig{Dataset: "tank/other"})
+	if len(got) != 1 {
+		t.Errorf("snapshot filter returned %d entries, want 1", len(got))
+	}
+}
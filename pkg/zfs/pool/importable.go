@@ -0,0 +1,217 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"bufio"
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/zfs/command"
+)
+
+// noImportablePools is what zpool import reports when nothing is found
+const noImportablePools = "no pools available to import"
+
+// ListImportable lists pools that are visible to `zpool import` but not
+// currently imported. zpool has no JSON output for this, so the human
+// readable report is parsed.
+func (p *Manager) ListImportable(ctx context.Context, cfg ImportableConfig) ([]ImportablePool, error) {
+	for _, path := range cfg.Paths {
+		if !filepath.IsAbs(path) {
+			return nil, errors.New(errors.ZFSPoolImport,
+				"search paths must be absolute").WithMetadata("path", path)
+		}
+	}
+
+	args := []string{"import"}
+	if cfg.Destroyed {
+		args = append(args, "-D")
+	}
+	args = append(args, searchPathArgs("", cfg.Paths)...)
+
+	out, err := p.executor.Execute(ctx, command.CommandOptions{}, "zpool import", args...)
+	if err != nil {
+		if re, ok := err.(*errors.RodentError); ok &&
+			strings.Contains(re.Metadata["stderr"], noImportablePools) {
+			return []ImportablePool{}, nil
+		}
+		if len(out) > 0 {
+			return nil, errors.Wrap(err, errors.ZFSPoolImport).
+				WithMetadata("output", string(out))
+		}
+		return nil, errors.Wrap(err, errors.ZFSPoolImport)
+	}
+
+	return parseImportable(string(out)), nil
+}
+
+// parseImportable parses the report printed by `zpool import` with no pool
+// argument. Each pool starts with a "pool:" line followed by id, state,
+// status, action, see and comment fields and finally a config tree:
+//
+//	   pool: tank
+//	     id: 15351207397411524035
+//	  state: ONLINE
+//	 action: The pool can be imported using its name or numeric identifier.
+//	 config:
+//
+//		tank        ONLINE
+//		  mirror-0  ONLINE
+//		    sdb     ONLINE
+//		    sdc     ONLINE
+func parseImportable(out string) []ImportablePool {
+	pools := []ImportablePool{}
+	var (
+		cur      *ImportablePool
+		field    string
+		inConfig bool
+		stack    []*ImportableVDev
+		rootSeen bool
+	)
+
+	flush := func() {
+		if cur != nil {
+			pools = append(pools, *cur)
+		}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		key, value, isField := splitImportField(trimmed)
+		if isField && key == "pool" {
+			flush()
+			cur = &ImportablePool{Name: value, VDevs: []*ImportableVDev{}}
+			field, inConfig, stack, rootSeen = "", false, nil, false
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+
+		if inConfig {
+			if !strings.HasPrefix(line, "\t") {
+				// Trailing free-form text after the config tree
+				continue
+			}
+			body := strings.TrimPrefix(line, "\t")
+			depth := (len(body) - len(strings.TrimLeft(body, " "))) / 2
+			vdev := parseImportVDev(strings.TrimSpace(body))
+
+			// stack holds the current row at each depth. The pool itself
+			// and class groups such as logs, cache and spares sit at depth
+			// 0; data vdevs under the pool root become top level vdevs.
+			if depth == 0 {
+				if !rootSeen {
+					rootSeen = true
+					stack = []*ImportableVDev{nil}
+					continue
+				}
+				cur.VDevs = append(cur.VDevs, vdev)
+				stack = []*ImportableVDev{vdev}
+				continue
+			}
+			if len(stack) == 0 {
+				stack = []*ImportableVDev{nil}
+			}
+			if depth > len(stack) {
+				depth = len(stack)
+			}
+			stack = stack[:depth]
+			if parent := stack[depth-1]; parent != nil {
+				parent.VDevs = append(parent.VDevs, vdev)
+			} else {
+				cur.VDevs = append(cur.VDevs, vdev)
+			}
+			stack = append(stack, vdev)
+			continue
+		}
+
+		if !isField {
+			// Continuation of a wrapped status or action message
+			switch field {
+			case "status":
+				cur.Status += " " + trimmed
+			case "action":
+				cur.Action += " " + trimmed
+			case "comment":
+				cur.Comment += " " + trimmed
+			}
+			continue
+		}
+
+		field = key
+		switch key {
+		case "id":
+			cur.GUID = value
+		case "state":
+			if strings.HasSuffix(value, "(DESTROYED)") {
+				cur.Destroyed = true
+				value = strings.TrimSpace(strings.TrimSuffix(value, "(DESTROYED)"))
+			}
+			cur.State = value
+		case "status":
+			cur.Status = value
+		case "action":
+			cur.Action = value
+		case "comment":
+			cur.Comment = value
+		case "see":
+			cur.See = value
+		case "config":
+			inConfig = true
+		}
+	}
+	flush()
+
+	return pools
+}
+
+// splitImportField splits a "key: value" header line of the import report
+func splitImportField(line string) (string, string, bool) {
+	key, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", "", false
+	}
+	switch key {
+	case "pool", "id", "state", "status", "action", "comment", "see", "config":
+		return key, strings.TrimSpace(value), true
+	}
+	return "", "", false
+}
+
+// parseImportVDev parses a config tree row: name, state and optional message
+func parseImportVDev(row string) *ImportableVDev {
+	fields := strings.Fields(row)
+	vdev := &ImportableVDev{Name: fields[0]}
+	if len(fields) > 1 {
+		vdev.State = fields[1]
+	}
+	if len(fields) > 2 {
+		vdev.Message = strings.Join(fields[2:], " ")
+	}
+	return vdev
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import "testing"
+
+const importableOutput = "   pool: tank\n" +
+	"     id: 15351207397411524035\n" +
+	"  state: ONLINE\n" +
+	" action: The pool can be imported using its name or numeric identifier.\n" +
+	" config:\n" +
+	"\n" +
+	"\ttank        ONLINE\n" +
+	"\t  mirror-0  ONLINE\n" +
+	"\t    sdb     ONLINE\n" +
+	"\t    sdc     ONLINE\n" +
+	"\tlogs\n" +
+	"\t  sdd       ONLINE\n" +
+	"\tspares\n" +
+	"\t  sde\n" +
+	"\n" +
+	"   pool: old\n" +
+	"     id: 9876543210\n" +
+	"  state: UNAVAIL (DESTROYED)\n" +
+	" status: One or more devices are missing from the system.\n" +
+	" action: The pool cannot be imported. Attach the missing\n" +
+	"\tdevices and try again.\n" +
+	"   see: https://openzfs.github.io/openzfs-docs/msg/ZFS-8000-3C\n" +
+	" config:\n" +
+	"\n" +
+	"\told                      UNAVAIL  insufficient replicas\n" +
+	"\t  raidz1-0               UNAVAIL  insufficient replicas\n" +
+	"\t    sdf                  ONLINE\n" +
+	"\t    1234567890123456789  UNAVAIL  cannot open\n" +
+	"\t    sdg                  UNAVAIL  cannot open\n"
+
+func TestParseImportable(t *testing.T) {
+	pools := parseImportable(importableOutput)
+	if len(pools) != 2 {
+		t.Fatalf("expected 2 pools, got %d", len(pools))
+	}
+
+	tank := pools[0]
+	if tank.Name != "tank" || tank.GUID != "15351207397411524035" || tank.State != "ONLINE" {
+		t.Errorf("unexpected pool header: %+v", tank)
+	}
+	if tank.Destroyed {
+		t.Error("tank should not be destroyed")
+	}
+	if len(tank.VDevs) != 3 {
+		t.Fatalf("expected mirror, logs and spares, got %d vdevs", len(tank.VDevs))
+	}
+	mirror := tank.VDevs[0]
+	if mirror.Name != "mirror-0" || len(mirror.VDevs) != 2 || mirror.VDevs[1].Name != "sdc" {
+		t.Errorf("unexpected mirror: %+v", mirror)
+	}
+	logs := tank.VDevs[1]
+	if logs.Name != "logs" || logs.State != "" || len(logs.VDevs) != 1 || logs.VDevs[0].Name != "sdd" {
+		t.Errorf("unexpected logs group: %+v", logs)
+	}
+	spares := tank.VDevs[2]
+	if spares.Name != "spares" || len(spares.VDevs) != 1 || spares.VDevs[0].State != "" {
+		t.Errorf("unexpected spares group: %+v", spares)
+	}
+
+	old := pools[1]
+	if !old.Destroyed || old.State != "UNAVAIL" {
+		t.Errorf("expected destroyed UNAVAIL pool, got %+v", old)
+	}
+	if old.Action != "The pool cannot be imported. Attach the missing devices and try again." {
+		t.Errorf("unexpected action: %q", old.Action)
+	}
+	if old.See == "" || old.Status == "" {
+		t.Errorf("expected status and see, got %+v", old)
+	}
+	if len(old.VDevs) != 1 || len(old.VDevs[0].VDevs) != 3 {
+		t.Fatalf("unexpected layout: %+v", old.VDevs)
+	}
+	missing := old.VDevs[0].VDevs[1]
+	if missing.State != "UNAVAIL" || missing.Message != "cannot open" {
+		t.Errorf("unexpected missing device: %+v", missing)
+	}
+}
+
+func TestParseImportableEmpty(t *testing.T) {
+	if pools := parseImportable(""); len(pools) != 0 {
+		t.Errorf("expected no pools, got %d", len(pools))
+	}
+}
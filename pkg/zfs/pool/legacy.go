@@ -0,0 +1,482 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"bufio"
+	"context"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/zfs/command"
+)
+
+// OpenZFS releases before 2.3 have no -j output. The functions here run
+// the tab separated (-H) or human readable forms of zpool list, get and
+// status with exact numbers (-p) and build the same structs the JSON
+// output decodes into.
+
+// listColumns are the columns of zpool list -j, plus guid which the JSON
+// output carries as pool_guid
+var listColumns = []string{
+	"name", "guid", "size", "allocated", "free", "checkpoint", "expandsize",
+	"fragmentation", "capacity", "dedupratio", "health", "altroot",
+}
+
+// auxClasses maps the group rows of the zpool status config tree to vdev
+// classes
+var auxClasses = map[string]string{
+	"logs":    "logs",
+	"cache":   "l2cache",
+	"spares":  "spares",
+	"special": "special",
+	"dedup":   "dedup",
+}
+
+var (
+	// scanDoneRegex matches a finished scrub or resilver:
+	// scrub repaired 0B in 00:00:01 with 0 errors on Sun Oct 12 00:24:01 2025
+	scanDoneRegex = regexp.MustCompile(
+		`^(error scrub|scrub|resilver)(?:ed)? repaired (\S+) in ((?:\d+ days )?\S+) with (\d+) errors on (.+)$`)
+	resilveredRegex = regexp.MustCompile(
+		`^resilvered (\S+) in ((?:\d+ days )?\S+) with (\d+) errors on (.+)$`)
+	// scanDurationRegex matches the duration of a finished scan, e.g.
+	// 00:00:01 or 1 days 02:03:04
+	scanDurationRegex = regexp.MustCompile(`^(?:(\d+) days )?(\d+):(\d+):(\d+)$`)
+	scanRunningRegex  = regexp.MustCompile(`^(error scrub|scrub|resilver) in progress since (.+)$`)
+	scanCanceledRegex = regexp.MustCompile(`^(error scrub|scrub|resilver) canceled on (.+)$`)
+	scanPausedRegex   = regexp.MustCompile(`^(error scrub|scrub) paused since (.+)$`)
+	dataErrorsRegex   = regexp.MustCompile(`^(\d+) data errors`)
+)
+
+func (p *Manager) listText(ctx context.Context) (ListResult, error) {
+	args := []string{"list", "-H", "-p", "-o", strings.Join(listColumns, ",")}
+
+	out, err := p.executor.Execute(ctx, command.CommandOptions{}, "zpool list", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return ListResult{}, errors.Wrap(err, errors.ZFSPoolList).
+				WithMetadata("output", string(out))
+		}
+		return ListResult{}, errors.Wrap(err, errors.ZFSPoolList)
+	}
+	return parseListText(string(out), listColumns)
+}
+
+func (p *Manager) getText(ctx context.Context, name, property string) (ListResult, error) {
+	args := []string{"get", "-H", "-p", "-o", "name,property,value,source", property}
+	if name != "" {
+		args = append(args, name)
+	}
+
+	out, err := p.executor.Execute(ctx, command.CommandOptions{}, "zpool get", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return ListResult{}, errors.Wrap(err, errors.ZFSPoolGetProperty).
+				WithMetadata("output", string(out))
+		}
+		return ListResult{}, errors.Wrap(err, errors.ZFSPoolGetProperty)
+	}
+	return parseGetText(string(out))
+}
+
+func (p *Manager) statusText(ctx context.Context, name string) (PoolStatus, error) {
+	args := []string{"status", "-P", "-p"}
+	if name != "" {
+		args = append(args, name)
+	}
+
+	out, err := p.executor.Execute(ctx, command.CommandOptions{}, "zpool status", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return PoolStatus{}, errors.Wrap(err, errors.ZFSPoolStatus).
+				WithMetadata("output", string(out))
+		}
+		return PoolStatus{}, errors.Wrap(err, errors.ZFSPoolStatus)
+	}
+	return parseStatusText(string(out)), nil
+}
+
+// parseListText parses zpool list -H -p -o output with the given columns
+func parseListText(out string, columns []string) (ListResult, error) {
+	result := ListResult{Pools: make(map[string]Pool)}
+
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != len(columns) {
+			return result, errors.New(errors.CommandOutputParse, "unexpected zpool list output").
+				WithMetadata("line", line)
+		}
+
+		pool := Pool{Type: "POOL", Properties: make(map[string]Property)}
+		for i, col := range columns {
+			switch col {
+			case "name":
+				pool.Name = fields[i]
+			case "guid":
+				pool.GUID = fields[i]
+			default:
+				if col == "health" {
+					pool.State = fields[i]
+				}
+				pool.Properties[col] = Property{
+					Value:  fields[i],
+					Source: Source{Type: "NONE", Data: "-"},
+				}
+			}
+		}
+		result.Pools[pool.Name] = pool
+	}
+	return result, nil
+}
+
+// parseGetText parses zpool get -H -p -o name,property,value,source output
+func parseGetText(out string) (ListResult, error) {
+	result := ListResult{Pools: make(map[string]Pool)}
+
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, "\t", 4)
+		if len(fields) != 4 {
+			return result, errors.New(errors.CommandOutputParse, "unexpected zpool get output").
+				WithMetadata("line", line)
+		}
+		name, property, value, source := fields[0], fields[1], fields[2], fields[3]
+
+		pool, ok := result.Pools[name]
+		if !ok {
+			pool = Pool{Name: name, Type: "POOL", Properties: make(map[string]Property)}
+		}
+		switch property {
+		case "health":
+			pool.State = value
+		case "guid":
+			pool.GUID = value
+		}
+		pool.Properties[property] = Property{Value: value, Source: parseSource(source)}
+		result.Pools[name] = pool
+	}
+	return result, nil
+}
+
+// parseSource converts the source column of zpool/zfs get to the source
+// object of the JSON output
+func parseSource(s string) Source {
+	switch {
+	case s == "-" || s == "":
+		return Source{Type: "NONE", Data: "-"}
+	case strings.HasPrefix(s, "inherited from "):
+		return Source{Type: "INHERITED", Data: strings.TrimPrefix(s, "inherited from ")}
+	}
+	return Source{Type: strings.ToUpper(s), Data: "-"}
+}
+
+// parseStatusText parses zpool status -P -p output:
+//
+//	  pool: tank
+//	 state: ONLINE
+//	  scan: scrub repaired 0B in 00:00:01 with 0 errors on Sun Oct 12 00:24:01 2025
+//	config:
+//
+//		NAME           STATE     READ WRITE CKSUM
+//		tank           ONLINE       0     0     0
+//		  mirror-0     ONLINE       0     0     0
+//		    /dev/sdb1  ONLINE       0     0     0
+//		    /dev/sdc1  ONLINE       0     0     0
+//		spares
+//		  /dev/sdd1    AVAIL
+//
+//	errors: No known data errors
+func parseStatusText(out string) PoolStatus {
+	status := PoolStatus{Pools: make(map[string]Pool)}
+
+	var (
+		cur      *Pool
+		field    string
+		inConfig bool
+		class    string
+		stack    []*VDev
+	)
+
+	flush := func() {
+		if cur != nil {
+			status.Pools[cur.Name] = *cur
+		}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		key, value, isField := splitStatusField(trimmed)
+		if isField && key == "pool" {
+			flush()
+			cur = &Pool{Name: value, VDevs: make(map[string]*VDev)}
+			field, inConfig, class, stack = "", false, "", nil
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+
+		if inConfig && strings.HasPrefix(line, "\t") && !isField {
+			body := strings.TrimPrefix(line, "\t")
+			depth := (len(body) - len(strings.TrimLeft(body, " "))) / 2
+			fields := strings.Fields(body)
+			if fields[0] == "NAME" && len(fields) > 1 && fields[1] == "STATE" {
+				continue
+			}
+
+			if depth == 0 {
+				if c, ok := auxClasses[fields[0]]; ok && len(fields) == 1 {
+					class = c
+					// The group row holds the place of the root vdev
+					stack = []*VDev{nil}
+					continue
+				}
+				// The pool itself is the root vdev
+				root := parseStatusVDev(fields)
+				root.VDevType = "root"
+				root.Path = ""
+				cur.VDevs[root.Name] = root
+				class = ""
+				stack = []*VDev{root}
+				continue
+			}
+
+			vdev := parseStatusVDev(fields)
+			if class != "" {
+				vdev.Class = class
+			}
+			if depth > len(stack) {
+				depth = len(stack)
+			}
+			stack = stack[:depth]
+
+			var siblings map[string]*VDev
+			switch {
+			case depth > 0 && stack[depth-1] != nil:
+				parent := stack[depth-1]
+				if parent.VDevs == nil {
+					parent.VDevs = make(map[string]*VDev)
+				}
+				siblings = parent.VDevs
+			default:
+				siblings = auxVDevs(cur, class)
+			}
+			siblings[vdev.Name] = vdev
+			stack = append(stack, vdev)
+			continue
+		}
+
+		if !isField {
+			// Continuation of a wrapped message
+			switch field {
+			case "status":
+				cur.Status += " " + trimmed
+			case "action":
+				cur.Action += " " + trimmed
+			}
+			continue
+		}
+
+		field = key
+		inConfig = false
+		switch key {
+		case "state":
+			cur.State = value
+		case "status":
+			cur.Status = value
+		case "action":
+			cur.Action = value
+		case "see":
+			cur.MoreInfo = value
+			cur.MsgID = filepath.Base(value)
+		case "scan":
+			cur.ScanStats = parseScanLine(value)
+		case "config":
+			inConfig = true
+		case "errors":
+			cur.ErrorCount = "0"
+			if m := dataErrorsRegex.FindStringSubmatch(value); m != nil {
+				cur.ErrorCount = m[1]
+			}
+		}
+	}
+	flush()
+
+	return status
+}
+
+// auxVDevs returns the map top level vdevs of class are kept in, creating
+// it as needed
+func auxVDevs(pool *Pool, class string) map[string]*VDev {
+	var m *map[string]*VDev
+	switch class {
+	case "logs":
+		m = &pool.Logs
+	case "l2cache":
+		m = &pool.L2Cache
+	case "spares":
+		m = &pool.Spares
+	case "special":
+		m = &pool.Special
+	case "dedup":
+		m = &pool.Dedup
+	default:
+		m = &pool.VDevs
+	}
+	if *m == nil {
+		*m = make(map[string]*VDev)
+	}
+	return *m
+}
+
+// splitStatusField splits a "key: value" header line of zpool status
+func splitStatusField(line string) (string, string, bool) {
+	key, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", "", false
+	}
+	switch key {
+	case "pool", "state", "status", "action", "see", "scan", "config", "errors",
+		"remove", "checkpoint":
+		return key, strings.TrimSpace(value), true
+	}
+	return "", "", false
+}
+
+// parseStatusVDev parses a config tree row: name, state, read, write and
+// checksum errors. Spares only have a name and state.
+func parseStatusVDev(fields []string) *VDev {
+	vdev := &VDev{Name: fields[0], VDevType: vdevTypeFromName(fields[0])}
+	if strings.HasPrefix(fields[0], "/") {
+		vdev.Path = fields[0]
+		vdev.Name = filepath.Base(fields[0])
+	}
+	if len(fields) > 1 {
+		vdev.State = fields[1]
+	}
+	if len(fields) > 4 {
+		vdev.ReadErrors = fields[2]
+		vdev.WriteErrors = fields[3]
+		vdev.ChecksumErrors = fields[4]
+	}
+	return vdev
+}
+
+// vdevTypeFromName derives the vdev type from its name in the config tree,
+// e.g. mirror-0, raidz2-1 or draid1:4d:8c:1s-0
+func vdevTypeFromName(name string) string {
+	if strings.HasPrefix(name, "/") {
+		if strings.HasPrefix(name, "/dev/") {
+			return "disk"
+		}
+		return "file"
+	}
+	kind := strings.SplitN(name, "-", 2)[0]
+	kind = strings.SplitN(kind, ":", 2)[0]
+	switch {
+	case kind == "mirror", kind == "spare", kind == "replacing", kind == "indirect", kind == "hole":
+		return kind
+	case strings.HasPrefix(kind, "raidz"):
+		return "raidz"
+	case strings.HasPrefix(kind, "draid"):
+		return "draid"
+	}
+	return "disk"
+}
+
+// parseScanLine converts the scan line of zpool status into scan stats.
+// Only the fields the text carries are set, except that the start time of a
+// finished scan is derived from its end time and duration.
+func parseScanLine(line string) *ScanStats {
+	function := func(s string) string {
+		if s == "error scrub" {
+			return "ERRORSCRUB"
+		}
+		return strings.ToUpper(s)
+	}
+
+	switch {
+	case line == "none requested":
+		return nil
+	case scanDoneRegex.MatchString(line):
+		m := scanDoneRegex.FindStringSubmatch(line)
+		return &ScanStats{
+			Function: function(m[1]), State: "FINISHED",
+			Processed: m[2], Errors: m[4], EndTime: m[5],
+			StartTime: scanStartTime(m[5], m[3]),
+		}
+	case resilveredRegex.MatchString(line):
+		m := resilveredRegex.FindStringSubmatch(line)
+		return &ScanStats{
+			Function: "RESILVER", State: "FINISHED",
+			Processed: m[1], Errors: m[3], EndTime: m[4],
+			StartTime: scanStartTime(m[4], m[2]),
+		}
+	case scanRunningRegex.MatchString(line):
+		m := scanRunningRegex.FindStringSubmatch(line)
+		return &ScanStats{Function: function(m[1]), State: "SCANNING", StartTime: m[2]}
+	case scanCanceledRegex.MatchString(line):
+		m := scanCanceledRegex.FindStringSubmatch(line)
+		return &ScanStats{Function: function(m[1]), State: "CANCELED", EndTime: m[2]}
+	case scanPausedRegex.MatchString(line):
+		m := scanPausedRegex.FindStringSubmatch(line)
+		return &ScanStats{Function: function(m[1]), State: "SCANNING", ScrubPause: m[2]}
+	}
+	return nil
+}
+
+// scanStartTime subtracts the duration of a finished scan from its end time.
+// It returns an empty string if either can't be parsed.
+func scanStartTime(end, duration string) string {
+	t, ok := parseScanTime(end)
+	if !ok {
+		return ""
+	}
+	m := scanDurationRegex.FindStringSubmatch(duration)
+	if m == nil {
+		return ""
+	}
+	var secs int64
+	for i, unit := range []int64{86400, 3600, 60, 1} {
+		if m[i+1] == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(m[i+1], 10, 64)
+		if err != nil {
+			return ""
+		}
+		secs += n * unit
+	}
+	return t.Add(-time.Duration(secs) * time.Second).Format(time.ANSIC)
+}
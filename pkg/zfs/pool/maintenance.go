@@ -0,0 +1,398 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/zfs/command"
+	"github.com/stratastor/rodent/pkg/zfs/common"
+)
+
+// MaintenanceChangeType identifies the change applied in a maintenance session
+type MaintenanceChangeType string
+
+const (
+	ChangeSetProperty   MaintenanceChangeType = "set_property"
+	ChangeEnableFeature MaintenanceChangeType = "enable_feature"
+	ChangeUpgrade       MaintenanceChangeType = "upgrade"
+	ChangeAddVDevs      MaintenanceChangeType = "add_vdevs"
+)
+
+// MaintenanceState is the lifecycle state of a maintenance session
+type MaintenanceState string
+
+const (
+	// Checkpoint taken, the change has not been applied (or failed to apply)
+	MaintenanceCheckpointed MaintenanceState = "checkpointed"
+	// Change applied, waiting for the operator to confirm or roll back
+	MaintenanceApplied MaintenanceState = "applied"
+	// Operator confirmed, checkpoint discarded
+	MaintenanceCommitted MaintenanceState = "committed"
+	// Pool rewound to the checkpoint
+	MaintenanceRolledBack MaintenanceState = "rolled_back"
+	// Checkpoint discarded without confirming; an applied change stays
+	MaintenanceAbandoned MaintenanceState = "abandoned"
+
+	// Transitional states while zpool runs for the session
+	MaintenanceApplying    MaintenanceState = "applying"
+	MaintenanceConfirming  MaintenanceState = "confirming"
+	MaintenanceRollingBack MaintenanceState = "rolling_back"
+	MaintenanceAbandoning  MaintenanceState = "abandoning"
+)
+
+var featureNameRegex = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// MaintenanceChange describes the risky change guarded by a checkpoint
+type MaintenanceChange struct {
+	Type     MaintenanceChangeType `json:"type"               binding:"required"`
+	Property string                `json:"property,omitempty"`
+	Value    string                `json:"value,omitempty"`
+	Feature  string                `json:"feature,omitempty"`
+	VDevSpec []VDevSpec            `json:"vdev_spec,omitempty"`
+	Force    bool                  `json:"force,omitempty"`
+}
+
+// MaintenanceSession tracks a checkpoint guarded change to a pool
+type MaintenanceSession struct {
+	ID        string            `json:"id"`
+	Pool      string            `json:"pool"`
+	Change    MaintenanceChange `json:"change"`
+	State     MaintenanceState  `json:"state"`
+	Error     string            `json:"error,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// maintenanceStore keeps maintenance sessions in memory. Sessions don't
+// survive a restart; the checkpoint itself does and can still be handled
+// through the checkpoint endpoints.
+//
+// mu only guards the map and session fields. It is not held while zpool
+// runs; a session is moved to a transitional state instead, which keeps
+// other requests off the session and its pool until the command is done.
+type maintenanceStore struct {
+	mu       sync.Mutex
+	sessions map[string]*MaintenanceSession
+}
+
+func newMaintenanceStore() *maintenanceStore {
+	return &maintenanceStore{sessions: make(map[string]*MaintenanceSession)}
+}
+
+// active returns the unfinished session of a pool. The caller holds mu.
+func (m *maintenanceStore) active(pool string) *MaintenanceSession {
+	for _, s := range m.sessions {
+		if s.Pool == pool && s.active() {
+			return s
+		}
+	}
+	return nil
+}
+
+// begin moves the session returned by find to a transitional state if it
+// is in one of the from states, and returns the state it was in
+func (m *maintenanceStore) begin(
+	find func() (*MaintenanceSession, error),
+	action string,
+	to MaintenanceState,
+	from ...MaintenanceState,
+) (*MaintenanceSession, MaintenanceState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, err := find()
+	if err != nil || session == nil {
+		return nil, "", err
+	}
+	for _, state := range from {
+		if session.State == state {
+			session.State = to
+			session.UpdatedAt = time.Now().UTC()
+			return session, state, nil
+		}
+	}
+	return nil, "", errors.New(errors.ZFSPoolMaintenanceState,
+		fmt.Sprintf("session %s is %s and can't be %s", session.ID, session.State, action))
+}
+
+// byID finds a session for begin
+func (m *maintenanceStore) byID(id string) func() (*MaintenanceSession, error) {
+	return func() (*MaintenanceSession, error) {
+		session, ok := m.sessions[id]
+		if !ok {
+			return nil, errors.New(errors.ZFSPoolMaintenanceNotFound, id)
+		}
+		return session, nil
+	}
+}
+
+// finish ends a transition in state, recording err if the command failed
+func (m *maintenanceStore) finish(
+	session *MaintenanceSession,
+	state MaintenanceState,
+	err error,
+) MaintenanceSession {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session.State = state
+	session.Error = ""
+	if err != nil {
+		session.Error = err.Error()
+	}
+	session.UpdatedAt = time.Now().UTC()
+	return *session
+}
+
+func (m *maintenanceStore) remove(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+}
+
+func (c MaintenanceChange) validate() error {
+	switch c.Type {
+	case ChangeSetProperty:
+		if !common.IsValidPoolProperty(c.Property, common.AnytimePoolPropContext) {
+			return errors.New(errors.ZFSPropertyError,
+				fmt.Sprintf("invalid pool property %q", c.Property))
+		}
+		if c.Value == "" {
+			return errors.New(errors.ServerRequestValidation, "property value is required")
+		}
+	case ChangeEnableFeature:
+		if !featureNameRegex.MatchString(c.Feature) {
+			return errors.New(errors.ServerRequestValidation,
+				fmt.Sprintf("invalid feature name %q", c.Feature))
+		}
+	case ChangeUpgrade:
+	case ChangeAddVDevs:
+		if len(c.VDevSpec) == 0 {
+			return errors.New(errors.ServerRequestValidation, "vdev_spec is required")
+		}
+	default:
+		return errors.New(errors.ServerRequestValidation,
+			fmt.Sprintf("unsupported maintenance change %q", c.Type))
+	}
+	return nil
+}
+
+func (p *Manager) applyChange(ctx context.Context, name string, c MaintenanceChange) error {
+	switch c.Type {
+	case ChangeSetProperty:
+		return p.SetProperty(ctx, name, c.Property, c.Value)
+	case ChangeEnableFeature:
+		return p.SetProperty(ctx, name, "feature@"+c.Feature, "enabled")
+	case ChangeUpgrade:
+		return p.Upgrade(ctx, name)
+	case ChangeAddVDevs:
+		_, err := p.AddVDevs(ctx, AddConfig{Name: name, VDevSpec: c.VDevSpec, Force: c.Force})
+		return err
+	}
+	return errors.New(errors.ServerRequestValidation,
+		fmt.Sprintf("unsupported maintenance change %q", c.Type))
+}
+
+// Upgrade enables all supported features on the pool
+func (p *Manager) Upgrade(ctx context.Context, name string) error {
+	args := []string{"upgrade", name}
+
+	out, err := p.executor.Execute(ctx, command.CommandOptions{}, "zpool upgrade", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return errors.Wrap(err, errors.ZFSPoolSetProperty).
+				WithMetadata("output", string(out))
+		}
+		return errors.Wrap(err, errors.ZFSPoolSetProperty)
+	}
+	return nil
+}
+
+// StartMaintenance checkpoints the pool and applies the change. The
+// checkpoint is kept until the session is confirmed or rolled back. If the
+// change fails the session stays checkpointed so the operator can decide.
+func (p *Manager) StartMaintenance(
+	ctx context.Context,
+	name string,
+	change MaintenanceChange,
+) (*MaintenanceSession, error) {
+	if err := change.validate(); err != nil {
+		return nil, err
+	}
+
+	p.sessions.mu.Lock()
+	if s := p.sessions.active(name); s != nil {
+		p.sessions.mu.Unlock()
+		return nil, errors.New(errors.ZFSPoolMaintenanceState,
+			fmt.Sprintf("pool %s already has active maintenance session %s", name, s.ID))
+	}
+	now := time.Now().UTC()
+	session := &MaintenanceSession{
+		ID:        uuid.New().String(),
+		Pool:      name,
+		Change:    change,
+		State:     MaintenanceApplying,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	p.sessions.sessions[session.ID] = session
+	p.sessions.mu.Unlock()
+
+	// Dry-run vdev additions first so that an obviously bad layout doesn't
+	// leave a checkpoint behind
+	if change.Type == ChangeAddVDevs {
+		if _, err := p.AddVDevs(ctx, AddConfig{
+			Name:     name,
+			VDevSpec: change.VDevSpec,
+			Force:    change.Force,
+			DryRun:   true,
+		}); err != nil {
+			p.sessions.remove(session.ID)
+			return nil, err
+		}
+	}
+
+	if err := p.Checkpoint(ctx, name); err != nil {
+		p.sessions.remove(session.ID)
+		return nil, err
+	}
+
+	err := p.applyChange(ctx, name, change)
+	state := MaintenanceApplied
+	if err != nil {
+		state = MaintenanceCheckpointed
+	}
+	s := p.sessions.finish(session, state, err)
+	return &s, nil
+}
+
+// GetMaintenance returns a maintenance session by ID
+func (p *Manager) GetMaintenance(id string) (*MaintenanceSession, error) {
+	p.sessions.mu.Lock()
+	defer p.sessions.mu.Unlock()
+
+	session, ok := p.sessions.sessions[id]
+	if !ok {
+		return nil, errors.New(errors.ZFSPoolMaintenanceNotFound, id)
+	}
+	s := *session
+	return &s, nil
+}
+
+// ListMaintenance returns the maintenance sessions of a pool
+func (p *Manager) ListMaintenance(name string) []MaintenanceSession {
+	p.sessions.mu.Lock()
+	defer p.sessions.mu.Unlock()
+
+	sessions := []MaintenanceSession{}
+	for _, s := range p.sessions.sessions {
+		if s.Pool == name {
+			sessions = append(sessions, *s)
+		}
+	}
+	return sessions
+}
+
+// ConfirmMaintenance keeps the applied change and discards the checkpoint
+func (p *Manager) ConfirmMaintenance(ctx context.Context, id string) (*MaintenanceSession, error) {
+	session, prev, err := p.sessions.begin(p.sessions.byID(id), "confirmed",
+		MaintenanceConfirming, MaintenanceApplied)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.discardCheckpoint(ctx, session.Pool, false); err != nil {
+		p.sessions.finish(session, prev, err)
+		return nil, err
+	}
+
+	s := p.sessions.finish(session, MaintenanceCommitted, nil)
+	return &s, nil
+}
+
+// RollbackMaintenance rewinds the pool to the session checkpoint. The pool is
+// exported and imported again with --rewind-to-checkpoint, so it must not be
+// in use.
+func (p *Manager) RollbackMaintenance(
+	ctx context.Context,
+	id string,
+	force bool,
+) (*MaintenanceSession, error) {
+	session, prev, err := p.sessions.begin(p.sessions.byID(id), "rolled back",
+		MaintenanceRollingBack, MaintenanceCheckpointed, MaintenanceApplied)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.Export(ctx, session.Pool, force); err != nil {
+		p.sessions.finish(session, prev, err)
+		return nil, err
+	}
+
+	if err := p.Import(ctx, ImportConfig{
+		Name:               session.Pool,
+		RewindToCheckpoint: true,
+	}); err != nil {
+		p.sessions.finish(session, prev, err)
+		return nil, err
+	}
+
+	s := p.sessions.finish(session, MaintenanceRolledBack, nil)
+	return &s, nil
+}
+
+// AbandonMaintenance discards the checkpoint of a session without
+// confirming it, typically after the change failed to apply. A change that
+// was applied is kept.
+func (p *Manager) AbandonMaintenance(ctx context.Context, id string) (*MaintenanceSession, error) {
+	session, prev, err := p.sessions.begin(p.sessions.byID(id), "abandoned",
+		MaintenanceAbandoning, MaintenanceCheckpointed, MaintenanceApplied)
+	if err != nil {
+		return nil, err
+	}
+	return p.abandon(ctx, session, prev, false)
+}
+
+func (p *Manager) abandon(
+	ctx context.Context,
+	session *MaintenanceSession,
+	prev MaintenanceState,
+	wait bool,
+) (*MaintenanceSession, error) {
+	if err := p.discardCheckpoint(ctx, session.Pool, wait); err != nil {
+		p.sessions.finish(session, prev, err)
+		return nil, err
+	}
+	s := p.sessions.finish(session, MaintenanceAbandoned, nil)
+	return &s, nil
+}
+
+func (s *MaintenanceSession) active() bool {
+	switch s.State {
+	case MaintenanceCommitted, MaintenanceRolledBack, MaintenanceAbandoned:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stratastor/logger"
+	"github.com/stratastor/rodent/pkg/zfs/command"
+)
+
+// fakeZpool stands in for the zpool binary. Discarding a checkpoint blocks
+// until the gate file exists.
+const fakeZpool = `#!/bin/sh
+if [ "$1 $2" = "checkpoint -d" ]; then
+	while [ ! -e %q ]; do sleep 0.01; done
+fi
+exit 0
+`
+
+func newMaintenanceManager(t *testing.T) (*Manager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	gate := filepath.Join(dir, "gate")
+	bin := filepath.Join(dir, "zpool")
+	if err := os.WriteFile(bin, []byte(fmt.Sprintf(fakeZpool, gate)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	zpool := command.BinZpool
+	command.BinZpool = bin
+	t.Cleanup(func() { command.BinZpool = zpool })
+
+	executor := command.NewCommandExecutor(false, logger.Config{LogLevel: "error"})
+	executor.SetVersion("2.2.2")
+	return NewManager(executor), gate
+}
+
+func TestMaintenanceConfirmReleasesLock(t *testing.T) {
+	p, gate := newMaintenanceManager(t)
+	ctx := context.Background()
+
+	session, err := p.StartMaintenance(ctx, "tank",
+		MaintenanceChange{Type: ChangeEnableFeature, Feature: "draid"})
+	if err != nil {
+		t.Fatalf("StartMaintenance failed: %v", err)
+	}
+	if session.State != MaintenanceApplied {
+		t.Fatalf("state = %s, want %s", session.State, MaintenanceApplied)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.ConfirmMaintenance(ctx, session.ID)
+		done <- err
+	}()
+
+	// While zpool checkpoint -d runs, the session can be read and other
+	// transitions are refused
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s, err := p.GetMaintenance(session.ID)
+		if err != nil {
+			t.Fatalf("GetMaintenance failed: %v", err)
+		}
+		if s.State == MaintenanceConfirming {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("session never became %s", MaintenanceConfirming)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := p.RollbackMaintenance(ctx, session.ID, false); err == nil {
+		t.Error("rollback allowed while confirming")
+	}
+	if err := p.DiscardCheckpoint(ctx, "tank", false); err == nil {
+		t.Error("checkpoint discard allowed while confirming")
+	}
+
+	if err := os.WriteFile(gate, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("ConfirmMaintenance failed: %v", err)
+	}
+	if s, _ := p.GetMaintenance(session.ID); s.State != MaintenanceCommitted {
+		t.Errorf("state = %s, want %s", s.State, MaintenanceCommitted)
+	}
+}
+
+func TestDiscardCheckpointAbandonsSession(t *testing.T) {
+	p, gate := newMaintenanceManager(t)
+	ctx := context.Background()
+	if err := os.WriteFile(gate, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := p.StartMaintenance(ctx, "tank", MaintenanceChange{Type: ChangeUpgrade})
+	if err != nil {
+		t.Fatalf("StartMaintenance failed: %v", err)
+	}
+	if err := p.DiscardCheckpoint(ctx, "tank", false); err != nil {
+		t.Fatalf("DiscardCheckpoint failed: %v", err)
+	}
+	if s, _ := p.GetMaintenance(session.ID); s.State != MaintenanceAbandoned {
+		t.Errorf("state = %s, want %s", s.State, MaintenanceAbandoned)
+	}
+	if _, err := p.ConfirmMaintenance(ctx, session.ID); err == nil {
+		t.Error("abandoned session confirmed")
+	}
+
+	// The pool is free for a new session
+	if _, err := p.StartMaintenance(ctx, "tank", MaintenanceChange{Type: ChangeUpgrade}); err != nil {
+		t.Errorf("StartMaintenance after abandon failed: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+// DeviceNamer maps device paths such as /dev/sdb, which can change across
+// reboots, to stable names. disk.Naming implements it.
+type DeviceNamer interface {
+	StablePath(path string) string
+	KernelName(path string) string
+}
+
+// SetDeviceNamer sets the naming applied to new devices before they are
+// passed to zpool and used to annotate Status. Paths are passed through
+// unchanged when no namer is set.
+func (p *Manager) SetDeviceNamer(n DeviceNamer) {
+	p.namer = n
+}
+
+func (p *Manager) stablePath(path string) string {
+	if p.namer == nil {
+		return path
+	}
+	return p.namer.StablePath(path)
+}
+
+// stableSpecs returns a copy of specs with all devices mapped to their
+// stable names
+func (p *Manager) stableSpecs(specs []VDevSpec) []VDevSpec {
+	if p.namer == nil || specs == nil {
+		return specs
+	}
+	mapped := make([]VDevSpec, len(specs))
+	for i, spec := range specs {
+		mapped[i] = VDevSpec{Type: spec.Type, Children: p.stableSpecs(spec.Children)}
+		for _, device := range spec.Devices {
+			mapped[i].Devices = append(mapped[i].Devices, p.namer.StablePath(device))
+		}
+	}
+	return mapped
+}
+
+// annotateDevices sets the stable path and current kernel name of every
+// leaf vdev
+func (p *Manager) annotateDevices(status *PoolStatus) {
+	if p.namer == nil {
+		return
+	}
+
+	var walk func(vdevs map[string]*VDev)
+	walk = func(vdevs map[string]*VDev) {
+		for _, v := range vdevs {
+			if v.Path != "" {
+				v.StablePath = p.namer.StablePath(v.Path)
+				v.KernelName = p.namer.KernelName(v.Path)
+			}
+			walk(v.VDevs)
+		}
+	}
+
+	for _, pool := range status.Pools {
+		for _, vdevs := range []map[string]*VDev{
+			pool.VDevs, pool.Logs, pool.L2Cache, pool.Spares, pool.Special, pool.Dedup,
+		} {
+			walk(vdevs)
+		}
+	}
+}
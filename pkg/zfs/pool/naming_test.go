@@ -0,0 +1,88 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"strings"
+	"testing"
+)
+
+// fakeNamer maps /dev/sdX to a by-id link named after the kernel name
+type fakeNamer struct{}
+
+func (fakeNamer) StablePath(path string) string {
+	if strings.HasPrefix(path, "/dev/disk/") {
+		return path
+	}
+	return "/dev/disk/by-id/ata-DISK_" + strings.TrimPrefix(path, "/dev/")
+}
+
+func (fakeNamer) KernelName(path string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(path, "/dev/disk/by-id/ata-DISK_"), "/dev/")
+}
+
+func TestStableSpecs(t *testing.T) {
+	p := &Manager{}
+	specs := []VDevSpec{
+		{Type: "mirror", Devices: []string{"/dev/sdb", "/dev/disk/by-id/ata-DISK_sdc"}},
+		{Type: "log", Children: []VDevSpec{{Devices: []string{"/dev/nvme0n1"}}}},
+	}
+
+	if got := p.stableSpecs(specs); got[0].Devices[0] != "/dev/sdb" {
+		t.Errorf("expected paths unchanged without a namer, got %v", got)
+	}
+
+	p.SetDeviceNamer(fakeNamer{})
+	got := p.stableSpecs(specs)
+	if got[0].Devices[0] != "/dev/disk/by-id/ata-DISK_sdb" ||
+		got[0].Devices[1] != "/dev/disk/by-id/ata-DISK_sdc" {
+		t.Errorf("unexpected mirror devices: %v", got[0].Devices)
+	}
+	if got[1].Children[0].Devices[0] != "/dev/disk/by-id/ata-DISK_nvme0n1" {
+		t.Errorf("unexpected log devices: %v", got[1].Children[0].Devices)
+	}
+	if specs[0].Devices[0] != "/dev/sdb" {
+		t.Error("stableSpecs modified its input")
+	}
+}
+
+func TestAnnotateDevices(t *testing.T) {
+	p := &Manager{}
+	p.SetDeviceNamer(fakeNamer{})
+
+	leaf := &VDev{Name: "sdb1", Path: "/dev/sdb1"}
+	spare := &VDev{Name: "ata-DISK_sdd", Path: "/dev/disk/by-id/ata-DISK_sdd"}
+	status := PoolStatus{Pools: map[string]Pool{
+		"tank": {
+			VDevs: map[string]*VDev{
+				"tank": {VDevs: map[string]*VDev{
+					"mirror-0": {VDevs: map[string]*VDev{"sdb1": leaf}},
+				}},
+			},
+			Spares: map[string]*VDev{"ata-DISK_sdd": spare},
+		},
+	}}
+
+	p.annotateDevices(&status)
+	if leaf.StablePath != "/dev/disk/by-id/ata-DISK_sdb1" || leaf.KernelName != "sdb1" {
+		t.Errorf("unexpected leaf annotation: %+v", leaf)
+	}
+	if spare.StablePath != spare.Path || spare.KernelName != "sdd" {
+		t.Errorf("unexpected spare annotation: %+v", spare)
+	}
+}
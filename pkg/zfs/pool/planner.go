@@ -0,0 +1,373 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/zfs/command"
+)
+
+const (
+	// sectorSize is the unit of the size attribute in sysfs
+	sectorSize = 512
+	// minAshift is the default ashift floor; 4K sectors are safe on all
+	// current disks even when they report 512 byte sectors
+	minAshift = 12
+	maxAshift = 16
+	// mixedSizeTolerance is the relative size difference, in percent,
+	// above which disks are considered to be of different sizes
+	mixedSizeTolerance = 1
+	// maxRaidzWidth is the width above which resilvering a raidz vdev
+	// becomes slow enough to warn about
+	maxRaidzWidth = 12
+	// defaultDraidData mirrors the zpool default of 8 data devices per group
+	defaultDraidData = 8
+)
+
+// sysBlockDir is where disk sizes and sector sizes are read from
+var sysBlockDir = "/sys/class/block"
+
+// Plan validates a target layout against the given disks and returns the
+// resulting create configuration, estimated capacity and the layout
+// reported by zpool create -n. Nothing is written to the disks.
+func (p *Manager) Plan(ctx context.Context, cfg PlanConfig) (*CreatePlan, error) {
+	plan, err := planLayout(cfg)
+	if err != nil {
+		return nil, err
+	}
+	plan.Config.VDevSpec = p.stableSpecs(plan.Config.VDevSpec)
+
+	args, err := createArgs(plan.Config)
+	if err != nil {
+		return nil, err
+	}
+	args = append([]string{"-n"}, args...)
+
+	// -f as Create passes it, so the preview matches what create would do
+	opts := command.CommandOptions{
+		Flags: command.FlagForce,
+	}
+
+	out, err := p.executor.Execute(ctx, opts, "zpool create", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return nil, errors.Wrap(err, errors.ZFSPoolLayout).
+				WithMetadata("output", string(out))
+		}
+		return nil, errors.Wrap(err, errors.ZFSPoolLayout)
+	}
+	plan.Preview = strings.TrimSpace(string(out))
+
+	return plan, nil
+}
+
+// planLayout splits the disks into vdevs of the requested layout and checks
+// sizes, redundancy and ashift
+func planLayout(cfg PlanConfig) (*CreatePlan, error) {
+	if cfg.Name == "" {
+		return nil, errors.New(errors.ZFSPoolLayout, "pool name is required")
+	}
+	if len(cfg.Disks) == 0 {
+		return nil, errors.New(errors.ZFSPoolLayout, "no disks specified")
+	}
+
+	plan := &CreatePlan{}
+	seen := make(map[string]bool)
+	for _, path := range cfg.Disks {
+		if !filepath.IsAbs(path) {
+			return nil, errors.New(errors.ZFSPoolLayout,
+				"disk paths must be absolute").WithMetadata("disk", path)
+		}
+		if seen[path] {
+			return nil, errors.New(errors.ZFSPoolLayout,
+				"disk specified more than once").WithMetadata("disk", path)
+		}
+		seen[path] = true
+
+		disk, err := readDiskInfo(path)
+		if err != nil {
+			return nil, err
+		}
+		plan.Disks = append(plan.Disks, disk)
+		plan.RawSize += disk.Size
+	}
+
+	if err := checkDiskSizes(cfg, plan); err != nil {
+		return nil, err
+	}
+
+	ashift, err := planAshift(cfg, plan)
+	if err != nil {
+		return nil, err
+	}
+	plan.Ashift = ashift
+
+	specs, usable, err := planVDevs(cfg.Layout, plan)
+	if err != nil {
+		return nil, err
+	}
+	// zpool reserves 1/32 of the pool as slop space
+	plan.Usable = usable - usable/32
+
+	props := make(map[string]string, len(cfg.Properties)+1)
+	for k, v := range cfg.Properties {
+		props[k] = v
+	}
+	props["ashift"] = strconv.Itoa(ashift)
+
+	plan.Config = CreateConfig{
+		Name:       cfg.Name,
+		VDevSpec:   specs,
+		Properties: props,
+		Features:   cfg.Features,
+		Force:      cfg.Force,
+		MountPoint: cfg.MountPoint,
+		AltRoot:    cfg.AltRoot,
+	}
+
+	return plan, nil
+}
+
+// planVDevs builds the vdev specs of a layout and returns the usable bytes
+// before slop space
+func planVDevs(layout LayoutConfig, plan *CreatePlan) ([]VDevSpec, uint64, error) {
+	disks := plan.Disks
+	n := len(disks)
+
+	switch layout.Type {
+	case LayoutStripe:
+		if n > 1 {
+			plan.Warnings = append(plan.Warnings,
+				"striped pool has no redundancy, losing any disk loses the pool")
+		}
+		spec := VDevSpec{}
+		for _, d := range disks {
+			spec.Devices = append(spec.Devices, d.Path)
+		}
+		return []VDevSpec{spec}, plan.RawSize, nil
+
+	case LayoutMirror, LayoutRaidz1, LayoutRaidz2, LayoutRaidz3:
+		parity := layoutParity(layout.Type)
+		width := layout.Width
+		if width == 0 {
+			width = n
+			if layout.Type == LayoutMirror {
+				width = 2
+			}
+		}
+		if width < parity+1 {
+			return nil, 0, errors.New(errors.ZFSPoolLayout,
+				fmt.Sprintf("%s needs at least %d disks per vdev", layout.Type, parity+1))
+		}
+		if n%width != 0 {
+			return nil, 0, errors.New(errors.ZFSPoolLayout,
+				fmt.Sprintf("%d disks cannot be split into %s vdevs of width %d",
+					n, layout.Type, width))
+		}
+		if layout.Type != LayoutMirror {
+			if width < parity+2 {
+				plan.Warnings = append(plan.Warnings, fmt.Sprintf(
+					"%s with width %d has the capacity of a mirror, consider mirrors instead",
+					layout.Type, width))
+			}
+			if width > maxRaidzWidth {
+				plan.Warnings = append(plan.Warnings, fmt.Sprintf(
+					"%s vdevs wider than %d disks take long to resilver", layout.Type, maxRaidzWidth))
+			}
+		}
+
+		var specs []VDevSpec
+		var usable uint64
+		for i := 0; i < n; i += width {
+			group := disks[i : i+width]
+			spec := VDevSpec{Type: layout.Type}
+			smallest := group[0].Size
+			for _, d := range group {
+				spec.Devices = append(spec.Devices, d.Path)
+				smallest = min(smallest, d.Size)
+			}
+			specs = append(specs, spec)
+			if layout.Type == LayoutMirror {
+				usable += smallest
+			} else {
+				usable += smallest * uint64(width-parity)
+			}
+		}
+		return specs, usable, nil
+
+	case LayoutDraid1, LayoutDraid2, LayoutDraid3:
+		parity := layoutParity(layout.Type)
+		spares := layout.DraidSpares
+		data := layout.DraidData
+		if spares < 0 || data < 0 {
+			return nil, 0, errors.New(errors.ZFSPoolLayout,
+				"dRAID data and spare counts cannot be negative")
+		}
+		if data == 0 {
+			data = min(defaultDraidData, n-parity-spares)
+		}
+		if data < 1 || n < data+parity+spares {
+			return nil, 0, errors.New(errors.ZFSPoolLayout, fmt.Sprintf(
+				"%s with %d data and %d spares needs at least %d disks",
+				layout.Type, max(data, 1), spares, max(data, 1)+parity+spares))
+		}
+
+		spec := VDevSpec{
+			Type: fmt.Sprintf("%s:%dd:%ds:%dc", layout.Type, data, spares, n),
+		}
+		smallest := disks[0].Size
+		for _, d := range disks {
+			spec.Devices = append(spec.Devices, d.Path)
+			smallest = min(smallest, d.Size)
+		}
+		usable := smallest * uint64(n-spares) * uint64(data) / uint64(data+parity)
+		return []VDevSpec{spec}, usable, nil
+	}
+
+	return nil, 0, errors.New(errors.ZFSPoolLayout,
+		fmt.Sprintf("unsupported layout type %q", layout.Type))
+}
+
+// layoutParity returns the number of disks a vdev of the layout may lose
+func layoutParity(layoutType string) int {
+	switch layoutType {
+	case LayoutMirror, LayoutRaidz1, LayoutDraid1:
+		return 1
+	case LayoutRaidz2, LayoutDraid2:
+		return 2
+	case LayoutRaidz3, LayoutDraid3:
+		return 3
+	}
+	return 0
+}
+
+// checkDiskSizes rejects disks of different sizes unless allowed, since
+// every vdev is limited by its smallest disk
+func checkDiskSizes(cfg PlanConfig, plan *CreatePlan) error {
+	smallest, largest := plan.Disks[0].Size, plan.Disks[0].Size
+	for _, d := range plan.Disks {
+		smallest = min(smallest, d.Size)
+		largest = max(largest, d.Size)
+	}
+	if (largest-smallest)*100 <= largest*mixedSizeTolerance {
+		return nil
+	}
+
+	msg := fmt.Sprintf("disk sizes range from %d to %d bytes, vdevs are limited to their smallest disk",
+		smallest, largest)
+	if !cfg.AllowMixedSizes {
+		return errors.New(errors.ZFSPoolLayout, msg+", use allow_mixed_sizes to override")
+	}
+	plan.Warnings = append(plan.Warnings, msg)
+	return nil
+}
+
+// planAshift picks or validates the ashift against the physical sector size
+// of the disks. An ashift below the sector size causes read-modify-write on
+// every write and cannot be changed after the vdev is created.
+func planAshift(cfg PlanConfig, plan *CreatePlan) (int, error) {
+	var largest, smallest uint64
+	for _, d := range plan.Disks {
+		if largest == 0 || d.PhysicalSize > largest {
+			largest = d.PhysicalSize
+		}
+		if smallest == 0 || d.PhysicalSize < smallest {
+			smallest = d.PhysicalSize
+		}
+	}
+	if smallest != largest {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf(
+			"disks mix %d and %d byte physical sectors", smallest, largest))
+	}
+	required := bits.Len64(largest) - 1
+
+	ashift := cfg.Ashift
+	if ashift == 0 {
+		if v, ok := cfg.Properties["ashift"]; ok {
+			parsed, err := strconv.Atoi(v)
+			if err != nil {
+				return 0, errors.New(errors.ZFSPoolLayout, "ashift must be a number")
+			}
+			ashift = parsed
+		}
+	}
+	if ashift == 0 {
+		return max(minAshift, required), nil
+	}
+
+	if ashift < 9 || ashift > maxAshift {
+		return 0, errors.New(errors.ZFSPoolLayout,
+			fmt.Sprintf("ashift must be between 9 and %d", maxAshift))
+	}
+	if ashift < required {
+		return 0, errors.New(errors.ZFSPoolLayout, fmt.Sprintf(
+			"ashift %d is below the %d byte physical sector size of the disks, use at least %d",
+			ashift, largest, required))
+	}
+	return ashift, nil
+}
+
+// readDiskInfo reads the size and physical sector size of a disk or
+// partition from sysfs, following /dev/disk/by-* links
+func readDiskInfo(path string) (PlanDisk, error) {
+	disk := PlanDisk{Path: path}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		resolved = path
+	}
+	dir := filepath.Join(sysBlockDir, filepath.Base(resolved))
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = real
+	}
+
+	sectors, err := readSysUint(filepath.Join(dir, "size"))
+	if err != nil {
+		return disk, errors.Wrap(err, errors.ZFSPoolLayout).
+			WithMetadata("disk", path)
+	}
+	disk.Size = sectors * sectorSize
+
+	// Partitions carry no queue attributes, their parent disk does
+	disk.PhysicalSize, err = readSysUint(filepath.Join(dir, "queue", "physical_block_size"))
+	if err != nil {
+		disk.PhysicalSize, err = readSysUint(
+			filepath.Join(filepath.Dir(dir), "queue", "physical_block_size"))
+		if err != nil {
+			disk.PhysicalSize = sectorSize
+		}
+	}
+
+	return disk, nil
+}
+
+func readSysUint(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
@@ -0,0 +1,219 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// fakeSysBlock creates sysfs entries for disks of the given sizes in bytes
+// and physical sector sizes, returning their /dev paths
+func fakeSysBlock(t *testing.T, sizes []uint64, sector uint64) []string {
+	t.Helper()
+	root := t.TempDir()
+	old := sysBlockDir
+	sysBlockDir = root
+	t.Cleanup(func() { sysBlockDir = old })
+
+	var paths []string
+	for i, size := range sizes {
+		name := "vd" + string(rune('a'+i))
+		dir := filepath.Join(root, name, "queue")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		write := func(path string, v uint64) {
+			if err := os.WriteFile(path, []byte(strconv.FormatUint(v, 10)+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		write(filepath.Join(root, name, "size"), size/sectorSize)
+		write(filepath.Join(dir, "physical_block_size"), sector)
+		paths = append(paths, "/dev/nonexistent-rodent-test/"+name)
+	}
+	return paths
+}
+
+const tib = uint64(1) << 40
+
+func TestPlanLayout(t *testing.T) {
+	tests := []struct {
+		name       string
+		sizes      []uint64
+		layout     LayoutConfig
+		wantVDevs  int
+		wantType   string
+		wantUsable uint64
+		wantErr    bool
+	}{
+		{
+			name:       "raidz2 width 6",
+			sizes:      []uint64{tib, tib, tib, tib, tib, tib, tib, tib, tib, tib, tib, tib},
+			layout:     LayoutConfig{Type: LayoutRaidz2, Width: 6},
+			wantVDevs:  2,
+			wantType:   "raidz2",
+			wantUsable: 8 * tib,
+		},
+		{
+			name:       "mirrors of 2",
+			sizes:      []uint64{tib, tib, tib, tib},
+			layout:     LayoutConfig{Type: LayoutMirror},
+			wantVDevs:  2,
+			wantType:   "mirror",
+			wantUsable: 2 * tib,
+		},
+		{
+			name:       "draid2",
+			sizes:      []uint64{tib, tib, tib, tib, tib, tib, tib, tib},
+			layout:     LayoutConfig{Type: LayoutDraid2, DraidData: 4, DraidSpares: 1},
+			wantVDevs:  1,
+			wantType:   "draid2:4d:1s:8c",
+			wantUsable: 7 * tib * 4 / 6,
+		},
+		{
+			name:    "uneven split",
+			sizes:   []uint64{tib, tib, tib, tib, tib},
+			layout:  LayoutConfig{Type: LayoutRaidz1, Width: 4},
+			wantErr: true,
+		},
+		{
+			name:    "raidz3 too narrow",
+			sizes:   []uint64{tib, tib, tib},
+			layout:  LayoutConfig{Type: LayoutRaidz3},
+			wantErr: true,
+		},
+		{
+			name:    "mixed sizes",
+			sizes:   []uint64{tib, tib, 2 * tib, 2 * tib},
+			layout:  LayoutConfig{Type: LayoutMirror},
+			wantErr: true,
+		},
+		{
+			name:    "unknown layout",
+			sizes:   []uint64{tib},
+			layout:  LayoutConfig{Type: "raid10"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disks := fakeSysBlock(t, tt.sizes, 4096)
+			plan, err := planLayout(PlanConfig{Name: "tank", Disks: disks, Layout: tt.layout})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(plan.Config.VDevSpec) != tt.wantVDevs {
+				t.Fatalf("expected %d vdevs, got %d", tt.wantVDevs, len(plan.Config.VDevSpec))
+			}
+			if plan.Config.VDevSpec[0].Type != tt.wantType {
+				t.Errorf("expected type %s, got %s", tt.wantType, plan.Config.VDevSpec[0].Type)
+			}
+			if want := tt.wantUsable - tt.wantUsable/32; plan.Usable != want {
+				t.Errorf("expected usable %d, got %d", want, plan.Usable)
+			}
+			if plan.Config.Properties["ashift"] != "12" {
+				t.Errorf("expected ashift 12, got %s", plan.Config.Properties["ashift"])
+			}
+		})
+	}
+}
+
+func TestPlanAshift(t *testing.T) {
+	disks := fakeSysBlock(t, []uint64{tib, tib}, 8192)
+
+	plan, err := planLayout(PlanConfig{Name: "tank", Disks: disks, Layout: LayoutConfig{Type: LayoutMirror}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Ashift != 13 {
+		t.Errorf("expected ashift 13 for 8K sectors, got %d", plan.Ashift)
+	}
+
+	_, err = planLayout(PlanConfig{
+		Name:   "tank",
+		Disks:  disks,
+		Layout: LayoutConfig{Type: LayoutMirror},
+		Ashift: 12,
+	})
+	if err == nil {
+		t.Error("expected ashift below sector size to be rejected")
+	}
+}
+
+func TestPlanMixedSizesAllowed(t *testing.T) {
+	disks := fakeSysBlock(t, []uint64{tib, 2 * tib}, 512)
+
+	plan, err := planLayout(PlanConfig{
+		Name:            "tank",
+		Disks:           disks,
+		Layout:          LayoutConfig{Type: LayoutMirror},
+		AllowMixedSizes: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan.Warnings) == 0 {
+		t.Error("expected a mixed size warning")
+	}
+	if want := tib - tib/32; plan.Usable != want {
+		t.Errorf("expected usable %d, got %d", want, plan.Usable)
+	}
+}
+
+func TestCreateArgs(t *testing.T) {
+	args, err := createArgs(CreateConfig{
+		Name:       "tank",
+		VDevSpec:   []VDevSpec{{Type: "mirror", Devices: []string{"/dev/vda", "/dev/vdb"}}},
+		Features:   map[string]bool{"encryption": false},
+		MountPoint: "/srv/tank",
+		AltRoot:    "/mnt",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		"-o", "feature@encryption=disabled", "-m", "/srv/tank", "-R", "/mnt",
+		"tank", "mirror", "/dev/vda", "/dev/vdb",
+	}
+	if len(args) != len(want) {
+		t.Fatalf("expected %v, got %v", want, args)
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, args)
+		}
+	}
+
+	if _, err := createArgs(CreateConfig{
+		Name:       "tank",
+		VDevSpec:   []VDevSpec{{Devices: []string{"/dev/vda"}}},
+		MountPoint: "relative",
+	}); err == nil {
+		t.Error("expected relative mountpoint to be rejected")
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/stratastor/rodent/pkg/errors"
@@ -30,10 +31,18 @@ import (
 // Manager manages ZFS pool operations
 type Manager struct {
 	executor *command.CommandExecutor
+	sessions *maintenanceStore
+	spares   *spareLog
+	history  *ScrubHistory
+	namer    DeviceNamer
 }
 
 func NewManager(executor *command.CommandExecutor) *Manager {
-	return &Manager{executor: executor}
+	return &Manager{
+		executor: executor,
+		sessions: newMaintenanceStore(),
+		spares:   newSpareLog(),
+	}
 }
 
 // buildVDevArgs converts VDevSpec to command arguments
@@ -53,6 +62,43 @@ func buildVDevArgs(specs []VDevSpec) []string {
 
 // Create creates a new ZFS pool
 func (p *Manager) Create(ctx context.Context, cfg CreateConfig) error {
+	if usesDRAID(cfg.VDevSpec) {
+		if err := p.executor.Require(ctx, command.FeatureDRAID); err != nil {
+			return err
+		}
+	}
+	cfg.VDevSpec = p.stableSpecs(cfg.VDevSpec)
+	args, err := createArgs(cfg)
+	if err != nil {
+		return err
+	}
+
+	opts := command.CommandOptions{
+		Flags: command.FlagForce,
+	}
+
+	out, err := p.executor.Execute(ctx, opts, "zpool create", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return errors.Wrap(err, errors.ZFSPoolCreate).
+				WithMetadata("output", string(out))
+		}
+		return errors.Wrap(err, errors.ZFSPoolCreate)
+	}
+
+	return nil
+}
+
+// createArgs builds the zpool create arguments, without the -n dry run and
+// -f flags
+func createArgs(cfg CreateConfig) ([]string, error) {
+	if cfg.Name == "" {
+		return nil, errors.New(errors.ZFSPoolCreate, "pool name is required")
+	}
+	if len(cfg.VDevSpec) == 0 {
+		return nil, errors.New(errors.ZFSPoolCreate, "no vdevs specified")
+	}
+
 	args := []string{}
 
 	// Add properties
@@ -62,57 +108,93 @@ func (p *Manager) Create(ctx context.Context, cfg CreateConfig) error {
 
 	// Add features
 	for feature, enabled := range cfg.Features {
+		state := "disabled"
 		if enabled {
-			args = append(args, "-o", fmt.Sprintf("feature@%s=enabled", feature))
+			state = "enabled"
 		}
+		args = append(args, "-o", fmt.Sprintf("feature@%s=%s", feature, state))
 	}
 
 	if cfg.MountPoint != "" {
+		if cfg.MountPoint != "none" && cfg.MountPoint != "legacy" &&
+			!filepath.IsAbs(cfg.MountPoint) {
+			return nil, errors.New(errors.ZFSPoolCreate,
+				"mountpoint must be an absolute path, none or legacy")
+		}
 		args = append(args, "-m", cfg.MountPoint)
 	}
 
+	if cfg.AltRoot != "" {
+		if !filepath.IsAbs(cfg.AltRoot) {
+			return nil, errors.New(errors.ZFSPoolCreate, "altroot must be an absolute path")
+		}
+		args = append(args, "-R", cfg.AltRoot)
+	}
+
 	// Add pool name and vdev specs
 	args = append(args, cfg.Name)
 	args = append(args, buildVDevArgs(cfg.VDevSpec)...)
 
-	opts := command.CommandOptions{
-		Flags: command.FlagForce, // if cfg.Force is true
-	}
-
-	out, err := p.executor.Execute(ctx, opts, "zpool create", args...)
-	if err != nil {
-		if len(out) > 0 {
-			return errors.Wrap(err, errors.ZFSPoolCreate).
-				WithMetadata("output", string(out))
-		}
-		return errors.Wrap(err, errors.ZFSPoolCreate)
-	}
-
-	return nil
+	return args, nil
 }
 
-// Import imports a ZFS pool
+// Import imports a ZFS pool by name or GUID
 func (p *Manager) Import(ctx context.Context, cfg ImportConfig) error {
+	target := cfg.Name
+	if cfg.GUID != "" {
+		target = cfg.GUID
+	}
+	if target == "" {
+		return errors.New(errors.ZFSPoolImport, "pool name or GUID is required")
+	}
+
 	args := []string{"import"}
 
 	if cfg.Force {
 		args = append(args, "-f")
 	}
 
-	if cfg.Dir != "" {
-		args = append(args, "-d", cfg.Dir)
+	if cfg.AllowDestroy {
+		args = append(args, "-D")
+	}
+
+	if cfg.MissingLog {
+		args = append(args, "-m")
+	}
+
+	args = append(args, searchPathArgs(cfg.Dir, cfg.Paths)...)
+
+	if cfg.RewindToCheckpoint {
+		args = append(args, "--rewind-to-checkpoint")
+	}
+
+	if cfg.AltRoot != "" {
+		if !filepath.IsAbs(cfg.AltRoot) {
+			return errors.New(errors.ZFSPoolImport, "altroot must be an absolute path")
+		}
+		args = append(args, "-R", cfg.AltRoot)
+	}
+
+	if cfg.CacheFile != "" {
+		if cfg.CacheFile != "none" && !filepath.IsAbs(cfg.CacheFile) {
+			return errors.New(errors.ZFSPoolImport,
+				"cachefile must be an absolute path or none")
+		}
+		args = append(args, "-o", "cachefile="+cfg.CacheFile)
+	}
+
+	if cfg.ReadOnly {
+		args = append(args, "-o", "readonly=on")
 	}
 
 	for k, v := range cfg.Properties {
 		args = append(args, "-o", fmt.Sprintf("%s=%s", k, v))
 	}
 
-	if cfg.Name != "" {
-		args = append(args, cfg.Name)
-	}
+	args = append(args, target)
 
-	if len(cfg.Paths) > 0 {
-		args = append(args, cfg.Paths...)
+	if cfg.NewName != "" {
+		args = append(args, cfg.NewName)
 	}
 
 	out, err := p.executor.Execute(ctx, command.CommandOptions{}, "zpool import", args...)
@@ -127,8 +209,29 @@ func (p *Manager) Import(ctx context.Context, cfg ImportConfig) error {
 	return nil
 }
 
+// searchPathArgs turns search directories and devices into -d arguments
+func searchPathArgs(dir string, paths []string) []string {
+	var args []string
+	if dir != "" {
+		args = append(args, "-d", dir)
+	}
+	for _, path := range paths {
+		args = append(args, "-d", path)
+	}
+	return args
+}
+
 // Status gets the status of a pool
 func (p *Manager) Status(ctx context.Context, name string) (PoolStatus, error) {
+	if !p.executor.SupportsJSON(ctx) {
+		status, err := p.statusText(ctx, name)
+		if err != nil {
+			return status, err
+		}
+		p.annotateDevices(&status)
+		return status, nil
+	}
+
 	args := []string{"status"}
 	if name != "" {
 		args = append(args, name)
@@ -153,10 +256,21 @@ func (p *Manager) Status(ctx context.Context, name string) (PoolStatus, error) {
 		return status, errors.Wrap(err, errors.CommandOutputParse)
 	}
 
+	for _, pool := range status.Pools {
+		if pool.RemovalStats != nil {
+			removalProgress(pool.RemovalStats)
+		}
+	}
+	p.annotateDevices(&status)
+
 	return status, nil
 }
 
 func (p *Manager) GetProperties(ctx context.Context, name string) (ListResult, error) {
+	if !p.executor.SupportsJSON(ctx) {
+		return p.getText(ctx, name, "all")
+	}
+
 	args := []string{"get", "all", "-H"}
 	if name != "" {
 		args = append(args, name)
@@ -186,6 +300,10 @@ func (p *Manager) GetProperties(ctx context.Context, name string) (ListResult, e
 
 // GetProperty gets a specific property of a pool
 func (p *Manager) GetProperty(ctx context.Context, name, property string) (ListResult, error) {
+	if !p.executor.SupportsJSON(ctx) {
+		return p.getText(ctx, name, property)
+	}
+
 	args := []string{"get", "-H", property}
 	if name != "" {
 		args = append(args, name)
@@ -283,11 +401,22 @@ func (p *Manager) Destroy(ctx context.Context, name string, force bool) error {
 	return nil
 }
 
-// Scrub starts/stops a scrub on a pool
-func (p *Manager) Scrub(ctx context.Context, name string, stop bool) error {
+// Scrub starts, stops or pauses a scrub on a pool. Starting a scrub on a
+// pool with a paused scrub resumes it.
+func (p *Manager) Scrub(ctx context.Context, name string, cfg ScrubConfig) error {
 	args := []string{"scrub"}
-	if stop {
+	switch cfg.Action {
+	case "", ScrubStart:
+	case ScrubStop:
 		args = append(args, "-s")
+	case ScrubPause:
+		args = append(args, "-p")
+	default:
+		return errors.New(errors.ServerRequestValidation,
+			fmt.Sprintf("invalid scrub action %q", cfg.Action))
+	}
+	if cfg.ErrorScrub {
+		args = append(args, "-e")
 	}
 	args = append(args, name)
 
@@ -304,6 +433,10 @@ func (p *Manager) Scrub(ctx context.Context, name string, stop bool) error {
 
 // List returns a list of all pools
 func (p *Manager) List(ctx context.Context) (ListResult, error) {
+	if !p.executor.SupportsJSON(ctx) {
+		return p.listText(ctx)
+	}
+
 	args := []string{"-H", "-p"}
 
 	opts := command.CommandOptions{
@@ -342,8 +475,14 @@ func (p *Manager) Resilver(ctx context.Context, name string) error {
 	return nil
 }
 
-func (p *Manager) AttachDevice(ctx context.Context, pool, device, newDevice string) error {
-	args := []string{"attach", pool, device, newDevice}
+// AttachDevice attaches newDevice to device. Force overwrites a ZFS label or
+// other signature on the new device.
+func (p *Manager) AttachDevice(ctx context.Context, pool, device, newDevice string, force bool) error {
+	args := []string{"attach"}
+	if force {
+		args = append(args, "-f")
+	}
+	args = append(args, pool, device, p.stablePath(newDevice))
 
 	out, err := p.executor.Execute(ctx, command.CommandOptions{}, "zpool attach", args...)
 	if err != nil {
@@ -370,8 +509,18 @@ func (p *Manager) DetachDevice(ctx context.Context, pool, device string) error {
 	return nil
 }
 
-func (p *Manager) ReplaceDevice(ctx context.Context, pool, oldDevice, newDevice string) error {
-	args := []string{"replace", pool, oldDevice, newDevice}
+// ReplaceDevice replaces oldDevice with newDevice, or with itself when
+// newDevice is empty. Force overwrites a ZFS label or other signature on the
+// new device.
+func (p *Manager) ReplaceDevice(ctx context.Context, pool, oldDevice, newDevice string, force bool) error {
+	args := []string{"replace"}
+	if force {
+		args = append(args, "-f")
+	}
+	args = append(args, pool, oldDevice)
+	if newDevice != "" {
+		args = append(args, p.stablePath(newDevice))
+	}
 
 	out, err := p.executor.Execute(ctx, command.CommandOptions{}, "zpool replace", args...)
 	if err != nil {
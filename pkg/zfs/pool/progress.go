@@ -0,0 +1,261 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/zfs/command"
+)
+
+// longOperationTimeout bounds initialize/trim runs started with wait set
+const longOperationTimeout = 12 * time.Hour
+
+var (
+	trimRateRegex = regexp.MustCompile(`^\d+[KMGTP]?$`)
+
+	// Matches "(14% initialized, started at Tue Jan 21 10:00:00 2025)" and
+	// the trimmed equivalent, as printed by zpool status -i -t
+	vdevProgressRegex = regexp.MustCompile(
+		`\((\d+)% (initialized|trimmed)(?:, (suspended, started at|started at|completed at) ([^)]+))?\)`,
+	)
+	// Matches the states printed without a percentage
+	vdevProgressStateRegex = regexp.MustCompile(
+		`\((uninitialized|untrimmed|trim unsupported|initializing|trimming)\)`,
+	)
+)
+
+// Initialize starts, suspends or cancels initialization of the pool's vdevs,
+// or uninitializes them
+func (p *Manager) Initialize(ctx context.Context, name string, cfg InitializeConfig) error {
+	args := []string{"initialize"}
+	switch cfg.Action {
+	case "", "start":
+	case "suspend":
+		args = append(args, "-s")
+	case "cancel":
+		args = append(args, "-c")
+	case "uninit":
+		args = append(args, "-u")
+	default:
+		return errors.New(errors.ServerRequestValidation,
+			fmt.Sprintf("invalid initialize action %q", cfg.Action))
+	}
+
+	opts := command.CommandOptions{}
+	if cfg.Wait {
+		args = append(args, "-w")
+		opts.Timeout = longOperationTimeout
+	}
+	args = append(args, name)
+	args = append(args, cfg.Devices...)
+
+	out, err := p.executor.Execute(ctx, opts, "zpool initialize", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return errors.Wrap(err, errors.ZFSPoolInitialize).
+				WithMetadata("output", string(out))
+		}
+		return errors.Wrap(err, errors.ZFSPoolInitialize)
+	}
+	return nil
+}
+
+// Trim starts, suspends or cancels TRIM of the pool's vdevs
+func (p *Manager) Trim(ctx context.Context, name string, cfg TrimConfig) error {
+	args := []string{"trim"}
+	switch cfg.Action {
+	case "", "start":
+		if cfg.Secure {
+			args = append(args, "-d")
+		}
+		if cfg.Rate != "" {
+			if !trimRateRegex.MatchString(cfg.Rate) {
+				return errors.New(errors.ServerRequestValidation,
+					fmt.Sprintf("invalid trim rate %q", cfg.Rate))
+			}
+			args = append(args, "-r", cfg.Rate)
+		}
+	case "suspend":
+		args = append(args, "-s")
+	case "cancel":
+		args = append(args, "-c")
+	default:
+		return errors.New(errors.ServerRequestValidation,
+			fmt.Sprintf("invalid trim action %q", cfg.Action))
+	}
+
+	opts := command.CommandOptions{}
+	if cfg.Wait {
+		args = append(args, "-w")
+		opts.Timeout = longOperationTimeout
+	}
+	args = append(args, name)
+	args = append(args, cfg.Devices...)
+
+	out, err := p.executor.Execute(ctx, opts, "zpool trim", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return errors.Wrap(err, errors.ZFSPoolTrim).
+				WithMetadata("output", string(out))
+		}
+		return errors.Wrap(err, errors.ZFSPoolTrim)
+	}
+	return nil
+}
+
+// StatusWithProgress returns the pool status with the initialize and TRIM
+// progress of each leaf vdev filled in
+func (p *Manager) StatusWithProgress(ctx context.Context, name string) (PoolStatus, error) {
+	status, err := p.Status(ctx, name)
+	if err != nil {
+		return status, err
+	}
+
+	args := []string{"status", "-i", "-t"}
+	if name != "" {
+		args = append(args, name)
+	}
+
+	out, err := p.executor.Execute(ctx, command.CommandOptions{}, "zpool status", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return status, errors.Wrap(err, errors.ZFSPoolStatus).
+				WithMetadata("output", string(out))
+		}
+		return status, errors.Wrap(err, errors.ZFSPoolStatus)
+	}
+
+	progress := parseVDevProgress(string(out))
+	for poolName, pool := range status.Pools {
+		vdevs, ok := progress[poolName]
+		if !ok {
+			continue
+		}
+		applyPoolProgress(pool, vdevs)
+	}
+
+	return status, nil
+}
+
+// vdevOps holds the parsed progress of a single vdev
+type vdevOps struct {
+	initialize *VDevProgress
+	trim       *VDevProgress
+}
+
+// parseVDevProgress parses the config section of zpool status -i -t text
+// output into per-pool maps of vdev name to progress
+func parseVDevProgress(out string) map[string]map[string]vdevOps {
+	result := make(map[string]map[string]vdevOps)
+
+	var pool string
+	inConfig := false
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "pool:") {
+			pool = strings.TrimSpace(strings.TrimPrefix(trimmed, "pool:"))
+			result[pool] = make(map[string]vdevOps)
+			inConfig = false
+			continue
+		}
+		if strings.HasPrefix(trimmed, "NAME") && strings.Contains(trimmed, "STATE") {
+			inConfig = true
+			continue
+		}
+		if !inConfig || pool == "" {
+			continue
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "errors:") {
+			inConfig = false
+			continue
+		}
+
+		fields := strings.Fields(trimmed)
+		ops := vdevOps{}
+		for _, m := range vdevProgressRegex.FindAllStringSubmatch(trimmed, -1) {
+			pct, _ := strconv.Atoi(m[1])
+			vp := &VDevProgress{Percent: pct, Time: strings.TrimSpace(m[4])}
+			switch m[3] {
+			case "suspended, started at":
+				vp.State = "suspended"
+			case "completed at":
+				vp.State = "completed"
+			default:
+				vp.State = "active"
+			}
+			if m[2] == "initialized" {
+				ops.initialize = vp
+			} else {
+				ops.trim = vp
+			}
+		}
+		for _, m := range vdevProgressStateRegex.FindAllStringSubmatch(trimmed, -1) {
+			switch m[1] {
+			case "uninitialized":
+				ops.initialize = &VDevProgress{State: "none"}
+			case "initializing":
+				ops.initialize = &VDevProgress{State: "active"}
+			case "untrimmed":
+				ops.trim = &VDevProgress{State: "none"}
+			case "trimming":
+				ops.trim = &VDevProgress{State: "active"}
+			case "trim unsupported":
+				ops.trim = &VDevProgress{State: "unsupported"}
+			}
+		}
+		if ops.initialize != nil || ops.trim != nil {
+			result[pool][fields[0]] = ops
+		}
+	}
+
+	return result
+}
+
+// applyPoolProgress fills in the progress of the data vdevs and of the log,
+// cache, special and dedup vdevs of a pool. Spares can't be initialized or
+// trimmed.
+func applyPoolProgress(pool Pool, progress map[string]vdevOps) {
+	for _, vdevs := range []map[string]*VDev{
+		pool.VDevs, pool.Logs, pool.L2Cache, pool.Special, pool.Dedup,
+	} {
+		for _, v := range vdevs {
+			applyVDevProgress(v, progress)
+		}
+	}
+}
+
+func applyVDevProgress(v *VDev, progress map[string]vdevOps) {
+	if ops, ok := progress[v.Name]; ok {
+		v.Initialize = ops.initialize
+		v.Trim = ops.trim
+	}
+	for _, child := range v.VDevs {
+		applyVDevProgress(child, progress)
+	}
+}
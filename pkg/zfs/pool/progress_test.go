@@ -0,0 +1,113 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"reflect"
+	"testing"
+)
+
+const statusInitTrimOutput = `  pool: tank
+ state: ONLINE
+config:
+
+	NAME        STATE     READ WRITE CKSUM
+	tank        ONLINE       0     0     0
+	  mirror-0  ONLINE       0     0     0
+	    sda     ONLINE       0     0     0  (14% initialized, started at Tue Jan 21 10:00:00 2025)  (100% trimmed, completed at Tue Jan 21 10:05:00 2025)
+	    sdb     ONLINE       0     0     0  (40% initialized, suspended, started at Tue Jan 21 10:00:00 2025)  (untrimmed)
+	  loop0     ONLINE       0     0     0  (uninitialized)  (trim unsupported)
+
+errors: No known data errors
+`
+
+func TestParseVDevProgress(t *testing.T) {
+	got := parseVDevProgress(statusInitTrimOutput)
+
+	want := map[string]map[string]vdevOps{
+		"tank": {
+			"sda": {
+				initialize: &VDevProgress{State: "active", Percent: 14, Time: "Tue Jan 21 10:00:00 2025"},
+				trim:       &VDevProgress{State: "completed", Percent: 100, Time: "Tue Jan 21 10:05:00 2025"},
+			},
+			"sdb": {
+				initialize: &VDevProgress{State: "suspended", Percent: 40, Time: "Tue Jan 21 10:00:00 2025"},
+				trim:       &VDevProgress{State: "none"},
+			},
+			"loop0": {
+				initialize: &VDevProgress{State: "none"},
+				trim:       &VDevProgress{State: "unsupported"},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseVDevProgress() = %+v, want %+v", got, want)
+	}
+}
+
+const statusClassesProgressOutput = `  pool: tank
+ state: ONLINE
+config:
+
+	NAME        STATE     READ WRITE CKSUM
+	tank        ONLINE       0     0     0
+	  sda       ONLINE       0     0     0  (100% initialized, completed at Tue Jan 21 10:00:00 2025)
+	special
+	  sdb       ONLINE       0     0     0  (5% trimmed, started at Tue Jan 21 10:00:00 2025)
+	logs
+	  sdc       ONLINE       0     0     0  (untrimmed)
+	cache
+	  nvme0n1   ONLINE       0     0     0  (20% initialized, started at Tue Jan 21 10:00:00 2025)
+	spares
+	  sdd       AVAIL
+
+errors: No known data errors
+`
+
+func TestApplyPoolProgress(t *testing.T) {
+	leaf := func(name string) *VDev { return &VDev{Name: name, VDevType: "disk"} }
+	pool := Pool{
+		Name:    "tank",
+		VDevs:   map[string]*VDev{"tank": {Name: "tank", VDevType: "root", VDevs: map[string]*VDev{"sda": leaf("sda")}}},
+		Special: map[string]*VDev{"sdb": leaf("sdb")},
+		Logs:    map[string]*VDev{"sdc": leaf("sdc")},
+		L2Cache: map[string]*VDev{"nvme0n1": leaf("nvme0n1")},
+		Spares:  map[string]*VDev{"sdd": leaf("sdd")},
+	}
+
+	applyPoolProgress(pool, parseVDevProgress(statusClassesProgressOutput)["tank"])
+
+	tests := []struct {
+		vdev       *VDev
+		initialize *VDevProgress
+		trim       *VDevProgress
+	}{
+		{pool.VDevs["tank"].VDevs["sda"], &VDevProgress{State: "completed", Percent: 100, Time: "Tue Jan 21 10:00:00 2025"}, nil},
+		{pool.Special["sdb"], nil, &VDevProgress{State: "active", Percent: 5, Time: "Tue Jan 21 10:00:00 2025"}},
+		{pool.Logs["sdc"], nil, &VDevProgress{State: "none"}},
+		{pool.L2Cache["nvme0n1"], &VDevProgress{State: "active", Percent: 20, Time: "Tue Jan 21 10:00:00 2025"}, nil},
+		{pool.Spares["sdd"], nil, nil},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.vdev.Initialize, tt.initialize) || !reflect.DeepEqual(tt.vdev.Trim, tt.trim) {
+			t.Errorf("%s: initialize %+v trim %+v, want %+v and %+v",
+				tt.vdev.Name, tt.vdev.Initialize, tt.vdev.Trim, tt.initialize, tt.trim)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+// maxScrubRecords caps the number of scrubs kept in the history file
+const maxScrubRecords = 1000
+
+// ScrubHistory is a file backed store of completed scrubs
+type ScrubHistory struct {
+	mu      sync.Mutex
+	path    string
+	records []ScrubRecord
+}
+
+// NewScrubHistory opens the scrub history stored at path, creating an empty
+// one if the file doesn't exist yet
+func NewScrubHistory(path string) (*ScrubHistory, error) {
+	h := &ScrubHistory{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return nil, errors.Wrap(err, errors.ZFSPoolScrubHistory).
+			WithMetadata("path", path)
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &h.records); err != nil {
+			return nil, errors.Wrap(err, errors.ZFSPoolScrubHistory).
+				WithMetadata("path", path)
+		}
+	}
+	return h, nil
+}
+
+// Add records a completed scrub. A scrub that is already recorded, matched
+// by pool and end time, is ignored. The end time is used because zpool
+// status without -p doesn't report when a canceled scrub started.
+func (h *ScrubHistory) Add(r ScrubRecord) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, existing := range h.records {
+		if existing.Pool == r.Pool && existing.EndTime == r.EndTime {
+			return nil
+		}
+	}
+
+	h.records = append(h.records, r)
+	if len(h.records) > maxScrubRecords {
+		h.records = h.records[len(h.records)-maxScrubRecords:]
+	}
+	return h.save()
+}
+
+// List returns the recorded scrubs of a pool, newest first. A limit of zero
+// returns all of them.
+func (h *ScrubHistory) List(pool string, limit int) []ScrubRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	records := []ScrubRecord{}
+	for i := len(h.records) - 1; i >= 0; i-- {
+		if h.records[i].Pool != pool {
+			continue
+		}
+		records = append(records, h.records[i])
+		if limit > 0 && len(records) == limit {
+			break
+		}
+	}
+	return records
+}
+
+// Last returns the most recent recorded scrub of a pool
+func (h *ScrubHistory) Last(pool string) (ScrubRecord, bool) {
+	records := h.List(pool, 1)
+	if len(records) == 0 {
+		return ScrubRecord{}, false
+	}
+	return records[0], true
+}
+
+// save writes the history atomically. Callers must hold h.mu.
+func (h *ScrubHistory) save() error {
+	data, err := json.MarshalIndent(h.records, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, errors.ZFSPoolScrubHistory)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return errors.Wrap(err, errors.ZFSPoolScrubHistory).
+			WithMetadata("path", h.path)
+	}
+
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return errors.Wrap(err, errors.ZFSPoolScrubHistory).
+			WithMetadata("path", h.path)
+	}
+	if err := os.Rename(tmp, h.path); err != nil {
+		return errors.Wrap(err, errors.ZFSPoolScrubHistory).
+			WithMetadata("path", h.path)
+	}
+	return nil
+}
+
+// scrubRecordFromStats builds a history record from a completed scan. It
+// returns false if the scan is not a finished or canceled scrub.
+func scrubRecordFromStats(pool string, stats *ScanStats) (ScrubRecord, bool) {
+	if stats == nil {
+		return ScrubRecord{}, false
+	}
+	if stats.Function != "SCRUB" && stats.Function != "ERRORSCRUB" {
+		return ScrubRecord{}, false
+	}
+	if stats.State != "FINISHED" && stats.State != "CANCELED" {
+		return ScrubRecord{}, false
+	}
+
+	r := ScrubRecord{
+		Pool:      pool,
+		Function:  stats.Function,
+		State:     stats.State,
+		StartTime: stats.StartTime,
+		EndTime:   stats.EndTime,
+		Examined:  stats.Examined,
+		Repaired:  stats.Processed,
+		Errors:    stats.Errors,
+	}
+
+	start, okStart := parseScanTime(stats.StartTime)
+	end, okEnd := parseScanTime(stats.EndTime)
+	if okStart && okEnd && !end.Before(start) {
+		r.Duration = int64(end.Sub(start).Seconds())
+	}
+	return r, true
+}
+
+// parseScanTime parses scan times, which are either unix seconds (with -p)
+// or in ctime format
+func parseScanTime(s string) (time.Time, bool) {
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0), true
+	}
+	t, err := time.ParseInLocation(time.ANSIC, s, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,288 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stratastor/logger"
+	"github.com/stratastor/rodent/pkg/zfs/command"
+)
+
+// ScrubSchedule sets the scrub cadence of a pool
+type ScrubSchedule struct {
+	Pool    string
+	Cadence time.Duration
+}
+
+// ScrubSchedulerConfig configures the scrub scheduler
+type ScrubSchedulerConfig struct {
+	// Interval between checks of pool scan state
+	Interval time.Duration
+	// MaxConcurrent caps the scrubs running across all pools
+	MaxConcurrent int
+	// QuietHours, if set, is a window in which scheduled scrubs are paused
+	QuietHours *QuietHours
+	Schedules  []ScrubSchedule
+}
+
+// QuietHours is a daily local time window. Start and End are minutes since
+// midnight; a window with End before Start spans midnight.
+type QuietHours struct {
+	Start int
+	End   int
+}
+
+// ParseQuietHours parses a window such as "22:00-06:00"
+func ParseQuietHours(s string) (*QuietHours, error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return nil, fmt.Errorf("invalid quiet hours %q, expected HH:MM-HH:MM", s)
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return nil, err
+	}
+	return &QuietHours{Start: start, End: end}, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Contains reports whether t falls inside the window
+func (q *QuietHours) Contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	if q.Start <= q.End {
+		return m >= q.Start && m < q.End
+	}
+	return m >= q.Start || m < q.End
+}
+
+// ParseCadence parses a scrub cadence. Besides Go durations it accepts whole
+// days and weeks, e.g. "30d" or "2w".
+func ParseCadence(s string) (time.Duration, error) {
+	var unit time.Duration
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	default:
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return 0, fmt.Errorf("invalid cadence %q", s)
+		}
+		return d, nil
+	}
+
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid cadence %q", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// SetScrubHistory sets the store completed scrubs are recorded in
+func (p *Manager) SetScrubHistory(h *ScrubHistory) {
+	p.history = h
+}
+
+// ScrubHistory returns the recorded scrubs of a pool, newest first
+func (p *Manager) ScrubHistory(pool string, limit int) []ScrubRecord {
+	if p.history == nil {
+		return []ScrubRecord{}
+	}
+	return p.history.List(pool, limit)
+}
+
+// scrubScheduler tracks the scrubs it started so that only those are paused
+// and resumed around quiet hours
+type scrubScheduler struct {
+	manager *Manager
+	cfg     ScrubSchedulerConfig
+	logger  logger.Logger
+
+	mu      sync.Mutex
+	started map[string]bool
+	paused  map[string]bool
+}
+
+// StartScrubScheduler records completed scrubs in the scrub history and
+// starts scheduled scrubs, every cfg.Interval until ctx is done
+func (p *Manager) StartScrubScheduler(ctx context.Context, cfg ScrubSchedulerConfig, l logger.Logger) {
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = 1
+	}
+	s := &scrubScheduler{
+		manager: p,
+		cfg:     cfg,
+		logger:  l,
+		started: make(map[string]bool),
+		paused:  make(map[string]bool),
+	}
+
+	ctx = command.WithPriority(ctx, command.PriorityBackground)
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.run(ctx, now)
+			}
+		}
+	}()
+}
+
+func (s *scrubScheduler) run(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status, err := s.manager.Status(ctx, "")
+	if err != nil {
+		s.logger.Debug("Failed to get pool status for scrub scheduler", "error", err)
+		return
+	}
+
+	if s.manager.history != nil {
+		for name, pool := range status.Pools {
+			if r, ok := scrubRecordFromStats(name, pool.ScanStats); ok {
+				if err := s.manager.history.Add(r); err != nil {
+					s.logger.Warn("Failed to record scrub", "pool", name, "error", err)
+				}
+			}
+		}
+	}
+
+	quiet := s.cfg.QuietHours != nil && s.cfg.QuietHours.Contains(now)
+
+	// Pause our scrubs for quiet hours and resume them afterwards
+	for name := range s.started {
+		pool, ok := status.Pools[name]
+		if !ok || !scrubInProgress(pool.ScanStats) {
+			delete(s.started, name)
+			delete(s.paused, name)
+			continue
+		}
+		switch {
+		case quiet && !s.paused[name]:
+			if err := s.manager.Scrub(ctx, name, ScrubConfig{Action: ScrubPause}); err != nil {
+				s.logger.Warn("Failed to pause scrub for quiet hours", "pool", name, "error", err)
+				continue
+			}
+			s.paused[name] = true
+		case !quiet && s.paused[name]:
+			if err := s.manager.Scrub(ctx, name, ScrubConfig{Action: ScrubStart}); err != nil {
+				s.logger.Warn("Failed to resume scrub", "pool", name, "error", err)
+				continue
+			}
+			delete(s.paused, name)
+		}
+	}
+
+	if quiet {
+		return
+	}
+
+	running := 0
+	for _, pool := range status.Pools {
+		if scrubInProgress(pool.ScanStats) && !scrubPaused(pool.ScanStats) {
+			running++
+		}
+	}
+
+	schedules := append([]ScrubSchedule(nil), s.cfg.Schedules...)
+	sort.Slice(schedules, func(i, j int) bool { return schedules[i].Pool < schedules[j].Pool })
+
+	for _, sched := range schedules {
+		if running >= s.cfg.MaxConcurrent {
+			return
+		}
+		pool, ok := status.Pools[sched.Pool]
+		if !ok || scanInProgress(pool.ScanStats) {
+			continue
+		}
+
+		last, ok := s.lastScrubEnd(sched.Pool, pool.ScanStats)
+		if ok && now.Sub(last) < sched.Cadence {
+			continue
+		}
+
+		if err := s.manager.Scrub(ctx, sched.Pool, ScrubConfig{Action: ScrubStart}); err != nil {
+			s.logger.Warn("Failed to start scheduled scrub", "pool", sched.Pool, "error", err)
+			continue
+		}
+		s.logger.Info("Started scheduled scrub", "pool", sched.Pool)
+		s.started[sched.Pool] = true
+		running++
+	}
+}
+
+// lastScrubEnd returns when the pool was last scrubbed, from the history or
+// the pool's current scan stats
+func (s *scrubScheduler) lastScrubEnd(pool string, stats *ScanStats) (time.Time, bool) {
+	var last time.Time
+	found := false
+
+	if s.manager.history != nil {
+		if r, ok := s.manager.history.Last(pool); ok {
+			last, found = parseScanTime(r.EndTime)
+		}
+	}
+	if r, ok := scrubRecordFromStats(pool, stats); ok {
+		if t, ok := parseScanTime(r.EndTime); ok && (!found || t.After(last)) {
+			last, found = t, true
+		}
+	}
+	return last, found
+}
+
+func scanInProgress(stats *ScanStats) bool {
+	return stats != nil && stats.State == "SCANNING"
+}
+
+func scrubInProgress(stats *ScanStats) bool {
+	return scanInProgress(stats) &&
+		(stats.Function == "SCRUB" || stats.Function == "ERRORSCRUB")
+}
+
+func scrubPaused(stats *ScanStats) bool {
+	switch stats.ScrubPause {
+	case "", "0", "-":
+		return false
+	}
+	return true
+}
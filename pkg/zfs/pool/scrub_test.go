@@ -0,0 +1,170 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestQuietHours(t *testing.T) {
+	tests := []struct {
+		window string
+		clock  string
+		want   bool
+	}{
+		{"08:00-18:00", "12:30", true},
+		{"08:00-18:00", "18:00", false},
+		{"08:00-18:00", "07:59", false},
+		{"22:00-06:00", "23:15", true},
+		{"22:00-06:00", "05:59", true},
+		{"22:00-06:00", "12:00", false},
+	}
+
+	for _, tt := range tests {
+		q, err := ParseQuietHours(tt.window)
+		if err != nil {
+			t.Fatalf("ParseQuietHours(%q) error = %v", tt.window, err)
+		}
+		at, _ := time.Parse("15:04", tt.clock)
+		if got := q.Contains(at); got != tt.want {
+			t.Errorf("%s contains %s = %v, want %v", tt.window, tt.clock, got, tt.want)
+		}
+	}
+
+	if _, err := ParseQuietHours("08:00"); err == nil {
+		t.Error("expected error for window without end")
+	}
+}
+
+func TestParseCadence(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "30d", want: 30 * 24 * time.Hour},
+		{in: "2w", want: 14 * 24 * time.Hour},
+		{in: "36h", want: 36 * time.Hour},
+		{in: "0d", wantErr: true},
+		{in: "weekly", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseCadence(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCadence(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseCadence(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestScrubHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scrub_history.json")
+
+	h, err := NewScrubHistory(path)
+	if err != nil {
+		t.Fatalf("NewScrubHistory() error = %v", err)
+	}
+
+	stats := &ScanStats{
+		Function:  "SCRUB",
+		State:     "FINISHED",
+		StartTime: "Sun Jan 12 00:00:00 2025",
+		EndTime:   "Sun Jan 12 01:00:00 2025",
+		Processed: "0",
+		Errors:    "0",
+	}
+	r, ok := scrubRecordFromStats("tank", stats)
+	if !ok {
+		t.Fatal("expected a record for a finished scrub")
+	}
+	if r.Duration != 3600 {
+		t.Errorf("Duration = %d, want 3600", r.Duration)
+	}
+
+	// The same scrub is seen on every poll until the next one starts
+	for i := 0; i < 3; i++ {
+		if err := h.Add(r); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	reopened, err := NewScrubHistory(path)
+	if err != nil {
+		t.Fatalf("NewScrubHistory() reopen error = %v", err)
+	}
+	if got := reopened.List("tank", 0); len(got) != 1 {
+		t.Errorf("List() returned %d records, want 1", len(got))
+	}
+
+	if _, ok := scrubRecordFromStats("tank", &ScanStats{Function: "SCRUB", State: "SCANNING"}); ok {
+		t.Error("running scrub must not be recorded")
+	}
+	if _, ok := scrubRecordFromStats("tank", &ScanStats{Function: "RESILVER", State: "FINISHED"}); ok {
+		t.Error("resilver must not be recorded")
+	}
+}
+
+// Two consecutive scrubs read from zpool status text, each seen on several
+// polls, must be recorded once each with their start times and durations.
+// The dates avoid the usual daylight saving changes.
+func TestScrubHistoryFromText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scrub_history.json")
+	h, err := NewScrubHistory(path)
+	if err != nil {
+		t.Fatalf("NewScrubHistory() error = %v", err)
+	}
+
+	for _, fixture := range []string{"scrub1.txt", "scrub1.txt", "scrub2.txt", "scrub2.txt"} {
+		status := parseStatusText(readFixture(t, fixture))
+		r, ok := scrubRecordFromStats("tank", status.Pools["tank"].ScanStats)
+		if !ok {
+			t.Fatalf("%s: expected a scrub record", fixture)
+		}
+		if err := h.Add(r); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+
+	golden := filepath.Join("testdata", "scrub_history.json.golden")
+	if *update {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("history differs from %s; run go test -update to regenerate\n%s", golden, got)
+	}
+}
@@ -0,0 +1,220 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/stratastor/logger"
+	"github.com/stratastor/rodent/pkg/zfs/command"
+)
+
+const (
+	// maxSpareActions caps the in-memory log of auto-replace actions
+	maxSpareActions = 256
+	// spareRetryPolls is how many polls to wait before retrying a failed
+	// replacement of the same device
+	spareRetryPolls = 10
+)
+
+// SpareAction records an automatic hot spare replacement
+type SpareAction struct {
+	Time    time.Time `json:"time"`
+	Pool    string    `json:"pool"`
+	Device  string    `json:"device"`
+	GUID    string    `json:"guid,omitempty"`
+	State   string    `json:"state"`
+	Spare   string    `json:"spare,omitempty"`
+	Success bool      `json:"success"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// spareLog keeps the recent auto-replace actions and the last attempt per
+// device so failing replacements aren't retried on every poll
+type spareLog struct {
+	mu       sync.Mutex
+	actions  []SpareAction
+	attempts map[string]time.Time
+}
+
+func newSpareLog() *spareLog {
+	return &spareLog{attempts: make(map[string]time.Time)}
+}
+
+func (l *spareLog) record(a SpareAction) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.actions = append(l.actions, a)
+	if len(l.actions) > maxSpareActions {
+		l.actions = l.actions[len(l.actions)-maxSpareActions:]
+	}
+	l.attempts[a.Pool+"/"+a.GUID+a.Device] = a.Time
+}
+
+func (l *spareLog) recentlyAttempted(pool string, v faultedVDev, since time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	t, ok := l.attempts[pool+"/"+v.guid+v.name]
+	return ok && t.After(since)
+}
+
+// SpareActions returns the recorded auto-replace actions for a pool, or for
+// all pools if pool is empty, oldest first
+func (p *Manager) SpareActions(pool string) []SpareAction {
+	p.spares.mu.Lock()
+	defer p.spares.mu.Unlock()
+
+	actions := []SpareAction{}
+	for _, a := range p.spares.actions {
+		if pool == "" || a.Pool == pool {
+			actions = append(actions, a)
+		}
+	}
+	return actions
+}
+
+// StartSpareMonitor polls pool status every interval and replaces FAULTED
+// devices with an available hot spare of the same pool. It returns
+// immediately; polling stops when ctx is done.
+func (p *Manager) StartSpareMonitor(ctx context.Context, interval time.Duration, l logger.Logger) {
+	ctx = command.WithPriority(ctx, command.PriorityBackground)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.replaceFaulted(ctx, interval, l)
+			}
+		}
+	}()
+}
+
+// replaceFaulted runs a single auto-replace pass over all pools
+func (p *Manager) replaceFaulted(ctx context.Context, interval time.Duration, l logger.Logger) {
+	status, err := p.Status(ctx, "")
+	if err != nil {
+		l.Warn("Failed to get pool status for spare monitor", "error", err)
+		return
+	}
+
+	retryAfter := time.Now().Add(-spareRetryPolls * interval)
+	for name, pool := range status.Pools {
+		spares := availableSpares(pool)
+		for _, v := range findFaulted(pool) {
+			if p.spares.recentlyAttempted(name, v, retryAfter) {
+				continue
+			}
+
+			action := SpareAction{
+				Time:   time.Now().UTC(),
+				Pool:   name,
+				Device: v.name,
+				GUID:   v.guid,
+				State:  v.state,
+			}
+
+			if len(spares) == 0 {
+				action.Error = "no available hot spare"
+				p.spares.record(action)
+				l.Warn("Faulted device has no available hot spare",
+					"pool", name, "device", v.name)
+				continue
+			}
+
+			action.Spare, spares = spares[0], spares[1:]
+			target := v.name
+			if v.guid != "" {
+				target = v.guid
+			}
+			// Polling is housekeeping, but restoring redundancy mustn't queue
+			// behind other background work
+			replaceCtx := command.WithPriority(ctx, command.PriorityMutating)
+			if err := p.ReplaceDevice(replaceCtx, name, target, action.Spare, false); err != nil {
+				action.Error = err.Error()
+				l.Error("Failed to replace faulted device with hot spare",
+					"pool", name, "device", v.name, "spare", action.Spare, "error", err)
+			} else {
+				action.Success = true
+				l.Info("Replaced faulted device with hot spare",
+					"pool", name, "device", v.name, "spare", action.Spare)
+			}
+			p.spares.record(action)
+		}
+	}
+}
+
+type faultedVDev struct {
+	name  string
+	guid  string
+	state string
+}
+
+// findFaulted returns the FAULTED leaf vdevs of the pool that aren't already
+// being replaced or covered by a spare
+func findFaulted(pool Pool) []faultedVDev {
+	var faulted []faultedVDev
+
+	var walk func(v *VDev, parentType string)
+	walk = func(v *VDev, parentType string) {
+		if len(v.VDevs) == 0 {
+			if v.State == "FAULTED" && parentType != "spare" && parentType != "replacing" {
+				name := v.Path
+				if name == "" {
+					name = v.Name
+				}
+				faulted = append(faulted, faultedVDev{name: name, guid: v.GUID, state: v.State})
+			}
+			return
+		}
+		for _, child := range v.VDevs {
+			walk(child, v.VDevType)
+		}
+	}
+
+	for _, v := range pool.VDevs {
+		walk(v, "")
+	}
+
+	sort.Slice(faulted, func(i, j int) bool { return faulted[i].name < faulted[j].name })
+	return faulted
+}
+
+// availableSpares returns the hot spares of the pool that are not in use
+func availableSpares(pool Pool) []string {
+	var spares []string
+	for name, v := range pool.Spares {
+		if v.State != "AVAIL" {
+			continue
+		}
+		if v.Path != "" {
+			name = v.Path
+		}
+		spares = append(spares, name)
+	}
+	sort.Strings(spares)
+	return spares
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindFaulted(t *testing.T) {
+	pool := Pool{
+		Name: "tank",
+		VDevs: map[string]*VDev{
+			"tank": {
+				Name:     "tank",
+				VDevType: "root",
+				VDevs: map[string]*VDev{
+					"mirror-0": {
+						Name:     "mirror-0",
+						VDevType: "mirror",
+						State:    "DEGRADED",
+						VDevs: map[string]*VDev{
+							"loop0": {Name: "loop0", Path: "/dev/loop0", GUID: "1", State: "FAULTED"},
+							"loop1": {Name: "loop1", Path: "/dev/loop1", GUID: "2", State: "ONLINE"},
+						},
+					},
+					"mirror-1": {
+						Name:     "mirror-1",
+						VDevType: "mirror",
+						State:    "DEGRADED",
+						VDevs: map[string]*VDev{
+							"spare-0": {
+								Name:     "spare-0",
+								VDevType: "spare",
+								VDevs: map[string]*VDev{
+									"loop2": {Name: "loop2", Path: "/dev/loop2", GUID: "3", State: "FAULTED"},
+									"loop5": {Name: "loop5", Path: "/dev/loop5", GUID: "6", State: "ONLINE"},
+								},
+							},
+							"loop3": {Name: "loop3", Path: "/dev/loop3", GUID: "4", State: "ONLINE"},
+						},
+					},
+				},
+			},
+		},
+		Spares: map[string]*VDev{
+			"loop5": {Name: "loop5", Path: "/dev/loop5", State: "INUSE"},
+			"loop4": {Name: "loop4", Path: "/dev/loop4", State: "AVAIL"},
+		},
+	}
+
+	want := []faultedVDev{{name: "/dev/loop0", guid: "1", state: "FAULTED"}}
+	if got := findFaulted(pool); !reflect.DeepEqual(got, want) {
+		t.Errorf("findFaulted() = %v, want %v", got, want)
+	}
+
+	if got := availableSpares(pool); !reflect.DeepEqual(got, []string{"/dev/loop4"}) {
+		t.Errorf("availableSpares() = %v", got)
+	}
+}
@@ -17,6 +17,8 @@
 
 package pool
 
+import "time"
+
 // ListResult represents the output of zpool list/get commands
 type ListResult struct {
 	Pools map[string]Pool `json:"pools"`
@@ -46,6 +48,48 @@ type Pool struct {
 	ScanStats  *ScanStats       `json:"scan_stats,omitempty"`
 	VDevs      map[string]*VDev `json:"vdevs,omitempty"`
 	ErrorCount string           `json:"error_count,omitempty"`
+
+	CheckpointStats *CheckpointStats `json:"checkpoint_stats,omitempty"`
+	RemovalStats    *RemovalStats    `json:"removal_stats,omitempty"`
+
+	// Auxiliary vdevs reported outside of the root vdev tree
+	Logs    map[string]*VDev `json:"logs,omitempty"`
+	L2Cache map[string]*VDev `json:"l2cache,omitempty"`
+	Spares  map[string]*VDev `json:"spares,omitempty"`
+	Special map[string]*VDev `json:"special,omitempty"`
+	Dedup   map[string]*VDev `json:"dedup,omitempty"`
+}
+
+// RemovalStats represents the device removal section of zpool status
+type RemovalStats struct {
+	Name          string `json:"name"`
+	State         string `json:"state"`
+	RemovingVDev  string `json:"removing_vdev"`
+	StartTime     string `json:"start_time"`
+	EndTime       string `json:"end_time"`
+	ToCopy        string `json:"to_copy"`
+	Copied        string `json:"copied"`
+	MappingMemory string `json:"mapping_memory"`
+
+	// PercentDone is derived from copied/to_copy when status is fetched
+	PercentDone float64 `json:"percent_done"`
+}
+
+// CheckpointStats represents the pool checkpoint section of zpool status
+type CheckpointStats struct {
+	State     string `json:"state"`
+	StartTime string `json:"start_time"`
+	Space     string `json:"space"`
+}
+
+// CheckpointInfo describes the checkpoint of a pool, if any
+type CheckpointInfo struct {
+	Pool      string `json:"pool"`
+	Exists    bool   `json:"exists"`
+	State     string `json:"state,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+	// Space consumed by the checkpoint, from the checkpoint pool property
+	Space string `json:"space,omitempty"`
 }
 
 // ScanStats represents pool scanning status
@@ -67,6 +111,36 @@ type ScanStats struct {
 	Issued             string `json:"issued"`
 }
 
+// Scrub actions
+const (
+	ScrubStart = "start"
+	ScrubStop  = "stop"
+	ScrubPause = "pause"
+)
+
+// ScrubConfig defines a zpool scrub request
+type ScrubConfig struct {
+	// start (or resume a paused scrub), stop or pause
+	Action string `json:"action"`
+	// ErrorScrub limits the scrub to blocks in the pool's error log
+	ErrorScrub bool `json:"error_scrub"`
+}
+
+// ScrubRecord describes a completed scrub kept in the scrub history
+type ScrubRecord struct {
+	Pool string `json:"pool"`
+	// SCRUB or ERRORSCRUB
+	Function  string `json:"function"`
+	State     string `json:"state"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	// Duration in seconds, when start and end times could be parsed
+	Duration int64  `json:"duration,omitempty"`
+	Examined string `json:"examined"`
+	Repaired string `json:"repaired"`
+	Errors   string `json:"errors"`
+}
+
 // Property represents a pool property with source information
 type Property struct {
 	Value  interface{} `json:"value"`
@@ -86,12 +160,49 @@ type VDev struct {
 	GUID           string           `json:"guid"`
 	State          string           `json:"state"`
 	Path           string           `json:"path,omitempty"`
+	StablePath     string           `json:"stable_path,omitempty"`
+	KernelName     string           `json:"kernel_name,omitempty"`
+	Class          string           `json:"class,omitempty"`
+	Initialize     *VDevProgress    `json:"initialize,omitempty"`
+	Trim           *VDevProgress    `json:"trim,omitempty"`
 	VDevs          map[string]*VDev `json:"vdevs,omitempty"` // Nested vdevs as map
 	ReadErrors     string           `json:"read_errors"`
 	WriteErrors    string           `json:"write_errors"`
 	ChecksumErrors string           `json:"checksum_errors"`
 }
 
+// VDevProgress represents the initialize or TRIM progress of a leaf vdev as
+// reported by zpool status -i/-t
+type VDevProgress struct {
+	// active, suspended, completed, none or unsupported
+	State   string `json:"state"`
+	Percent int    `json:"percent"`
+	// Time the operation was started or completed, in ctime format
+	Time string `json:"time,omitempty"`
+}
+
+// InitializeConfig defines a zpool initialize request. Devices limits the
+// operation to the given leaf vdevs; all vdevs are used if empty.
+type InitializeConfig struct {
+	// start, suspend, cancel or uninit
+	Action  string   `json:"action"`
+	Devices []string `json:"devices,omitempty"`
+	Wait    bool     `json:"wait"`
+}
+
+// TrimConfig defines a zpool trim request. Devices limits the operation to
+// the given leaf vdevs; all vdevs are used if empty.
+type TrimConfig struct {
+	// start, suspend or cancel
+	Action  string   `json:"action"`
+	Devices []string `json:"devices,omitempty"`
+	// Rate limits the TRIM rate per device, e.g. 100M (bytes per second)
+	Rate string `json:"rate,omitempty"`
+	// Secure performs a secure TRIM, only supported by some devices
+	Secure bool `json:"secure"`
+	Wait   bool `json:"wait"`
+}
+
 // Stats holds VDev performance statistics
 type Stats struct {
 	ReadErrors     int64 `json:"read_errors"`
@@ -109,24 +220,198 @@ type CreateConfig struct {
 	Name       string
 	VDevSpec   []VDevSpec
 	Properties map[string]string
-	Features   map[string]bool
+	// Features enables (true) or disables (false) individual feature@ flags
+	Features map[string]bool
+	// Force allows devices carrying a ZFS label or other signature;
+	// zpool create itself always runs with -f
 	Force      bool
 	MountPoint string
+	AltRoot    string
 }
 
-// VDevSpec defines virtual device configuration for pool creation
+// VDevSpec defines virtual device configuration for pool creation and
+// expansion. Type is a vdev type (mirror, raidz2, draid, ...) or an allocation
+// class (log, cache, spare, special, dedup) whose vdevs are given as Children.
 type VDevSpec struct {
-	Type     string     // mirror, raidz, etc.
-	Devices  []string   // Device paths
-	Children []VDevSpec // For nested vdev configurations
+	Type     string     `json:"type,omitempty"`     // mirror, raidz, log, etc.
+	Devices  []string   `json:"devices,omitempty"`  // Device paths
+	Children []VDevSpec `json:"children,omitempty"` // For nested vdev configurations
+}
+
+// AddConfig defines parameters for adding vdevs to an existing pool
+type AddConfig struct {
+	Name     string     `json:"name"`
+	VDevSpec []VDevSpec `json:"vdev_spec" binding:"required"`
+	// Force adds vdevs even if their redundancy doesn't match the pool or
+	// the devices carry a ZFS label or other signature
+	Force bool `json:"force"`
+	// DryRun previews the resulting layout without changing the pool
+	DryRun bool `json:"dry_run"`
+}
+
+// RemoveConfig defines parameters for removing top-level vdevs
+type RemoveConfig struct {
+	Name    string   `json:"name"`
+	Devices []string `json:"devices" binding:"required"`
+	// DryRun reports the memory the removal mapping will use
+	DryRun bool `json:"dry_run"`
+	// Wait blocks until the evacuation completes
+	Wait bool `json:"wait"`
+}
+
+// LayoutPreview holds the output of a dry-run pool change
+type LayoutPreview struct {
+	Pool   string `json:"pool"`
+	Output string `json:"output"`
 }
 
 // ImportConfig defines parameters for pool import
 type ImportConfig struct {
-	Name         string
-	Dir          string // Search directory
-	Properties   map[string]string
-	Force        bool
-	AllowDestroy bool
-	Paths        []string // Device paths to search
+	// Name of the pool to import. GUID takes precedence when set.
+	Name string `json:"name"`
+	GUID string `json:"guid"`
+	// NewName imports the pool under a different name
+	NewName string `json:"new_name"`
+
+	// Dir and Paths are directories or devices searched for pool members
+	Dir   string   `json:"dir"`
+	Paths []string `json:"paths"`
+
+	Properties map[string]string `json:"properties"`
+	Force      bool              `json:"force"`
+	// AllowDestroy imports a destroyed pool
+	AllowDestroy bool `json:"allow_destroy"`
+	ReadOnly     bool `json:"readonly"`
+	// MissingLog imports the pool with a missing log device
+	MissingLog bool   `json:"missing_log"`
+	AltRoot    string `json:"altroot"`
+	// CacheFile is a cache file path, or "none" to not cache the pool
+	CacheFile string `json:"cachefile"`
+
+	// RewindToCheckpoint rewinds the pool to its checkpoint on import,
+	// discarding every change made after the checkpoint was taken
+	RewindToCheckpoint bool `json:"rewind_to_checkpoint"`
+}
+
+// ImportableConfig defines a search for importable pools
+type ImportableConfig struct {
+	// Paths are directories or devices to search, instead of the defaults
+	Paths []string
+	// Destroyed lists destroyed pools only
+	Destroyed bool
+}
+
+// ImportablePool is a pool found by zpool import that can be imported
+type ImportablePool struct {
+	Name      string            `json:"name"`
+	GUID      string            `json:"guid"`
+	State     string            `json:"state"`
+	Destroyed bool              `json:"destroyed"`
+	Status    string            `json:"status,omitempty"`
+	Action    string            `json:"action,omitempty"`
+	Comment   string            `json:"comment,omitempty"`
+	See       string            `json:"see,omitempty"`
+	VDevs     []*ImportableVDev `json:"vdevs"`
+}
+
+// ImportableVDev is a vdev of an importable pool. Allocation class groups
+// (logs, cache, spares) appear as vdevs without a state.
+type ImportableVDev struct {
+	Name    string            `json:"name"`
+	State   string            `json:"state,omitempty"`
+	Message string            `json:"message,omitempty"`
+	VDevs   []*ImportableVDev `json:"vdevs,omitempty"`
+}
+
+// HistoryConfig defines a zpool history query
+type HistoryConfig struct {
+	// Internal includes internally logged ZFS events (-i)
+	Internal bool
+	// Long includes the user, host and zone of each entry (-l)
+	Long bool
+	// Since and Until bound the entry time; zero values are unbounded
+	Since time.Time
+	Until time.Time
+	// Dataset limits entries to those touching the dataset or its children
+	Dataset string
+}
+
+// HistoryEntry is a single parsed zpool history record
+type HistoryEntry struct {
+	Pool string    `json:"pool"`
+	Time time.Time `json:"time"`
+	// Command is the logged command line, or the operation and message of
+	// an internal event
+	Command  string `json:"command"`
+	Internal bool   `json:"internal"`
+
+	// Fields of internal events
+	TXG       uint64 `json:"txg,omitempty"`
+	Operation string `json:"operation,omitempty"`
+	Dataset   string `json:"dataset,omitempty"`
+
+	// Fields of the long format
+	UID  string `json:"uid,omitempty"`
+	User string `json:"user,omitempty"`
+	Host string `json:"host,omitempty"`
+	Zone string `json:"zone,omitempty"`
+}
+
+// Layout types accepted by the pool creation planner
+const (
+	LayoutStripe = "stripe"
+	LayoutMirror = "mirror"
+	LayoutRaidz1 = "raidz1"
+	LayoutRaidz2 = "raidz2"
+	LayoutRaidz3 = "raidz3"
+	LayoutDraid1 = "draid1"
+	LayoutDraid2 = "draid2"
+	LayoutDraid3 = "draid3"
+)
+
+// LayoutConfig describes the target data vdev layout of a new pool, e.g.
+// raidz2 with width 6 or mirrors of 2. The disks are split into vdevs of
+// Width devices; dRAID uses all disks in a single vdev.
+type LayoutConfig struct {
+	Type  string `json:"type" binding:"required"`
+	Width int    `json:"width,omitempty"`
+	// dRAID only: data devices per redundancy group and distributed spares
+	DraidData   int `json:"draid_data,omitempty"`
+	DraidSpares int `json:"draid_spares,omitempty"`
+}
+
+// PlanConfig defines the input of the pool creation planner
+type PlanConfig struct {
+	Name   string       `json:"name" binding:"required"`
+	Disks  []string     `json:"disks" binding:"required"`
+	Layout LayoutConfig `json:"layout" binding:"required"`
+	// Ashift defaults to the largest physical sector size of the disks,
+	// but never less than 12
+	Ashift          int               `json:"ashift,omitempty"`
+	AllowMixedSizes bool              `json:"allow_mixed_sizes,omitempty"`
+	Properties      map[string]string `json:"properties,omitempty"`
+	Features        map[string]bool   `json:"features,omitempty"`
+	MountPoint      string            `json:"mountpoint,omitempty"`
+	AltRoot         string            `json:"altroot,omitempty"`
+	Force           bool              `json:"force,omitempty"`
+}
+
+// PlanDisk is a disk considered by the planner
+type PlanDisk struct {
+	Path         string `json:"path"`
+	Size         uint64 `json:"size"`
+	PhysicalSize uint64 `json:"physical_sector_size"`
+}
+
+// CreatePlan is the validated result of the planner. Config can be submitted
+// as is to create the pool.
+type CreatePlan struct {
+	Config   CreateConfig `json:"config"`
+	Disks    []PlanDisk   `json:"disks"`
+	Ashift   int          `json:"ashift"`
+	RawSize  uint64       `json:"raw_size"`
+	Usable   uint64       `json:"estimated_usable"`
+	Warnings []string     `json:"warnings,omitempty"`
+	// Preview is the layout reported by zpool create -n
+	Preview string `json:"preview"`
 }
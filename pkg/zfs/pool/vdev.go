@@ -0,0 +1,308 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/zfs/command"
+)
+
+// removalWaitTimeout bounds zpool remove -w, which blocks until all data
+// has been evacuated from the removed vdevs
+const removalWaitTimeout = 12 * time.Hour
+
+// Allocation classes accepted as VDevSpec types by zpool add
+var allocationClasses = map[string]bool{
+	"log":     true,
+	"cache":   true,
+	"spare":   true,
+	"special": true,
+	"dedup":   true,
+}
+
+// AddVDevs adds vdevs to an existing pool. Unless forced, the redundancy of
+// the new data, special and dedup vdevs must match what the pool already
+// uses. With DryRun set the pool is left untouched and the resulting layout
+// is returned.
+func (p *Manager) AddVDevs(ctx context.Context, cfg AddConfig) (*LayoutPreview, error) {
+	if len(cfg.VDevSpec) == 0 {
+		return nil, errors.New(errors.ZFSPoolInvalidDevice, "no vdevs specified")
+	}
+	if usesDRAID(cfg.VDevSpec) {
+		// Pools created before 2.1, or with feature@draid disabled, can't
+		// take draid vdevs
+		if err := p.executor.Require(ctx, command.FeatureDRAID); err != nil {
+			return nil, err
+		}
+		if err := p.executor.RequirePoolFeature(ctx, cfg.Name, command.FeatureDRAID); err != nil {
+			return nil, err
+		}
+	}
+
+	if !cfg.Force {
+		status, err := p.Status(ctx, cfg.Name)
+		if err != nil {
+			return nil, err
+		}
+		pool, ok := status.Pools[cfg.Name]
+		if !ok {
+			return nil, errors.New(errors.ZFSPoolNotFound, cfg.Name)
+		}
+		if err := checkRedundancy(pool, cfg.VDevSpec); err != nil {
+			return nil, err
+		}
+	}
+
+	args := []string{"add"}
+	if cfg.Force {
+		args = append(args, "-f")
+	}
+	if cfg.DryRun {
+		args = append(args, "-n")
+	}
+	args = append(args, cfg.Name)
+	args = append(args, buildVDevArgs(p.stableSpecs(cfg.VDevSpec))...)
+
+	out, err := p.executor.Execute(ctx, command.CommandOptions{}, "zpool add", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return nil, errors.Wrap(err, errors.ZFSPoolDeviceOperation).
+				WithMetadata("output", string(out))
+		}
+		return nil, errors.Wrap(err, errors.ZFSPoolDeviceOperation)
+	}
+
+	if !cfg.DryRun {
+		return nil, nil
+	}
+	return &LayoutPreview{Pool: cfg.Name, Output: strings.TrimSpace(string(out))}, nil
+}
+
+// RemoveDevice removes top-level vdevs, evacuating their data to the rest of
+// the pool. Progress is reported in the removal_stats of Status.
+func (p *Manager) RemoveDevice(ctx context.Context, cfg RemoveConfig) (*LayoutPreview, error) {
+	if len(cfg.Devices) == 0 {
+		return nil, errors.New(errors.ZFSPoolInvalidDevice, "no devices specified")
+	}
+
+	args := []string{"remove"}
+	if cfg.DryRun {
+		args = append(args, "-n")
+	}
+	if cfg.Wait {
+		args = append(args, "-w")
+	}
+	args = append(args, cfg.Name)
+	args = append(args, cfg.Devices...)
+
+	opts := command.CommandOptions{}
+	if cfg.Wait {
+		// Evacuation can take much longer than the default timeout
+		opts.Timeout = removalWaitTimeout
+	}
+
+	out, err := p.executor.Execute(ctx, opts, "zpool remove", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return nil, errors.Wrap(err, errors.ZFSPoolDeviceOperation).
+				WithMetadata("output", string(out))
+		}
+		return nil, errors.Wrap(err, errors.ZFSPoolDeviceOperation)
+	}
+
+	if !cfg.DryRun {
+		return nil, nil
+	}
+	return &LayoutPreview{Pool: cfg.Name, Output: strings.TrimSpace(string(out))}, nil
+}
+
+// CancelRemoval stops an in-progress device removal
+func (p *Manager) CancelRemoval(ctx context.Context, name string) error {
+	args := []string{"remove", "-s", name}
+
+	out, err := p.executor.Execute(ctx, command.CommandOptions{}, "zpool remove", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return errors.Wrap(err, errors.ZFSPoolDeviceOperation).
+				WithMetadata("output", string(out))
+		}
+		return errors.Wrap(err, errors.ZFSPoolDeviceOperation)
+	}
+	return nil
+}
+
+// usesDRAID reports whether any spec is a draid vdev
+func usesDRAID(specs []VDevSpec) bool {
+	for _, spec := range specs {
+		if strings.HasPrefix(spec.Type, "draid") || usesDRAID(spec.Children) {
+			return true
+		}
+	}
+	return false
+}
+
+// redundancy describes the replication of a top-level vdev, e.g. "mirror"
+// with width 2 or "raidz2" with width 6
+type redundancy struct {
+	kind  string
+	width int
+}
+
+func (r redundancy) String() string {
+	if r.kind == "disk" {
+		return "non-redundant disk"
+	}
+	return fmt.Sprintf("%d-wide %s", r.width, r.kind)
+}
+
+// checkRedundancy verifies that every new data, special or dedup vdev matches
+// the redundancy of the existing vdevs of the same class
+func checkRedundancy(pool Pool, specs []VDevSpec) error {
+	existing := existingRedundancy(pool)
+
+	for _, spec := range specs {
+		class := ""
+		vdevs := []VDevSpec{spec}
+		if allocationClasses[spec.Type] {
+			class = spec.Type
+			if class == "log" || class == "cache" || class == "spare" {
+				continue
+			}
+			vdevs = spec.Children
+			if len(spec.Devices) > 0 {
+				vdevs = append(vdevs, VDevSpec{Devices: spec.Devices})
+			}
+		}
+
+		want, ok := existing[class]
+		if !ok {
+			continue
+		}
+		for _, v := range vdevs {
+			// A pool whose vdevs already disagree matches no new vdev
+			got := specRedundancy(v)
+			if len(want) != 1 || got != want[0] {
+				names := make([]string, len(want))
+				for i, r := range want {
+					names[i] = r.String()
+				}
+				return errors.New(errors.ZFSPoolRedundancyMismatch,
+					fmt.Sprintf("pool %s uses %s vdevs and new vdev uses %s, use force to override",
+						pool.Name, strings.Join(names, " and "), got))
+			}
+		}
+	}
+	return nil
+}
+
+// existingRedundancy returns the distinct redundancies of the top-level
+// vdevs of each class in the pool, keyed by class ("" for data vdevs) and
+// sorted. Indirect vdevs left by a removal and holes carry no data and are
+// skipped.
+func existingRedundancy(pool Pool) map[string][]redundancy {
+	result := make(map[string][]redundancy)
+	add := func(class string, vdevs map[string]*VDev) {
+		for _, v := range vdevs {
+			if v.VDevType == "indirect" || v.VDevType == "hole" {
+				continue
+			}
+			c := class
+			if v.Class != "" && v.Class != "normal" {
+				c = v.Class
+			}
+			r := vdevRedundancy(v)
+			seen := false
+			for _, existing := range result[c] {
+				if existing == r {
+					seen = true
+					break
+				}
+			}
+			if !seen {
+				result[c] = append(result[c], r)
+			}
+		}
+	}
+
+	for _, root := range pool.VDevs {
+		if root.VDevType == "root" {
+			add("", root.VDevs)
+		} else {
+			add("", map[string]*VDev{root.Name: root})
+		}
+	}
+	add("special", pool.Special)
+	add("dedup", pool.Dedup)
+
+	for _, rs := range result {
+		sort.Slice(rs, func(i, j int) bool { return rs[i].String() < rs[j].String() })
+	}
+	return result
+}
+
+func vdevRedundancy(v *VDev) redundancy {
+	switch v.VDevType {
+	case "mirror":
+		return redundancy{kind: "mirror", width: len(v.VDevs)}
+	case "raidz", "draid":
+		// Parity is only carried in the vdev name, e.g. raidz2-0
+		kind := strings.SplitN(v.Name, "-", 2)[0]
+		kind = strings.SplitN(kind, ":", 2)[0]
+		if kind == "raidz" {
+			kind = "raidz1"
+		}
+		return redundancy{kind: kind, width: len(v.VDevs)}
+	}
+	return redundancy{kind: "disk", width: 1}
+}
+
+func specRedundancy(spec VDevSpec) redundancy {
+	kind := spec.Type
+	switch {
+	case kind == "":
+		return redundancy{kind: "disk", width: 1}
+	case kind == "raidz":
+		kind = "raidz1"
+	case strings.HasPrefix(kind, "draid"):
+		kind = strings.SplitN(kind, ":", 2)[0]
+		if kind == "draid" {
+			kind = "draid1"
+		}
+	}
+	return redundancy{kind: kind, width: len(spec.Devices)}
+}
+
+// removalProgress fills in the percentage of data evacuated by a removal
+func removalProgress(stats *RemovalStats) {
+	toCopy, err := strconv.ParseFloat(stats.ToCopy, 64)
+	if err != nil || toCopy <= 0 {
+		return
+	}
+	copied, err := strconv.ParseFloat(stats.Copied, 64)
+	if err != nil {
+		return
+	}
+	stats.PercentDone = copied / toCopy * 100
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pool
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckRedundancy(t *testing.T) {
+	mirrorPool := Pool{
+		Name: "tank",
+		VDevs: map[string]*VDev{
+			"tank": {
+				Name:     "tank",
+				VDevType: "root",
+				VDevs: map[string]*VDev{
+					"mirror-0": {
+						Name:     "mirror-0",
+						VDevType: "mirror",
+						VDevs: map[string]*VDev{
+							"/dev/loop0": {Name: "/dev/loop0", VDevType: "disk"},
+							"/dev/loop1": {Name: "/dev/loop1", VDevType: "disk"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	raidzPool := Pool{
+		Name: "tank",
+		VDevs: map[string]*VDev{
+			"tank": {
+				Name:     "tank",
+				VDevType: "root",
+				VDevs: map[string]*VDev{
+					"raidz2-0": {
+						Name:     "raidz2-0",
+						VDevType: "raidz",
+						VDevs: map[string]*VDev{
+							"a": {}, "b": {}, "c": {}, "d": {},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	mirror := func(name string, disks ...string) *VDev {
+		v := &VDev{Name: name, VDevType: "mirror", VDevs: map[string]*VDev{}}
+		for _, d := range disks {
+			v.VDevs[d] = &VDev{Name: d, VDevType: "disk"}
+		}
+		return v
+	}
+	rootPool := func(vdevs ...*VDev) Pool {
+		root := &VDev{Name: "tank", VDevType: "root", VDevs: map[string]*VDev{}}
+		for _, v := range vdevs {
+			root.VDevs[v.Name] = v
+		}
+		return Pool{Name: "tank", VDevs: map[string]*VDev{"tank": root}}
+	}
+
+	// A mirror pool whose second vdev was removed, leaving an indirect vdev
+	removedPool := rootPool(
+		mirror("mirror-0", "/dev/loop0", "/dev/loop1"),
+		&VDev{Name: "indirect-1", VDevType: "indirect"},
+		&VDev{Name: "hole-2", VDevType: "hole"},
+	)
+
+	// A pool that already mixes mirror widths
+	mixedPool := rootPool(
+		mirror("mirror-0", "/dev/loop0", "/dev/loop1"),
+		mirror("mirror-1", "/dev/loop2", "/dev/loop3", "/dev/loop4"),
+	)
+
+	tests := []struct {
+		name    string
+		pool    Pool
+		specs   []VDevSpec
+		wantErr bool
+	}{
+		{
+			name:  "matching mirror",
+			pool:  mirrorPool,
+			specs: []VDevSpec{{Type: "mirror", Devices: []string{"/dev/loop2", "/dev/loop3"}}},
+		},
+		{
+			name:    "wider mirror",
+			pool:    mirrorPool,
+			specs:   []VDevSpec{{Type: "mirror", Devices: []string{"/dev/a", "/dev/b", "/dev/c"}}},
+			wantErr: true,
+		},
+		{
+			name:    "plain disk on mirror pool",
+			pool:    mirrorPool,
+			specs:   []VDevSpec{{Devices: []string{"/dev/loop2"}}},
+			wantErr: true,
+		},
+		{
+			name: "log and cache are not checked",
+			pool: mirrorPool,
+			specs: []VDevSpec{
+				{Type: "log", Devices: []string{"/dev/loop2"}},
+				{Type: "cache", Devices: []string{"/dev/loop3"}},
+			},
+		},
+		{
+			name: "first special vdev",
+			pool: mirrorPool,
+			specs: []VDevSpec{{
+				Type:     "special",
+				Children: []VDevSpec{{Type: "mirror", Devices: []string{"/dev/a", "/dev/b"}}},
+			}},
+		},
+		{
+			name:  "matching raidz2",
+			pool:  raidzPool,
+			specs: []VDevSpec{{Type: "raidz2", Devices: []string{"/dev/a", "/dev/b", "/dev/c", "/dev/d"}}},
+		},
+		{
+			name:  "indirect and hole vdevs are skipped",
+			pool:  removedPool,
+			specs: []VDevSpec{{Type: "mirror", Devices: []string{"/dev/loop2", "/dev/loop3"}}},
+		},
+		{
+			name:    "2-wide mirror on mixed pool",
+			pool:    mixedPool,
+			specs:   []VDevSpec{{Type: "mirror", Devices: []string{"/dev/a", "/dev/b"}}},
+			wantErr: true,
+		},
+		{
+			name:    "3-wide mirror on mixed pool",
+			pool:    mixedPool,
+			specs:   []VDevSpec{{Type: "mirror", Devices: []string{"/dev/a", "/dev/b", "/dev/c"}}},
+			wantErr: true,
+		},
+		{
+			name:    "raidz1 on raidz2 pool",
+			pool:    raidzPool,
+			specs:   []VDevSpec{{Type: "raidz", Devices: []string{"/dev/a", "/dev/b", "/dev/c", "/dev/d"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Map order varies between runs, so check repeatedly
+			for i := 0; i < 20; i++ {
+				err := checkRedundancy(tt.pool, tt.specs)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("checkRedundancy() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			}
+		})
+	}
+
+	want := "pool tank uses 2-wide mirror and 3-wide mirror vdevs and new vdev uses non-redundant disk, use force to override"
+	err := checkRedundancy(mixedPool, []VDevSpec{{Devices: []string{"/dev/a"}}})
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("checkRedundancy() error = %v, want %q", err, want)
+	}
+}
+
+func TestRemovalProgress(t *testing.T) {
+	stats := &RemovalStats{ToCopy: "1000", Copied: "250"}
+	removalProgress(stats)
+	if stats.PercentDone != 25 {
+		t.Errorf("PercentDone = %v, want 25", stats.PercentDone)
+	}
+}
+
+func TestUsesDRAID(t *testing.T) {
+	if usesDRAID([]VDevSpec{{Type: "mirror", Devices: []string{"/dev/sdb", "/dev/sdc"}}}) {
+		t.Error("mirror reported as draid")
+	}
+	if !usesDRAID([]VDevSpec{{Type: "special", Children: []VDevSpec{{Type: "draid2:4d:1s"}}}}) {
+		t.Error("nested draid not detected")
+	}
+}
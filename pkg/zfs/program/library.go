@@ -0,0 +1,169 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package program
+
+import "sort"
+
+// library holds the vetted channel programs that can be run by name. Scripts
+// receive their parameters positionally through argv, in the order declared
+// by Params, and always return a table so the result decodes to a JSON object.
+var library = map[string]Script{
+	"snapshot_recursive": {
+		Name: "snapshot_recursive",
+		Description: "Atomically snapshot a dataset and all of its descendants. " +
+			"Nothing is created if any snapshot would fail.",
+		Params: []Param{
+			{Name: "dataset", Kind: ParamDataset, Description: "Root dataset"},
+			{Name: "snapname", Kind: ParamComponent, Description: "Snapshot name"},
+		},
+		Source: `
+args = ...
+argv = args["argv"]
+root = argv[1]
+snapname = argv[2]
+
+snaps = {}
+function collect(fs)
+    table.insert(snaps, fs .. "@" .. snapname)
+    for child in zfs.list.children(fs) do
+        collect(child)
+    end
+end
+collect(root)
+
+failed = {}
+for _, snap in ipairs(snaps) do
+    local err = zfs.check.snapshot(snap)
+    if err ~= 0 then
+        failed[snap] = err
+    end
+end
+if next(failed) ~= nil then
+    return {failed = failed}
+end
+
+created = {}
+for _, snap in ipairs(snaps) do
+    created[snap] = zfs.sync.snapshot(snap)
+end
+return {created = created}
+`,
+	},
+	"prune_snapshots": {
+		Name: "prune_snapshots",
+		Description: "Destroy the oldest snapshots of a dataset whose name starts " +
+			"with prefix, keeping the newest keep snapshots.",
+		Params: []Param{
+			{Name: "dataset", Kind: ParamDataset, Description: "Dataset to prune"},
+			{Name: "prefix", Kind: ParamComponent, Description: "Snapshot name prefix"},
+			{Name: "keep", Kind: ParamUint, Description: "Number of snapshots to keep"},
+		},
+		Source: `
+args = ...
+argv = args["argv"]
+fs = argv[1]
+prefix = argv[2]
+keep = tonumber(argv[3])
+
+matched = {}
+txg = {}
+for snap in zfs.list.snapshots(fs) do
+    local short = string.sub(snap, string.len(fs) + 2)
+    if string.sub(short, 1, string.len(prefix)) == prefix then
+        table.insert(matched, snap)
+        txg[snap] = zfs.get_prop(snap, "createtxg")
+    end
+end
+table.sort(matched, function(a, b) return txg[a] < txg[b] end)
+
+destroyed = {}
+failed = {}
+for i = 1, #matched - keep do
+    local snap = matched[i]
+    local err = zfs.check.destroy(snap)
+    if err == 0 then
+        err = zfs.sync.destroy(snap)
+    end
+    if err == 0 then
+        destroyed[snap] = txg[snap]
+    else
+        failed[snap] = err
+    end
+end
+return {destroyed = destroyed, failed = failed, matched = #matched}
+`,
+	},
+	"property_audit": {
+		Name: "property_audit",
+		Description: "Report the value and source of the given properties for a " +
+			"dataset and all of its descendants.",
+		ReadOnly: true,
+		Params: []Param{
+			{Name: "dataset", Kind: ParamDataset, Description: "Root dataset"},
+			{
+				Name:        "properties",
+				Kind:        ParamPropertyList,
+				Description: "Comma separated property names",
+			},
+		},
+		Source: `
+args = ...
+argv = args["argv"]
+root = argv[1]
+
+props = {}
+for prop in string.gmatch(argv[2], "[^,]+") do
+    table.insert(props, prop)
+end
+
+result = {}
+function audit(ds)
+    local entry = {}
+    for _, prop in ipairs(props) do
+        local value, source = zfs.get_prop(ds, prop)
+        entry[prop] = {value = value, source = source}
+    end
+    result[ds] = entry
+    for child in zfs.list.children(ds) do
+        audit(child)
+    end
+end
+audit(root)
+return result
+`,
+	},
+}
+
+// Lookup returns the library script with the given name
+func Lookup(name string) (Script, bool) {
+	s, ok := library[name]
+	return s, ok
+}
+
+// Scripts returns the library scripts sorted by name, without their source
+func Scripts() []Script {
+	scripts := make([]Script, 0, len(library))
+	for _, s := range library {
+		s.Source = ""
+		scripts = append(scripts, s)
+	}
+	sort.Slice(scripts, func(i, j int) bool {
+		return scripts[i].Name < scripts[j].Name
+	})
+	return scripts
+}
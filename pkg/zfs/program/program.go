@@ -0,0 +1,258 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package program
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/stratastor/rodent/pkg/errors"
+	"github.com/stratastor/rodent/pkg/zfs/command"
+	"github.com/stratastor/rodent/pkg/zfs/common"
+)
+
+// Manager runs ZFS channel programs
+type Manager struct {
+	executor *command.CommandExecutor
+	cfg      Config
+}
+
+func NewManager(executor *command.CommandExecutor, cfg Config) *Manager {
+	if cfg.InstructionLimit == 0 || cfg.InstructionLimit > MaxInstructionLimit {
+		cfg.InstructionLimit = DefaultInstructionLimit
+	}
+	if cfg.MemoryLimit == 0 || cfg.MemoryLimit > MaxMemoryLimit {
+		cfg.MemoryLimit = DefaultMemoryLimit
+	}
+	return &Manager{executor: executor, cfg: cfg}
+}
+
+// AllowCustom reports whether caller supplied scripts may be run by
+// callers with the admin role
+func (m *Manager) AllowCustom() bool {
+	return m.cfg.AllowCustom && m.cfg.AdminToken != ""
+}
+
+// Admin reports whether token grants the admin role. Rodent has no user
+// accounts; the role is held by callers presenting the configured admin
+// token.
+func (m *Manager) Admin(token string) bool {
+	if m.cfg.AdminToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(m.cfg.AdminToken)) == 1
+}
+
+// Run executes a script from the vetted library
+func (m *Manager) Run(ctx context.Context, cfg RunConfig) (*Result, error) {
+	script, ok := Lookup(cfg.Name)
+	if !ok {
+		return nil, errors.New(errors.ZFSProgramNotFound, cfg.Name)
+	}
+
+	if err := common.PoolNameCheck(cfg.Pool); err != nil {
+		return nil, err
+	}
+
+	argv, err := bindArgs(script, cfg.Pool, cfg.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := m.execute(ctx, cfg.Pool, script.Source, argv, script.ReadOnly, cfg.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseResult(script.Name, cfg.Pool, script.ReadOnly, out)
+}
+
+// RunCustom executes a caller supplied script. It is refused unless custom
+// scripts are enabled in the configuration and the caller's token grants the
+// admin role.
+func (m *Manager) RunCustom(ctx context.Context, token string, cfg CustomConfig) (*Result, error) {
+	if !m.AllowCustom() {
+		return nil, errors.New(errors.ZFSProgramCustomDenied,
+			"set zfs.channelPrograms.allowCustom and adminToken to run custom scripts")
+	}
+	if !m.Admin(token) {
+		return nil, errors.New(errors.ZFSProgramCustomDenied,
+			"custom scripts require the admin token")
+	}
+
+	if err := common.PoolNameCheck(cfg.Pool); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(cfg.Source) == "" {
+		return nil, errors.New(errors.ZFSProgramInvalidArgument, "script source is empty")
+	}
+
+	out, err := m.execute(ctx, cfg.Pool, cfg.Source, cfg.Args, cfg.ReadOnly, cfg.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseResult("custom", cfg.Pool, cfg.ReadOnly, out)
+}
+
+// limits resolves the effective limits for a run. Requests may only lower
+// the configured limits.
+func (m *Manager) limits(l Limits) (uint64, uint64) {
+	instr, mem := m.cfg.InstructionLimit, m.cfg.MemoryLimit
+	if l.InstructionLimit > 0 && l.InstructionLimit < instr {
+		instr = l.InstructionLimit
+	}
+	if l.MemoryLimit > 0 && l.MemoryLimit < mem {
+		mem = l.MemoryLimit
+	}
+	return instr, mem
+}
+
+func (m *Manager) execute(
+	ctx context.Context,
+	pool, source string,
+	argv []string,
+	readOnly bool,
+	l Limits,
+) ([]byte, error) {
+	// The script is handed to zfs program through a private temp file since
+	// Lua source can't pass the executor's argument checks
+	f, err := os.CreateTemp("", "rodent-zcp-*.lua")
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ZFSProgramFailed)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(source); err != nil {
+		f.Close()
+		return nil, errors.Wrap(err, errors.ZFSProgramFailed)
+	}
+	if err := f.Close(); err != nil {
+		return nil, errors.Wrap(err, errors.ZFSProgramFailed)
+	}
+
+	instr, mem := m.limits(l)
+	args := []string{"program"}
+	if readOnly {
+		args = append(args, "-n")
+	}
+	args = append(args,
+		"-t", strconv.FormatUint(instr, 10),
+		"-m", strconv.FormatUint(mem, 10),
+		pool, f.Name())
+	args = append(args, argv...)
+
+	opts := command.CommandOptions{
+		Flags: command.FlagJSON,
+	}
+
+	out, err := m.executor.Execute(ctx, opts, "zfs program", args...)
+	if err != nil {
+		if len(out) > 0 {
+			return nil, errors.Wrap(err, errors.ZFSProgramFailed).
+				WithMetadata("output", string(out))
+		}
+		return nil, errors.Wrap(err, errors.ZFSProgramFailed)
+	}
+	return out, nil
+}
+
+// bindArgs validates named arguments against the script's parameters and
+// returns them in positional order
+func bindArgs(script Script, pool string, args map[string]string) ([]string, error) {
+	for name := range args {
+		if !hasParam(script, name) {
+			return nil, errors.New(errors.ZFSProgramInvalidArgument,
+				fmt.Sprintf("unknown argument %q for %s", name, script.Name))
+		}
+	}
+
+	argv := make([]string, 0, len(script.Params))
+	for _, p := range script.Params {
+		v, ok := args[p.Name]
+		if !ok || v == "" {
+			return nil, errors.New(errors.ZFSProgramInvalidArgument,
+				fmt.Sprintf("missing argument %q", p.Name))
+		}
+		if err := validateParam(p, pool, v); err != nil {
+			return nil, errors.New(errors.ZFSProgramInvalidArgument,
+				fmt.Sprintf("argument %q: %v", p.Name, err))
+		}
+		argv = append(argv, v)
+	}
+	return argv, nil
+}
+
+func hasParam(script Script, name string) bool {
+	for _, p := range script.Params {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func validateParam(p Param, pool, value string) error {
+	switch p.Kind {
+	case ParamDataset:
+		if err := common.DatasetNameCheck(value); err != nil {
+			return err
+		}
+		if value != pool && !strings.HasPrefix(value, pool+"/") {
+			return fmt.Errorf("dataset %s is not in pool %s", value, pool)
+		}
+	case ParamComponent:
+		return common.ComponentNameCheck(value)
+	case ParamUint:
+		if _, err := strconv.ParseUint(value, 10, 32); err != nil {
+			return fmt.Errorf("invalid number %s", value)
+		}
+	case ParamPropertyList:
+		for _, prop := range strings.Split(value, ",") {
+			if !common.IsValidDatasetProperty(prop) {
+				return fmt.Errorf("invalid property %s", prop)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported parameter kind %s", p.Kind)
+	}
+	return nil
+}
+
+// parseResult decodes the JSON rendering of the returned nvlist
+func parseResult(name, pool string, readOnly bool, out []byte) (*Result, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(out, &raw); err != nil {
+		return nil, errors.Wrap(err, errors.CommandOutputParse).
+			WithMetadata("output", string(out))
+	}
+
+	result := &Result{Program: name, Pool: pool, ReadOnly: readOnly}
+	if ret, ok := raw["return"]; ok {
+		result.Return = ret
+	} else {
+		result.Return = raw
+	}
+	return result, nil
+}
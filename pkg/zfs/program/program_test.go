@@ -0,0 +1,139 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package program
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stratastor/rodent/pkg/errors"
+)
+
+func TestBindArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		args    map[string]string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "snapshot in order",
+			script: "snapshot_recursive",
+			args:   map[string]string{"snapname": "daily", "dataset": "tank/data"},
+			want:   []string{"tank/data", "daily"},
+		},
+		{
+			name:    "dataset outside pool",
+			script:  "snapshot_recursive",
+			args:    map[string]string{"dataset": "other/data", "snapname": "daily"},
+			wantErr: true,
+		},
+		{
+			name:    "missing argument",
+			script:  "prune_snapshots",
+			args:    map[string]string{"dataset": "tank", "prefix": "auto-"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown argument",
+			script:  "prune_snapshots",
+			args:    map[string]string{"dataset": "tank", "prefix": "auto-", "keep": "3", "x": "y"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid keep",
+			script:  "prune_snapshots",
+			args:    map[string]string{"dataset": "tank", "prefix": "auto-", "keep": "-1"},
+			wantErr: true,
+		},
+		{
+			name:   "property list",
+			script: "property_audit",
+			args:   map[string]string{"dataset": "tank", "properties": "compression,used"},
+			want:   []string{"tank", "compression,used"},
+		},
+		{
+			name:    "invalid property",
+			script:  "property_audit",
+			args:    map[string]string{"dataset": "tank", "properties": "compression,bogus"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, ok := Lookup(tt.script)
+			if !ok {
+				t.Fatalf("script %s not found", tt.script)
+			}
+			got, err := bindArgs(script, "tank", tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("bindArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bindArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimits(t *testing.T) {
+	m := NewManager(nil, Config{InstructionLimit: MaxInstructionLimit + 1})
+	if m.cfg.InstructionLimit != DefaultInstructionLimit {
+		t.Errorf("instruction limit = %d, want default", m.cfg.InstructionLimit)
+	}
+
+	instr, mem := m.limits(Limits{InstructionLimit: 1000, MemoryLimit: MaxMemoryLimit})
+	if instr != 1000 || mem != DefaultMemoryLimit {
+		t.Errorf("limits() = %d, %d", instr, mem)
+	}
+}
+
+func TestCustomRequiresAdmin(t *testing.T) {
+	tests := []struct {
+		cfg   Config
+		token string
+		allow bool
+		admin bool
+	}{
+		{Config{AllowCustom: true, AdminToken: "s3cret"}, "s3cret", true, true},
+		{Config{AllowCustom: true, AdminToken: "s3cret"}, "wrong", true, false},
+		{Config{AllowCustom: true, AdminToken: "s3cret"}, "", true, false},
+		{Config{AllowCustom: true}, "", false, false},
+		{Config{AdminToken: "s3cret"}, "s3cret", false, true},
+	}
+
+	for _, tt := range tests {
+		m := NewManager(nil, tt.cfg)
+		if got := m.AllowCustom(); got != tt.allow {
+			t.Errorf("%+v: AllowCustom() = %v, want %v", tt.cfg, got, tt.allow)
+		}
+		if got := m.Admin(tt.token); got != tt.admin {
+			t.Errorf("%+v: Admin(%q) = %v, want %v", tt.cfg, tt.token, got, tt.admin)
+		}
+		if tt.allow && tt.admin {
+			continue
+		}
+		_, err := m.RunCustom(context.Background(), tt.token, CustomConfig{Pool: "tank", Source: "return {}"})
+		if re, ok := err.(*errors.RodentError); !ok || re.Code != errors.ZFSProgramCustomDenied {
+			t.Errorf("%+v: RunCustom(%q) error = %v, want ZFSProgramCustomDenied", tt.cfg, tt.token, err)
+		}
+	}
+}
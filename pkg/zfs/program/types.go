@@ -0,0 +1,101 @@
+/*
+ * Copyright 2024-2025 Raamsri Kumar <raam@tinkershack.in>
+ * Copyright 2024-2025 The StrataSTOR Authors and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package program
+
+// ParamKind describes how a script parameter is validated before it is passed
+// to the channel program as a positional argument.
+type ParamKind string
+
+const (
+	// ParamDataset is a filesystem or volume name inside the target pool
+	ParamDataset ParamKind = "dataset"
+	// ParamComponent is a single name component, e.g. a snapshot short name
+	ParamComponent ParamKind = "component"
+	// ParamUint is a non-negative integer
+	ParamUint ParamKind = "uint"
+	// ParamPropertyList is a comma separated list of dataset properties
+	ParamPropertyList ParamKind = "property_list"
+)
+
+const (
+	// Limits enforced by the kernel for zfs program -t and -m
+	MaxInstructionLimit uint64 = 100000000
+	MaxMemoryLimit      uint64 = 100 * 1024 * 1024
+
+	DefaultInstructionLimit uint64 = 10000000
+	DefaultMemoryLimit      uint64 = 10 * 1024 * 1024
+)
+
+// Param describes a positional argument of a library script
+type Param struct {
+	Name        string    `json:"name"`
+	Kind        ParamKind `json:"kind"`
+	Description string    `json:"description"`
+}
+
+// Script is a vetted channel program from the built-in library
+type Script struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	ReadOnly    bool    `json:"read_only"`
+	Params      []Param `json:"params"`
+	Source      string  `json:"source,omitempty"`
+}
+
+// Config holds the execution policy of the channel program manager
+type Config struct {
+	AllowCustom bool
+	// AdminToken is the bearer token of the admin role that custom
+	// scripts require
+	AdminToken       string
+	InstructionLimit uint64
+	MemoryLimit      uint64
+}
+
+// Limits optionally lowers the instruction and memory limits for a single run
+type Limits struct {
+	InstructionLimit uint64 `json:"instruction_limit,omitempty"`
+	MemoryLimit      uint64 `json:"memory_limit,omitempty"`
+}
+
+// RunConfig runs a script from the library by name
+type RunConfig struct {
+	Name string            `json:"name"`
+	Pool string            `json:"pool" binding:"required"`
+	Args map[string]string `json:"args"`
+	Limits
+}
+
+// CustomConfig runs a caller supplied script. Only honoured when custom
+// scripts are allowed by configuration.
+type CustomConfig struct {
+	Pool     string   `json:"pool"      binding:"required"`
+	Source   string   `json:"source"    binding:"required"`
+	Args     []string `json:"args"`
+	ReadOnly bool     `json:"read_only"`
+	Limits
+}
+
+// Result holds the value returned by a channel program, decoded from the
+// nvlist output of zfs program -j
+type Result struct {
+	Program  string      `json:"program"`
+	Pool     string      `json:"pool"`
+	ReadOnly bool        `json:"read_only"`
+	Return   interface{} `json:"return"`
+}